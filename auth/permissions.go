@@ -0,0 +1,98 @@
+package auth
+
+import "slices"
+
+// Peran user. Tiap peran mewarisi izin peran di bawahnya
+// (kasir < supervisor < manager < admin).
+const (
+	RoleCashier    = "cashier"
+	RoleSupervisor = "supervisor"
+	RoleManager    = "manager"
+	RoleAdmin      = "admin"
+)
+
+type Permission string
+
+const (
+	ProductsRead       Permission = "products:read"
+	ProductsWrite      Permission = "products:write"
+	CategoriesRead     Permission = "categories:read"
+	CategoriesWrite    Permission = "categories:write"
+	StockWrite         Permission = "stock:write"
+	PricingWrite       Permission = "pricing:write" // daftar harga, modifier, aturan loyalti
+	CustomersRead      Permission = "customers:read"
+	CustomersWrite     Permission = "customers:write"
+	TransactionsCreate Permission = "transactions:create"
+	TransactionsRead   Permission = "transactions:read"
+	TransactionsVoid   Permission = "transactions:void"
+	PricesOverride     Permission = "prices:override"
+	DiscountsLarge     Permission = "discounts:large"
+	ReportsRead        Permission = "reports:read"
+	UsersManage        Permission = "users:manage"
+	APIKeysManage      Permission = "api_keys:manage"
+	AuditRead          Permission = "audit:read"
+)
+
+var roleOrder = []string{RoleCashier, RoleSupervisor, RoleManager, RoleAdmin}
+
+// grants berisi izin yang ditambahkan oleh tiap peran di atas peran sebelumnya.
+var grants = map[string][]Permission{
+	RoleCashier: {
+		ProductsRead, CategoriesRead, CustomersRead, CustomersWrite,
+		TransactionsCreate, TransactionsRead,
+	},
+	RoleSupervisor: {TransactionsVoid, PricesOverride, DiscountsLarge, ReportsRead},
+	RoleManager:    {ProductsWrite, CategoriesWrite, StockWrite, PricingWrite, AuditRead},
+	RoleAdmin:      {UsersManage, APIKeysManage},
+}
+
+var permissions = buildPermissions()
+
+func buildPermissions() map[string]map[Permission]bool {
+	out := map[string]map[Permission]bool{}
+	inherited := map[Permission]bool{}
+	for _, role := range roleOrder {
+		for _, p := range grants[role] {
+			inherited[p] = true
+		}
+		set := make(map[Permission]bool, len(inherited))
+		for p := range inherited {
+			set[p] = true
+		}
+		out[role] = set
+	}
+	return out
+}
+
+// Can melaporkan apakah peran memiliki izin p.
+func Can(role string, p Permission) bool {
+	return permissions[role][p]
+}
+
+// Allowed memeriksa izin p untuk user (lewat peran) atau API key (lewat scope-nya).
+func Allowed(role string, scopes []string, p Permission) bool {
+	if scopes != nil {
+		return slices.Contains(scopes, string(p))
+	}
+	return Can(role, p)
+}
+
+// ValidPermission melaporkan apakah p adalah izin yang dikenal (dipakai untuk scope API key).
+func ValidPermission(p Permission) bool {
+	return permissions[RoleAdmin][p]
+}
+
+func ValidRole(role string) bool {
+	_, ok := permissions[role]
+	return ok
+}
+
+// Overridable adalah izin yang boleh "dipinjam" kasir dengan PIN supervisor
+// untuk satu request (void, ubah harga, diskon besar).
+func Overridable(p Permission) bool {
+	switch p {
+	case TransactionsVoid, PricesOverride, DiscountsLarge:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package auth
+
+import "testing"
+
+// Matriks izin lengkap: kolom berurutan cashier, supervisor, manager, admin. Izin baru
+// harus ditambahkan di sini, jadi perubahan hak akses selalu terlihat saat review.
+func TestCan(t *testing.T) {
+	roles := []string{RoleCashier, RoleSupervisor, RoleManager, RoleAdmin}
+	matrix := map[Permission][4]bool{
+		ProductsRead:       {true, true, true, true},
+		CategoriesRead:     {true, true, true, true},
+		CustomersRead:      {true, true, true, true},
+		CustomersWrite:     {true, true, true, true},
+		TransactionsCreate: {true, true, true, true},
+		TransactionsRead:   {true, true, true, true},
+		TransactionsVoid:   {false, true, true, true},
+		PricesOverride:     {false, true, true, true},
+		DiscountsLarge:     {false, true, true, true},
+		ReportsRead:        {false, true, true, true},
+		ProductsWrite:      {false, false, true, true},
+		CategoriesWrite:    {false, false, true, true},
+		StockWrite:         {false, false, true, true},
+		PricingWrite:       {false, false, true, true},
+		AuditRead:          {false, false, true, true},
+		UsersManage:        {false, false, false, true},
+		APIKeysManage:      {false, false, false, true},
+	}
+
+	for p, want := range matrix {
+		for i, role := range roles {
+			if got := Can(role, p); got != want[i] {
+				t.Errorf("Can(%s, %s) = %v, want %v", role, p, got, want[i])
+			}
+		}
+		if !ValidPermission(p) {
+			t.Errorf("ValidPermission(%s) = false", p)
+		}
+	}
+	if n := len(permissions[RoleAdmin]); n != len(matrix) {
+		t.Errorf("admin has %d permissions, matrix lists %d; add the new permission to the matrix", n, len(matrix))
+	}
+	for _, p := range []Permission{ProductsRead, UsersManage} {
+		if Can("", p) || Can("owner", p) {
+			t.Errorf("unknown role granted %s", p)
+		}
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		scopes []string
+		p      Permission
+		want   bool
+	}{
+		{"role grants", RoleManager, nil, ProductsWrite, true},
+		{"role denies", RoleCashier, nil, ProductsWrite, false},
+		// API key hanya dinilai dari scope-nya, bukan dari peran
+		{"scope grants", "", []string{"products:read", "products:write"}, ProductsWrite, true},
+		{"scope denies", RoleAdmin, []string{"products:read"}, ProductsWrite, false},
+		{"empty scopes deny everything", RoleAdmin, []string{}, ProductsRead, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allowed(tt.role, tt.scopes, tt.p); got != tt.want {
+				t.Errorf("Allowed(%q, %v, %s) = %v, want %v", tt.role, tt.scopes, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverridable(t *testing.T) {
+	for p := range permissions[RoleAdmin] {
+		want := p == TransactionsVoid || p == PricesOverride || p == DiscountsLarge
+		if got := Overridable(p); got != want {
+			t.Errorf("Overridable(%s) = %v, want %v", p, got, want)
+		}
+		// supervisor harus bisa memberi persetujuan untuk setiap izin yang boleh di-override
+		if want && !Can(RoleSupervisor, p) {
+			t.Errorf("%s is overridable but supervisors lack it", p)
+		}
+	}
+}
+
+func TestValidRole(t *testing.T) {
+	for _, role := range []string{RoleCashier, RoleSupervisor, RoleManager, RoleAdmin} {
+		if !ValidRole(role) {
+			t.Errorf("ValidRole(%s) = false", role)
+		}
+	}
+	if ValidRole("owner") || ValidRole("") {
+		t.Error("unknown role accepted")
+	}
+}
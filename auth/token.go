@@ -0,0 +1,88 @@
+// Package auth menerbitkan dan memeriksa access token (JWT), membuat token acak, dan
+// menentukan izin tiap peran.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims adalah isi access token. Subject berisi ID user, SessionID mengikat token ke
+// sesi login agar logout langsung mematikan token yang masih berlaku, dan TenantID
+// menentukan toko tempat token berlaku.
+type Claims struct {
+	TenantID  int    `json:"tid"`
+	SessionID int    `json:"sid"`
+	Username  string `json:"usr"`
+	jwt.RegisteredClaims
+}
+
+func (c *Claims) UserID() int {
+	id, _ := strconv.Atoi(c.Subject)
+	return id
+}
+
+// Issuer menandatangani access token dengan HS256.
+type Issuer struct {
+	secret    []byte
+	accessTTL time.Duration
+}
+
+func NewIssuer(secret string, accessTTL time.Duration) *Issuer {
+	return &Issuer{secret: []byte(secret), accessTTL: accessTTL}
+}
+
+func (i *Issuer) AccessTTL() time.Duration {
+	return i.accessTTL
+}
+
+func (i *Issuer) Issue(tenantID, userID int, username string, sessionID int) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		TenantID:  tenantID,
+		SessionID: sessionID,
+		Username:  username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(userID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(i.accessTTL)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+}
+
+func (i *Issuer) Parse(token string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return i.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+// NewToken membuat token acak (refresh token, kunci terminal); yang disimpan di
+// database hanya hash-nya.
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"kasir-api/database"
+	"kasir-api/repositories"
+	"kasir-api/repositories/sqlite"
+)
+
+// backend adalah database yang dipilih dari skema DB_CONN: Postgres (postgres://...) atau
+// file SQLite (sqlite://path). Keduanya punya repository yang sama, jadi server, migrasi,
+// dan scheduler dirakit dengan alur yang sama.
+type backend struct {
+	// stores merakit repository untuk satu tenant
+	stores   func(tenantID int) repositories.Stores
+	tenants  repositories.TenantStore
+	migrator migrator
+	ping     func(ctx context.Context) error
+	pending  func(ctx context.Context) ([]database.Migration, error)
+	close    func()
+}
+
+func openBackend(conn string) (*backend, error) {
+	if sqlite.IsDSN(conn) {
+		db, err := sqlite.Open(conn)
+		if err != nil {
+			return nil, err
+		}
+		return &backend{
+			stores:   func(tenantID int) repositories.Stores { return sqlite.NewStores(db, tenantID) },
+			tenants:  sqlite.NewTenantRepository(db),
+			migrator: sqliteMigrator(db),
+			ping:     db.PingContext,
+			pending:  func(ctx context.Context) ([]database.Migration, error) { return sqlite.PendingMigrations(ctx, db) },
+			close:    func() { db.Close() },
+		}, nil
+	}
+
+	pool, err := database.InitDB(conn)
+	if err != nil {
+		return nil, err
+	}
+	return &backend{
+		stores:   func(tenantID int) repositories.Stores { return repositories.NewStores(pool, tenantID) },
+		tenants:  repositories.NewTenantRepository(pool),
+		migrator: postgresMigrator(pool),
+		ping:     pool.Ping,
+		pending:  func(ctx context.Context) ([]database.Migration, error) { return database.PendingMigrations(ctx, pool) },
+		close:    pool.Close,
+	}, nil
+}
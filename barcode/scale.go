@@ -0,0 +1,78 @@
+// Package barcode membaca barcode timbangan (EAN-13 berawalan 2) yang menyimpan
+// kode barang beserta berat atau harga langsung di dalam barcode.
+package barcode
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Susunan EAN-13 timbangan yang dipakai:
+//
+//	2P IIIII VVVVV C
+//
+// P      digit kedua prefix: 0-4 → V adalah berat (gram), 5-9 → V adalah harga (rupiah)
+// IIIII  kode barang (PLU) yang dicocokkan ke products.plu
+// VVVVV  nilai berat atau harga
+// C      check digit EAN-13
+const (
+	pluStart   = 2
+	valueStart = 7
+	checkIndex = 12
+)
+
+var ErrNotScaleBarcode = errors.New("not a scale barcode")
+
+type ScaleBarcode struct {
+	PLU string
+	// Grams terisi jika barcode berisi berat, Price terisi jika barcode berisi harga
+	Grams int
+	Price int
+}
+
+// IsWeight melaporkan apakah barcode menyimpan berat (bukan harga).
+func (b ScaleBarcode) IsWeight() bool {
+	return b.Price == 0
+}
+
+// ParseScale mengurai barcode timbangan. Barcode lain (panjang bukan 13 digit atau
+// tidak berawalan 2) menghasilkan ErrNotScaleBarcode agar pemanggil bisa mencoba
+// pencarian barcode biasa.
+func ParseScale(code string) (ScaleBarcode, error) {
+	if len(code) != 13 || code[0] != '2' {
+		return ScaleBarcode{}, ErrNotScaleBarcode
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return ScaleBarcode{}, ErrNotScaleBarcode
+		}
+	}
+	if checkDigit(code[:checkIndex]) != code[checkIndex] {
+		return ScaleBarcode{}, errors.New("invalid barcode check digit")
+	}
+
+	value, _ := strconv.Atoi(code[valueStart:checkIndex])
+	b := ScaleBarcode{PLU: code[pluStart:valueStart]}
+	if code[1] <= '4' {
+		b.Grams = value
+	} else {
+		b.Price = value
+	}
+	if value == 0 {
+		return ScaleBarcode{}, errors.New("scale barcode carries zero weight or price")
+	}
+	return b, nil
+}
+
+// checkDigit menghitung check digit EAN-13 dari 12 digit pertama.
+func checkDigit(digits string) byte {
+	sum := 0
+	for i, c := range digits {
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
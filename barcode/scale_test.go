@@ -0,0 +1,46 @@
+package barcode_test
+
+import (
+	"errors"
+	"kasir-api/barcode"
+	"testing"
+)
+
+func TestParseScale(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		want    barcode.ScaleBarcode
+		wantErr bool
+		// notScale: pemanggil harus mencoba barcode biasa
+		notScale bool
+	}{
+		{name: "weight", code: "2012345012509", want: barcode.ScaleBarcode{PLU: "12345", Grams: 1250}},
+		{name: "weight prefix 24", code: "2412345005004", want: barcode.ScaleBarcode{PLU: "12345", Grams: 500}},
+		{name: "price prefix 25", code: "2512345150008", want: barcode.ScaleBarcode{PLU: "12345", Price: 15000}},
+		{name: "leading zeros", code: "2000001000076", want: barcode.ScaleBarcode{PLU: "00001", Grams: 7}},
+		{name: "bad check digit", code: "2012345012500", wantErr: true},
+		{name: "zero weight", code: "2000002000006", wantErr: true},
+		{name: "zero price", code: "2712345000000", wantErr: true},
+		{name: "other prefix", code: "8991234567891", wantErr: true, notScale: true},
+		{name: "too short", code: "201234501250", wantErr: true, notScale: true},
+		{name: "not digits", code: "20123450125a9", wantErr: true, notScale: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := barcode.ParseScale(tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseScale(%s) err = %v, wantErr %v", tt.code, err, tt.wantErr)
+			}
+			if errors.Is(err, barcode.ErrNotScaleBarcode) != tt.notScale {
+				t.Errorf("ParseScale(%s) err = %v, want ErrNotScaleBarcode = %v", tt.code, err, tt.notScale)
+			}
+			if got != tt.want {
+				t.Errorf("ParseScale(%s) = %+v, want %+v", tt.code, got, tt.want)
+			}
+			if err == nil && got.IsWeight() != (tt.want.Grams > 0) {
+				t.Errorf("IsWeight = %v for %+v", got.IsWeight(), got)
+			}
+		})
+	}
+}
@@ -2,7 +2,7 @@ package database
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -30,6 +30,6 @@ func InitDB(conn string) (*pgxpool.Pool, error) {
 		return nil, err
 	}
 
-	log.Println("Database connected successfully")
+	slog.Info("database connected")
 	return pool, nil
 }
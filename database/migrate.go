@@ -0,0 +1,335 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File migrasi bernama NNNN_nama.up.sql dan NNNN_nama.down.sql, ikut tertanam di binary.
+// Satu sumber untuk semua backend: ditulis dalam SQL Postgres, lalu diterjemahkan oleh
+// Dialect masing-masing backend (lihat repositories/sqlite). Bagian yang tidak bisa
+// diterjemahkan ditulis per dialek di antara baris "-- +dialect <nama>" dan "-- +dialect end".
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationTimeout membatasi satu kali up/down; migrasi bisa jauh lebih lama dari query biasa.
+const migrationTimeout = 5 * time.Minute
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState adalah status satu migrasi; AppliedAt nil berarti belum dijalankan.
+type MigrationState struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// LoadMigrations membaca file migrasi dari dir dan mengurutkannya per versi.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		base, direction, ok := cutDirection(e.Name())
+		if !ok {
+			return nil, fmt.Errorf("migration %s: name must end in .up.sql or .down.sql", e.Name())
+		}
+		versionStr, name, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must start with a positive version number", e.Name())
+		}
+		body, err := fs.ReadFile(fsys, dir+"/"+e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both .up.sql and .down.sql", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func cutDirection(file string) (base, direction string, ok bool) {
+	if base, ok := strings.CutSuffix(file, ".up.sql"); ok {
+		return base, "up", true
+	}
+	if base, ok := strings.CutSuffix(file, ".down.sql"); ok {
+		return base, "down", true
+	}
+	return "", "", false
+}
+
+// Dialect adalah bagian runner migrasi yang berbeda antar database. Urutan migrasi,
+// pencatatan di schema_migrations, dan satu transaksi per migrasi ada di Runner.
+type Dialect interface {
+	// Name mencocokkan blok "-- +dialect <nama>" di file migrasi.
+	Name() string
+	// Lock menjalankan fn pada koneksi yang memegang kunci migrasi, supaya hanya satu
+	// proses yang bermigrasi pada satu waktu.
+	Lock(ctx context.Context, fn func(conn MigrationConn) error) error
+	// Conn menjalankan fn tanpa kunci, untuk sekadar membaca status.
+	Conn(ctx context.Context, fn func(conn MigrationConn) error) error
+	// Placeholder mengembalikan penanda parameter ke-n (mulai 1), mis. "$1" atau "?".
+	Placeholder(n int) string
+	// Translate mengubah SQL Postgres ke dialek ini.
+	Translate(query string) string
+}
+
+// MigrationConn adalah koneksi yang dipakai Runner.
+type MigrationConn interface {
+	Exec(ctx context.Context, query string, args ...any) error
+	// Query memanggil row untuk setiap baris hasil query.
+	Query(ctx context.Context, query string, row func(scan func(dest ...any) error) error) error
+	// InTx menjalankan fn dalam satu transaksi; error dari fn membatalkan semuanya.
+	InTx(ctx context.Context, fn func(exec func(query string, args ...any) error) error) error
+}
+
+// Runner menjalankan migrasi tertanam pada satu backend.
+type Runner struct {
+	dialect Dialect
+}
+
+func NewRunner(d Dialect) *Runner {
+	return &Runner{dialect: d}
+}
+
+// Migrations mengembalikan semua migrasi yang sudah diterjemahkan ke dialek runner.
+func (r *Runner) Migrations() ([]Migration, error) {
+	migrations, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	for i := range migrations {
+		m := &migrations[i]
+		m.Up = r.dialect.Translate(selectDialect(m.Up, r.dialect.Name()))
+		m.Down = r.dialect.Translate(selectDialect(m.Down, r.dialect.Name()))
+	}
+	return migrations, nil
+}
+
+// selectDialect membuang blok "-- +dialect x" milik dialek lain.
+func selectDialect(body, dialect string) string {
+	var (
+		out   strings.Builder
+		block string
+	)
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		line := sc.Text()
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), "-- +dialect "); ok {
+			if block = strings.TrimSpace(name); block == "end" {
+				block = ""
+			}
+			continue
+		}
+		if block == "" || block == dialect {
+			out.WriteString(line)
+			out.WriteByte('\n')
+		}
+	}
+	return out.String()
+}
+
+// Up menjalankan semua migrasi yang belum diterapkan, masing-masing dalam satu
+// transaksi, dan mengembalikan migrasi yang baru diterapkan.
+func (r *Runner) Up() ([]Migration, error) {
+	migrations, err := r.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]Migration, 0)
+	err = r.locked(migrations, func(ctx context.Context, conn MigrationConn, applied map[int]time.Time) error {
+		record := fmt.Sprintf(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)`,
+			r.dialect.Placeholder(1), r.dialect.Placeholder(2), r.dialect.Placeholder(3))
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := runMigration(ctx, conn, m, m.Up, record, m.Version, m.Name, time.Now().UTC()); err != nil {
+				return err
+			}
+			slog.Info("migrate: applied", "version", m.Version, "name", m.Name)
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down membatalkan steps migrasi terakhir yang sudah diterapkan, terbaru lebih dulu.
+func (r *Runner) Down(steps int) ([]Migration, error) {
+	migrations, err := r.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]Migration, 0)
+	err = r.locked(migrations, func(ctx context.Context, conn MigrationConn, applied map[int]time.Time) error {
+		record := `DELETE FROM schema_migrations WHERE version = ` + r.dialect.Placeholder(1)
+		for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if err := runMigration(ctx, conn, m, m.Down, record, m.Version); err != nil {
+				return err
+			}
+			slog.Info("migrate: reverted", "version", m.Version, "name", m.Name)
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status mengembalikan semua migrasi yang dikenal binary ini beserta waktu diterapkannya.
+func (r *Runner) Status() ([]MigrationState, error) {
+	migrations, err := r.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	err = r.locked(migrations, func(ctx context.Context, conn MigrationConn, applied map[int]time.Time) error {
+		for _, m := range migrations {
+			state := MigrationState{Version: m.Version, Name: m.Name}
+			if at, ok := applied[m.Version]; ok {
+				state.AppliedAt = &at
+			}
+			states = append(states, state)
+		}
+		return nil
+	})
+	return states, err
+}
+
+// Pending mengembalikan migrasi yang belum diterapkan, untuk pemeriksaan kesiapan
+// (/readyz). Hanya membaca schema_migrations tanpa kunci, jadi tidak ikut menunggu
+// instance lain yang sedang bermigrasi.
+func (r *Runner) Pending(ctx context.Context) ([]Migration, error) {
+	migrations, err := r.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	err = r.dialect.Conn(ctx, func(conn MigrationConn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; !ok {
+				pending = append(pending, m)
+			}
+		}
+		return nil
+	})
+	return pending, err
+}
+
+// locked menjalankan fn sambil memegang kunci migrasi, setelah memastikan tabel
+// schema_migrations ada dan membaca migrasi yang sudah diterapkan. Versi yang tercatat
+// dengan nama lain (mis. database dari skema lama) ditolak daripada dilewati diam-diam.
+func (r *Runner) locked(migrations []Migration, fn func(ctx context.Context, conn MigrationConn, applied map[int]time.Time) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+	defer cancel()
+
+	return r.dialect.Lock(ctx, func(conn MigrationConn) error {
+		err := conn.Exec(ctx, r.dialect.Translate(`
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version    INTEGER PRIMARY KEY,
+				name       TEXT        NOT NULL,
+				applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+			)`))
+		if err != nil {
+			return err
+		}
+		names := map[int]string{}
+		err = conn.Query(ctx, `SELECT version, name FROM schema_migrations`, func(scan func(dest ...any) error) error {
+			var version int
+			var name string
+			if err := scan(&version, &name); err != nil {
+				return err
+			}
+			names[version] = name
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if name, ok := names[m.Version]; ok && name != m.Name {
+				return fmt.Errorf("migration %04d is recorded as %q but this binary has %q; the database was created by an incompatible schema",
+					m.Version, name, m.Name)
+			}
+		}
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, conn, applied)
+	})
+}
+
+func appliedMigrations(ctx context.Context, conn MigrationConn) (map[int]time.Time, error) {
+	applied := map[int]time.Time{}
+	err := conn.Query(ctx, `SELECT version, applied_at FROM schema_migrations`, func(scan func(dest ...any) error) error {
+		var version int
+		var at time.Time
+		if err := scan(&version, &at); err != nil {
+			return err
+		}
+		applied[version] = at
+		return nil
+	})
+	return applied, err
+}
+
+// runMigration menjalankan isi file migrasi dan mencatatnya di schema_migrations dalam
+// satu transaksi, jadi migrasi yang gagal tidak meninggalkan skema setengah jadi.
+func runMigration(ctx context.Context, conn MigrationConn, m Migration, body, record string, args ...any) error {
+	return conn.InTx(ctx, func(exec func(query string, args ...any) error) error {
+		if err := exec(body); err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		return exec(record, args...)
+	})
+}
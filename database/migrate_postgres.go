@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockID adalah kunci advisory lock Postgres; hanya satu instance yang boleh
+// menjalankan migrasi pada satu waktu, instance lain menunggu sampai selesai.
+const migrationLockID = 7_402_013_001
+
+// postgresDialect menjalankan migrasi apa adanya (file migrasi ditulis untuk Postgres).
+type postgresDialect struct {
+	pool *pgxpool.Pool
+}
+
+func (postgresDialect) Name() string                  { return "postgres" }
+func (postgresDialect) Placeholder(n int) string      { return "$" + strconv.Itoa(n) }
+func (postgresDialect) Translate(query string) string { return query }
+
+// Lock memegang advisory lock pada satu koneksi. Lock terlepas sendiri jika koneksi putus.
+func (d postgresDialect) Lock(ctx context.Context, fn func(conn MigrationConn) error) error {
+	return d.Conn(ctx, func(conn MigrationConn) error {
+		if err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+			return err
+		}
+		defer func() {
+			// context terpisah supaya lock tetap dilepas walaupun ctx sudah habis
+			unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, migrationLockID)
+		}()
+		return fn(conn)
+	})
+}
+
+func (d postgresDialect) Conn(ctx context.Context, fn func(conn MigrationConn) error) error {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	return fn(postgresConn{conn})
+}
+
+type postgresConn struct {
+	conn *pgxpool.Conn
+}
+
+func (c postgresConn) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := c.conn.Exec(ctx, query, args...)
+	return err
+}
+
+func (c postgresConn) Query(ctx context.Context, query string, row func(scan func(dest ...any) error) error) error {
+	rows, err := c.conn.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := row(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (c postgresConn) InTx(ctx context.Context, fn func(exec func(query string, args ...any) error) error) error {
+	tx, err := c.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = fn(func(query string, args ...any) error {
+		_, err := tx.Exec(ctx, query, args...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func postgresRunner(pool *pgxpool.Pool) *Runner {
+	return NewRunner(postgresDialect{pool: pool})
+}
+
+// MigrateUp menjalankan semua migrasi Postgres yang belum diterapkan.
+func MigrateUp(pool *pgxpool.Pool) ([]Migration, error) {
+	return postgresRunner(pool).Up()
+}
+
+// MigrateDown membatalkan steps migrasi Postgres terakhir, terbaru lebih dulu.
+func MigrateDown(pool *pgxpool.Pool, steps int) ([]Migration, error) {
+	return postgresRunner(pool).Down(steps)
+}
+
+// MigrationStatus mengembalikan semua migrasi yang dikenal binary ini beserta waktu diterapkannya.
+func MigrationStatus(pool *pgxpool.Pool) ([]MigrationState, error) {
+	return postgresRunner(pool).Status()
+}
+
+// PendingMigrations mengembalikan migrasi Postgres yang belum diterapkan (untuk /readyz).
+func PendingMigrations(ctx context.Context, pool *pgxpool.Pool) ([]Migration, error) {
+	return postgresRunner(pool).Pending(ctx)
+}
@@ -0,0 +1,39 @@
+package database
+
+import "testing"
+
+func TestSelectDialect(t *testing.T) {
+	body := `CREATE TABLE a (id INTEGER);
+-- +dialect postgres
+SELECT setval('a_id_seq', 1);
+-- +dialect end
+-- +dialect sqlite
+CREATE TRIGGER a_ro BEFORE DELETE ON a BEGIN SELECT RAISE(ABORT, 'no'); END;
+-- +dialect end
+CREATE INDEX a_idx ON a (id);
+`
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{"postgres", "CREATE TABLE a (id INTEGER);\nSELECT setval('a_id_seq', 1);\nCREATE INDEX a_idx ON a (id);\n"},
+		{"sqlite", "CREATE TABLE a (id INTEGER);\nCREATE TRIGGER a_ro BEFORE DELETE ON a BEGIN SELECT RAISE(ABORT, 'no'); END;\nCREATE INDEX a_idx ON a (id);\n"},
+	}
+	for _, tt := range tests {
+		if got := selectDialect(body, tt.dialect); got != tt.want {
+			t.Errorf("selectDialect(%s) =\n%s\nwant\n%s", tt.dialect, got, tt.want)
+		}
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d; versions must be consecutive", i, m.Version)
+		}
+	}
+}
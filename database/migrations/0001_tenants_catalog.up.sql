@@ -0,0 +1,122 @@
+-- Tenant (toko) dan katalog: kategori, produk, varian, satuan, modifier, daftar harga.
+-- Setiap tabel menyimpan tenant_id; relasi antar tabel memakai foreign key komposit
+-- (tenant_id, x_id) supaya baris anak tidak bisa menunjuk data milik toko lain.
+-- ON DELETE SET NULL (kolom) membutuhkan PostgreSQL 15 atau lebih baru.
+
+CREATE TABLE tenants (
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    slug       TEXT        NOT NULL UNIQUE,
+    name       TEXT        NOT NULL,
+    active     BOOLEAN     NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- tenant bawaan (models.DefaultTenantID) untuk mode satu toko
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', 'Default');
+-- +dialect postgres
+SELECT setval(pg_get_serial_sequence('tenants', 'id'), 1);
+-- +dialect end
+
+CREATE TABLE categories (
+    id          INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id   INTEGER NOT NULL REFERENCES tenants (id),
+    name        TEXT    NOT NULL,
+    description TEXT    NOT NULL DEFAULT '',
+    UNIQUE (tenant_id, id)
+);
+
+CREATE TABLE products (
+    id          INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id   INTEGER        NOT NULL REFERENCES tenants (id),
+    name        TEXT           NOT NULL,
+    price       BIGINT         NOT NULL DEFAULT 0 CHECK (price >= 0),
+    stock       NUMERIC(14, 3) NOT NULL DEFAULT 0,
+    unit        TEXT           NOT NULL DEFAULT 'pcs',
+    decimal_qty BOOLEAN        NOT NULL DEFAULT false,
+    plu         TEXT,
+    category_id INTEGER,
+    option_axes TEXT[]         NOT NULL DEFAULT '{}',
+    UNIQUE (tenant_id, id),
+    UNIQUE (tenant_id, plu),
+    FOREIGN KEY (tenant_id, category_id) REFERENCES categories (tenant_id, id) ON DELETE SET NULL (category_id)
+);
+CREATE INDEX products_tenant_name_idx ON products (tenant_id, name);
+
+CREATE TABLE product_variants (
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id  INTEGER        NOT NULL REFERENCES tenants (id),
+    product_id INTEGER        NOT NULL,
+    sku        TEXT           NOT NULL,
+    name       TEXT           NOT NULL,
+    options    JSONB          NOT NULL DEFAULT '{}',
+    price      BIGINT         NOT NULL DEFAULT 0 CHECK (price >= 0),
+    stock      NUMERIC(14, 3) NOT NULL DEFAULT 0,
+    UNIQUE (tenant_id, id),
+    UNIQUE (tenant_id, sku),
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products (tenant_id, id) ON DELETE CASCADE
+);
+CREATE INDEX product_variants_product_idx ON product_variants (product_id);
+
+CREATE TABLE product_units (
+    id          INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id   INTEGER        NOT NULL REFERENCES tenants (id),
+    product_id  INTEGER        NOT NULL,
+    name        TEXT           NOT NULL,
+    factor      NUMERIC(14, 3) NOT NULL CHECK (factor > 0),
+    price       BIGINT,
+    barcode     TEXT,
+    sellable    BOOLEAN        NOT NULL DEFAULT true,
+    purchasable BOOLEAN        NOT NULL DEFAULT true,
+    UNIQUE (tenant_id, id),
+    UNIQUE (product_id, name),
+    UNIQUE (tenant_id, barcode),
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products (tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE modifier_groups (
+    id          INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id   INTEGER NOT NULL REFERENCES tenants (id),
+    name        TEXT    NOT NULL,
+    product_id  INTEGER,
+    category_id INTEGER,
+    required    BOOLEAN NOT NULL DEFAULT false,
+    min_select  INTEGER NOT NULL DEFAULT 0,
+    max_select  INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, category_id) REFERENCES categories (tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE modifiers (
+    id          INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id   INTEGER NOT NULL REFERENCES tenants (id),
+    group_id    INTEGER NOT NULL,
+    name        TEXT    NOT NULL,
+    price_delta BIGINT  NOT NULL DEFAULT 0,
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, group_id) REFERENCES modifier_groups (tenant_id, id) ON DELETE CASCADE
+);
+CREATE INDEX modifiers_group_idx ON modifiers (group_id);
+
+CREATE TABLE price_lists (
+    id        INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants (id),
+    code      TEXT    NOT NULL,
+    name      TEXT    NOT NULL,
+    UNIQUE (tenant_id, id),
+    UNIQUE (tenant_id, code)
+);
+
+CREATE TABLE price_list_items (
+    id            INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id     INTEGER        NOT NULL REFERENCES tenants (id),
+    price_list_id INTEGER        NOT NULL,
+    product_id    INTEGER        NOT NULL,
+    variant_id    INTEGER,
+    min_quantity  NUMERIC(14, 3) NOT NULL DEFAULT 0,
+    price         BIGINT         NOT NULL CHECK (price >= 0),
+    FOREIGN KEY (tenant_id, price_list_id) REFERENCES price_lists (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, variant_id) REFERENCES product_variants (tenant_id, id) ON DELETE CASCADE
+);
+CREATE INDEX price_list_items_lookup_idx ON price_list_items (price_list_id, product_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS terminal_users;
+DROP TABLE IF EXISTS terminals;
+DROP TABLE IF EXISTS users;
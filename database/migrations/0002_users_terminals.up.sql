@@ -0,0 +1,68 @@
+-- User, terminal kasir, sesi login, dan API key. Hash kunci terminal, API key, dan
+-- refresh token unik di semua tenant karena tenant ditentukan dari kunci tersebut.
+
+CREATE TABLE users (
+    id                  INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id           INTEGER     NOT NULL REFERENCES tenants (id),
+    username            TEXT        NOT NULL,
+    name                TEXT        NOT NULL,
+    role                TEXT        NOT NULL,
+    password_hash       TEXT        NOT NULL,
+    pin_hash            TEXT,
+    failed_pin_attempts INTEGER     NOT NULL DEFAULT 0,
+    pin_locked_until    TIMESTAMPTZ,
+    active              BOOLEAN     NOT NULL DEFAULT true,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (tenant_id, id),
+    UNIQUE (tenant_id, username)
+);
+
+CREATE TABLE terminals (
+    id           INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id    INTEGER     NOT NULL REFERENCES tenants (id),
+    name         TEXT        NOT NULL,
+    key_hash     TEXT        NOT NULL UNIQUE,
+    active       BOOLEAN     NOT NULL DEFAULT true,
+    last_seen_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (tenant_id, id)
+);
+
+CREATE TABLE terminal_users (
+    tenant_id   INTEGER NOT NULL REFERENCES tenants (id),
+    terminal_id INTEGER NOT NULL,
+    user_id     INTEGER NOT NULL,
+    PRIMARY KEY (terminal_id, user_id),
+    FOREIGN KEY (tenant_id, terminal_id) REFERENCES terminals (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users (tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE TABLE sessions (
+    id           INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id    INTEGER     NOT NULL REFERENCES tenants (id),
+    user_id      INTEGER     NOT NULL,
+    terminal_id  INTEGER,
+    refresh_hash TEXT        NOT NULL UNIQUE,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, terminal_id) REFERENCES terminals (tenant_id, id) ON DELETE CASCADE
+);
+CREATE INDEX sessions_user_idx ON sessions (user_id) WHERE revoked_at IS NULL;
+CREATE INDEX sessions_terminal_idx ON sessions (terminal_id) WHERE revoked_at IS NULL;
+
+CREATE TABLE api_keys (
+    id           INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id    INTEGER     NOT NULL REFERENCES tenants (id),
+    name         TEXT        NOT NULL,
+    prefix       TEXT        NOT NULL,
+    scopes       TEXT[]      NOT NULL DEFAULT '{}',
+    rate_limit   INTEGER     NOT NULL CHECK (rate_limit > 0),
+    key_hash     TEXT        NOT NULL UNIQUE,
+    created_by   INTEGER,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (tenant_id, created_by) REFERENCES users (tenant_id, id) ON DELETE SET NULL (created_by)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS loyalty_category_multipliers;
+DROP TABLE IF EXISTS loyalty_rules;
+DROP TABLE IF EXISTS customers;
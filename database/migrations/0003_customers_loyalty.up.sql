@@ -0,0 +1,31 @@
+-- Pelanggan dan aturan loyalti (satu baris aturan per tenant).
+
+CREATE TABLE customers (
+    id            INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id     INTEGER     NOT NULL REFERENCES tenants (id),
+    name          TEXT        NOT NULL,
+    phone         TEXT,
+    email         TEXT,
+    notes         TEXT        NOT NULL DEFAULT '',
+    price_list_id INTEGER,
+    credit_limit  BIGINT      NOT NULL DEFAULT 0 CHECK (credit_limit >= 0),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (tenant_id, id),
+    UNIQUE (tenant_id, phone),
+    FOREIGN KEY (tenant_id, price_list_id) REFERENCES price_lists (tenant_id, id) ON DELETE SET NULL (price_list_id)
+);
+
+CREATE TABLE loyalty_rules (
+    tenant_id       INTEGER PRIMARY KEY REFERENCES tenants (id),
+    spend_per_point BIGINT  NOT NULL DEFAULT 0,
+    point_value     BIGINT  NOT NULL DEFAULT 0,
+    expiry_days     INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE loyalty_category_multipliers (
+    tenant_id   INTEGER       NOT NULL REFERENCES tenants (id),
+    category_id INTEGER       NOT NULL,
+    multiplier  NUMERIC(6, 2) NOT NULL CHECK (multiplier >= 0),
+    PRIMARY KEY (tenant_id, category_id),
+    FOREIGN KEY (tenant_id, category_id) REFERENCES categories (tenant_id, id) ON DELETE CASCADE
+);
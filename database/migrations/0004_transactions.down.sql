@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS receivable_allocations;
+DROP TABLE IF EXISTS receivable_payments;
+DROP TABLE IF EXISTS receivables;
+DROP TABLE IF EXISTS loyalty_ledger;
+DROP TABLE IF EXISTS transaction_detail_modifiers;
+DROP TABLE IF EXISTS transaction_details;
+DROP TABLE IF EXISTS transactions;
@@ -0,0 +1,119 @@
+-- Transaksi beserta detail, salinan modifier, buku poin loyalti, dan piutang (kasbon).
+-- Produk/varian yang sudah pernah terjual tidak bisa dihapus (tidak ada ON DELETE).
+
+CREATE TABLE transactions (
+    id                  INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id           INTEGER       NOT NULL REFERENCES tenants (id),
+    status              TEXT          NOT NULL,
+    subtotal            BIGINT        NOT NULL,
+    discount_percent    NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    discount            BIGINT        NOT NULL DEFAULT 0,
+    loyalty_discount    BIGINT        NOT NULL DEFAULT 0,
+    total_amount        BIGINT        NOT NULL,
+    rounding_adjustment BIGINT        NOT NULL DEFAULT 0,
+    currency            TEXT          NOT NULL,
+    payment_method      TEXT          NOT NULL,
+    customer_id         INTEGER,
+    points_earned       INTEGER       NOT NULL DEFAULT 0,
+    points_redeemed     INTEGER       NOT NULL DEFAULT 0,
+    cashier_id          INTEGER,
+    terminal_id         INTEGER,
+    approved_by         INTEGER,
+    created_at          TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    refunded_at         TIMESTAMPTZ,
+    refunded_by         INTEGER,
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, customer_id) REFERENCES customers (tenant_id, id) ON DELETE SET NULL (customer_id),
+    FOREIGN KEY (tenant_id, cashier_id) REFERENCES users (tenant_id, id) ON DELETE SET NULL (cashier_id),
+    FOREIGN KEY (tenant_id, terminal_id) REFERENCES terminals (tenant_id, id) ON DELETE SET NULL (terminal_id),
+    FOREIGN KEY (tenant_id, approved_by) REFERENCES users (tenant_id, id) ON DELETE SET NULL (approved_by),
+    FOREIGN KEY (tenant_id, refunded_by) REFERENCES users (tenant_id, id) ON DELETE SET NULL (refunded_by)
+);
+CREATE INDEX transactions_tenant_created_idx ON transactions (tenant_id, created_at);
+CREATE INDEX transactions_customer_idx ON transactions (customer_id, created_at);
+
+CREATE TABLE transaction_details (
+    id             INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id      INTEGER        NOT NULL REFERENCES tenants (id),
+    transaction_id INTEGER        NOT NULL,
+    product_id     INTEGER        NOT NULL,
+    variant_id     INTEGER,
+    quantity       NUMERIC(14, 3) NOT NULL CHECK (quantity > 0),
+    unit           TEXT           NOT NULL,
+    base_quantity  NUMERIC(14, 3) NOT NULL,
+    price_list_id  INTEGER,
+    unit_price     BIGINT         NOT NULL,
+    price_override BOOLEAN        NOT NULL DEFAULT false,
+    subtotal       BIGINT         NOT NULL,
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, transaction_id) REFERENCES transactions (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products (tenant_id, id),
+    FOREIGN KEY (tenant_id, variant_id) REFERENCES product_variants (tenant_id, id),
+    FOREIGN KEY (tenant_id, price_list_id) REFERENCES price_lists (tenant_id, id) ON DELETE SET NULL (price_list_id)
+);
+CREATE INDEX transaction_details_transaction_idx ON transaction_details (transaction_id);
+
+-- modifier_id sengaja tanpa foreign key: baris ini salinan saat transaksi, modifier-nya
+-- boleh diubah atau dihapus kemudian
+CREATE TABLE transaction_detail_modifiers (
+    id                    INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id             INTEGER NOT NULL REFERENCES tenants (id),
+    transaction_detail_id INTEGER NOT NULL,
+    modifier_id           INTEGER NOT NULL,
+    name                  TEXT    NOT NULL,
+    price_delta           BIGINT  NOT NULL,
+    FOREIGN KEY (tenant_id, transaction_detail_id) REFERENCES transaction_details (tenant_id, id) ON DELETE CASCADE
+);
+CREATE INDEX transaction_detail_modifiers_detail_idx ON transaction_detail_modifiers (transaction_detail_id);
+
+CREATE TABLE loyalty_ledger (
+    id             INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id      INTEGER     NOT NULL REFERENCES tenants (id),
+    customer_id    INTEGER     NOT NULL,
+    transaction_id INTEGER,
+    kind           TEXT        NOT NULL,
+    points         INTEGER     NOT NULL,
+    expires_at     TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (tenant_id, customer_id) REFERENCES customers (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, transaction_id) REFERENCES transactions (tenant_id, id)
+);
+CREATE INDEX loyalty_ledger_customer_idx ON loyalty_ledger (customer_id);
+
+-- pelanggan yang masih punya piutang tidak bisa dihapus
+CREATE TABLE receivables (
+    id             INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id      INTEGER     NOT NULL REFERENCES tenants (id),
+    customer_id    INTEGER     NOT NULL,
+    transaction_id INTEGER     NOT NULL,
+    amount         BIGINT      NOT NULL CHECK (amount > 0),
+    paid_amount    BIGINT      NOT NULL DEFAULT 0 CHECK (paid_amount >= 0 AND paid_amount <= amount),
+    status         TEXT        NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    settled_at     TIMESTAMPTZ,
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, customer_id) REFERENCES customers (tenant_id, id),
+    FOREIGN KEY (tenant_id, transaction_id) REFERENCES transactions (tenant_id, id)
+);
+CREATE INDEX receivables_customer_idx ON receivables (customer_id, status);
+
+CREATE TABLE receivable_payments (
+    id          INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id   INTEGER     NOT NULL REFERENCES tenants (id),
+    customer_id INTEGER     NOT NULL,
+    amount      BIGINT      NOT NULL CHECK (amount > 0),
+    note        TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, customer_id) REFERENCES customers (tenant_id, id)
+);
+
+CREATE TABLE receivable_allocations (
+    id            INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id     INTEGER NOT NULL REFERENCES tenants (id),
+    payment_id    INTEGER NOT NULL,
+    receivable_id INTEGER NOT NULL,
+    amount        BIGINT  NOT NULL CHECK (amount > 0),
+    FOREIGN KEY (tenant_id, payment_id) REFERENCES receivable_payments (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, receivable_id) REFERENCES receivables (tenant_id, id)
+);
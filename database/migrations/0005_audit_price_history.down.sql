@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS price_history;
+DROP TABLE IF EXISTS scheduled_prices;
+DROP TABLE IF EXISTS audit_logs;
+-- +dialect postgres
+DROP FUNCTION IF EXISTS audit_logs_append_only();
+-- +dialect end
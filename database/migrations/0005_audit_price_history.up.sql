@@ -0,0 +1,78 @@
+-- Audit log (append-only) dan riwayat harga produk.
+
+-- Aktor sengaja tanpa foreign key: log harus tetap utuh walaupun user atau API key dihapus.
+CREATE TABLE audit_logs (
+    id               BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id        INTEGER     NOT NULL REFERENCES tenants (id),
+    actor_user_id    INTEGER,
+    actor_api_key_id INTEGER,
+    actor_name       TEXT        NOT NULL DEFAULT '',
+    approved_by      INTEGER,
+    action           TEXT        NOT NULL,
+    entity           TEXT        NOT NULL,
+    entity_id        INTEGER,
+    before           JSONB,
+    after            JSONB,
+    diff             JSONB,
+    source_ip        TEXT        NOT NULL DEFAULT '',
+    request_id       TEXT        NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX audit_logs_tenant_created_idx ON audit_logs (tenant_id, created_at);
+CREATE INDEX audit_logs_entity_idx ON audit_logs (tenant_id, entity, entity_id);
+
+-- +dialect postgres
+CREATE FUNCTION audit_logs_append_only() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$;
+
+CREATE TRIGGER audit_logs_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+CREATE TRIGGER audit_logs_no_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+-- +dialect end
+-- +dialect sqlite
+CREATE TRIGGER audit_logs_no_update
+    BEFORE UPDATE ON audit_logs
+    BEGIN SELECT RAISE(ABORT, 'audit_logs is append-only'); END;
+CREATE TRIGGER audit_logs_no_delete
+    BEFORE DELETE ON audit_logs
+    BEGIN SELECT RAISE(ABORT, 'audit_logs is append-only'); END;
+-- +dialect end
+
+CREATE TABLE scheduled_prices (
+    id           INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id    INTEGER     NOT NULL REFERENCES tenants (id),
+    product_id   INTEGER     NOT NULL,
+    price        BIGINT      NOT NULL CHECK (price >= 0),
+    effective_at TIMESTAMPTZ NOT NULL,
+    status       TEXT        NOT NULL,
+    created_by   INTEGER,
+    applied_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (tenant_id, id),
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, created_by) REFERENCES users (tenant_id, id) ON DELETE SET NULL (created_by)
+);
+CREATE INDEX scheduled_prices_due_idx ON scheduled_prices (effective_at) WHERE status = 'pending';
+CREATE INDEX scheduled_prices_product_idx ON scheduled_prices (product_id);
+
+CREATE TABLE price_history (
+    id                 INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    tenant_id          INTEGER     NOT NULL REFERENCES tenants (id),
+    product_id         INTEGER     NOT NULL,
+    old_price          BIGINT,
+    new_price          BIGINT      NOT NULL,
+    source             TEXT        NOT NULL,
+    scheduled_price_id INTEGER,
+    changed_by         INTEGER,
+    changed_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (tenant_id, product_id) REFERENCES products (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, scheduled_price_id) REFERENCES scheduled_prices (tenant_id, id) ON DELETE SET NULL (scheduled_price_id),
+    FOREIGN KEY (tenant_id, changed_by) REFERENCES users (tenant_id, id) ON DELETE SET NULL (changed_by)
+);
+CREATE INDEX price_history_product_idx ON price_history (product_id, changed_at);
@@ -0,0 +1,10 @@
+-- +dialect postgres
+ALTER TABLE product_variants DROP CONSTRAINT IF EXISTS product_variants_stock_non_negative;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_stock_non_negative;
+-- +dialect end
+-- +dialect sqlite
+DROP TRIGGER IF EXISTS product_variants_stock_non_negative_update;
+DROP TRIGGER IF EXISTS product_variants_stock_non_negative_insert;
+DROP TRIGGER IF EXISTS products_stock_non_negative_update;
+DROP TRIGGER IF EXISTS products_stock_non_negative_insert;
+-- +dialect end
@@ -0,0 +1,23 @@
+-- Stok tidak boleh negatif. Checkout sudah mengunci dan memeriksa stok; constraint ini
+-- jaring terakhir bila ada jalur tulis yang lolos. Database yang sempat oversell harus
+-- dikoreksi dulu (stok negatif) sebelum migrasi ini bisa dijalankan.
+
+-- +dialect postgres
+ALTER TABLE products ADD CONSTRAINT products_stock_non_negative CHECK (stock >= 0);
+ALTER TABLE product_variants ADD CONSTRAINT product_variants_stock_non_negative CHECK (stock >= 0);
+-- +dialect end
+-- +dialect sqlite
+-- SQLite tidak bisa menambah CHECK ke tabel yang sudah ada, jadi dijaga dengan trigger.
+CREATE TRIGGER products_stock_non_negative_insert
+    BEFORE INSERT ON products WHEN NEW.stock < 0
+    BEGIN SELECT RAISE(ABORT, 'CHECK constraint failed: products_stock_non_negative'); END;
+CREATE TRIGGER products_stock_non_negative_update
+    BEFORE UPDATE OF stock ON products WHEN NEW.stock < 0
+    BEGIN SELECT RAISE(ABORT, 'CHECK constraint failed: products_stock_non_negative'); END;
+CREATE TRIGGER product_variants_stock_non_negative_insert
+    BEFORE INSERT ON product_variants WHEN NEW.stock < 0
+    BEGIN SELECT RAISE(ABORT, 'CHECK constraint failed: product_variants_stock_non_negative'); END;
+CREATE TRIGGER product_variants_stock_non_negative_update
+    BEFORE UPDATE OF stock ON product_variants WHEN NEW.stock < 0
+    BEGIN SELECT RAISE(ABORT, 'CHECK constraint failed: product_variants_stock_non_negative'); END;
+-- +dialect end
@@ -0,0 +1,2 @@
+-- Pajak atas total setelah diskon (money.Config.TaxRate); transaksi lama tanpa pajak.
+ALTER TABLE transactions ADD COLUMN tax BIGINT NOT NULL DEFAULT 0;
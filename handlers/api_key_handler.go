@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+)
+
+type APIKeyHandler struct {
+	service *services.APIKeyService
+}
+
+func NewAPIKeyHandler(service *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// GetAllAPIKeys - GET /api/api-keys
+func (h *APIKeyHandler) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.GetAll(r.Context())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(keys)
+}
+
+// CreateAPIKey - POST /api/api-keys
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var k models.APIKey
+	if !decodeJSON(w, r, r.Body, &k) {
+		return
+	}
+	k.CreatedBy = nil
+	if principal, ok := middleware.PrincipalFrom(r.Context()); ok && principal.UserID != 0 {
+		k.CreatedBy = &principal.UserID
+	}
+	if err := h.service.Create(r.Context(), &k); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(k)
+}
+
+// GetAPIKeyByID - GET /api/api-keys/{id}
+func (h *APIKeyHandler) GetAPIKeyByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "api key")
+	if !ok {
+		return
+	}
+
+	key, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(key)
+}
+
+// UpdateAPIKey - PUT /api/api-keys/{id}
+func (h *APIKeyHandler) UpdateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "api key")
+	if !ok {
+		return
+	}
+
+	type UpdateReq struct {
+		Name      *string   `json:"name"`
+		Scopes    *[]string `json:"scopes"`
+		RateLimit *int      `json:"rate_limit"`
+	}
+	var req UpdateReq
+	if !decodeJSON(w, r, r.Body, &req) {
+		return
+	}
+
+	old, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if req.Name != nil {
+		old.Name = *req.Name
+	}
+	if req.Scopes != nil {
+		old.Scopes = *req.Scopes
+	}
+	if req.RateLimit != nil {
+		old.RateLimit = *req.RateLimit
+	}
+
+	if err := h.service.Update(r.Context(), old); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(old)
+}
+
+// RevokeAPIKey - DELETE /api/api-keys/{id}
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "api key")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), id); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "API key revoked successfully",
+	})
+}
+
+// RotateAPIKey - POST /api/api-keys/{id}/rotate
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "api key")
+	if !ok {
+		return
+	}
+
+	key, err := h.service.Rotate(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(key)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+)
+
+// terminalKeyHeader membawa kunci terminal untuk login PIN.
+const terminalKeyHeader = "X-Terminal-Key"
+
+type AuthHandler struct {
+	service     *services.AuthService
+	userService *services.UserService
+}
+
+func NewAuthHandler(service *services.AuthService, userService *services.UserService) *AuthHandler {
+	return &AuthHandler{service: service, userService: userService}
+}
+
+// HandleLogin handles POST /api/auth/login
+func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginRequest
+	if !decodeJSON(w, r, r.Body, &req) {
+		return
+	}
+
+	tokens, err := h.service.Login(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			problem.Write(w, r, http.StatusUnauthorized, err.Error())
+			return
+		}
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tokens)
+}
+
+// HandlePINLogin handles POST /api/auth/pin-login (header X-Terminal-Key wajib)
+func (h *AuthHandler) HandlePINLogin(w http.ResponseWriter, r *http.Request) {
+	var req models.PINLoginRequest
+	if !decodeJSON(w, r, r.Body, &req) {
+		return
+	}
+
+	tokens, err := h.service.PINLogin(r.Context(), r.Header.Get(terminalKeyHeader), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials), errors.Is(err, services.ErrInvalidTerminal):
+			problem.Write(w, r, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, services.ErrPINLocked):
+			problem.Write(w, r, http.StatusTooManyRequests, err.Error())
+		default:
+			problem.Error(w, r, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tokens)
+}
+
+// HandleRefresh handles POST /api/auth/refresh
+func (h *AuthHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if !decodeJSON(w, r, r.Body, &req) {
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		problem.Write(w, r, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tokens)
+}
+
+// HandleLogout handles POST /api/auth/logout (mencabut sesi dari access token yang dipakai)
+func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	if err := h.service.Logout(r.Context(), principal.SessionID); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "Logged out successfully",
+	})
+}
+
+// HandleMe handles GET /api/auth/me
+func (h *AuthHandler) HandleMe(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	if principal.APIKeyID != nil {
+		// klien API key tidak punya akun user; kembalikan identitas & scope-nya
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(principal)
+		return
+	}
+	user, err := h.userService.GetByID(r.Context(), principal.UserID)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(user)
+}
+
+// currentUserID mengembalikan ID user yang login, atau nil untuk klien API key.
+func currentUserID(r *http.Request) *int {
+	if p, ok := middleware.PrincipalFrom(r.Context()); ok && p.UserID != 0 {
+		id := p.UserID
+		return &id
+	}
+	return nil
+}
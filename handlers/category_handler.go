@@ -1,40 +1,28 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"kasir-api/models"
+	"kasir-api/problem"
 	"kasir-api/services"
 	"net/http"
-	"strconv"
-	"strings"
 )
 
 type CategoryHandler struct {
 	service *services.CategoryService
+	audit   *services.AuditService
 }
 
-func NewCategoryHandler(service *services.CategoryService) *CategoryHandler {
-	return &CategoryHandler{service: service}
+func NewCategoryHandler(service *services.CategoryService, audit *services.AuditService) *CategoryHandler {
+	return &CategoryHandler{service: service, audit: audit}
 }
 
-// HandleCategories handles requests for GET|POST /api/categories
-func (h *CategoryHandler) HandleCategories(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.getAllCategories(w, r)
-	case http.MethodPost:
-		h.createCategory(w, r)
-	case http.MethodOptions:
-		w.WriteHeader(http.StatusNoContent)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-func (h *CategoryHandler) getAllCategories(w http.ResponseWriter, r *http.Request) {
-	items, err := h.service.GetAllCategories()
+// GetAllCategories - GET /api/categories
+func (h *CategoryHandler) GetAllCategories(w http.ResponseWriter, r *http.Request) {
+	items, err := h.service.GetAllCategories(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to get all categories: "+err.Error(), http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -42,14 +30,18 @@ func (h *CategoryHandler) getAllCategories(w http.ResponseWriter, r *http.Reques
 	_ = json.NewEncoder(w).Encode(items)
 }
 
-func (h *CategoryHandler) createCategory(w http.ResponseWriter, r *http.Request) {
+// CreateCategory - POST /api/categories
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	var c models.Category
-	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
-		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, r.Body, &c, func() error { return h.service.Validate(&c) }) {
 		return
 	}
-	if err := h.service.CreateCategory(&c); err != nil {
-		http.Error(w, "Failed to create category: "+err.Error(), http.StatusInternalServerError)
+	err := audited(h.audit, r, models.AuditCreate, "category", func(ctx context.Context) (int, any, any, error) {
+		err := h.service.CreateCategory(ctx, &c)
+		return c.ID, nil, c, err
+	})
+	if err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -58,33 +50,16 @@ func (h *CategoryHandler) createCategory(w http.ResponseWriter, r *http.Request)
 	_ = json.NewEncoder(w).Encode(c)
 }
 
-// HandleCategoryByID handles requests for GET|PUT|DELETE /api/categories/{id}
-func (h *CategoryHandler) HandleCategoryByID(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.getCategoryByID(w, r)
-	case http.MethodPut:
-		h.updateCategory(w, r)
-	case http.MethodDelete:
-		h.deleteCategory(w, r)
-	case http.MethodOptions:
-		w.WriteHeader(http.StatusNoContent)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-func (h *CategoryHandler) getCategoryByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/category/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+// GetCategoryByID - GET /api/categories/{id}
+func (h *CategoryHandler) GetCategoryByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "category")
+	if !ok {
 		return
 	}
 
-	category, err := h.service.GetCategoryByID(id)
+	category, err := h.service.GetCategoryByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Category not found: "+err.Error(), http.StatusNotFound)
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -92,11 +67,10 @@ func (h *CategoryHandler) getCategoryByID(w http.ResponseWriter, r *http.Request
 	_ = json.NewEncoder(w).Encode(category)
 }
 
-func (h *CategoryHandler) updateCategory(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/category/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+// UpdateCategory - PUT /api/categories/{id}
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "category")
+	if !ok {
 		return
 	}
 
@@ -106,55 +80,50 @@ func (h *CategoryHandler) updateCategory(w http.ResponseWriter, r *http.Request)
 	}
 	var req UpdateReq
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, r.Body, &req) {
 		return
 	}
-	old, err := h.service.GetCategoryByID(id)
+	var updated *models.Category
+	err := audited(h.audit, r, models.AuditUpdate, "category", func(ctx context.Context) (int, any, any, error) {
+		old, err := h.service.GetCategoryByID(ctx, id)
+		if err != nil {
+			return id, nil, nil, err
+		}
+		before := *old
+		if req.Name != nil {
+			old.Name = *req.Name
+		}
+		if req.Description != nil {
+			old.Description = *req.Description
+		}
+		updated = old
+		return id, before, old, h.service.UpdateCategory(ctx, old)
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
-		return
-	}
-	if req.Name != nil {
-		old.Name = *req.Name
-	}
-	if req.Description != nil {
-		old.Description = *req.Description
-	}
-
-	if err := h.service.UpdateCategory(old); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(old)
-
-	var updatedCategory models.Category
-	if err := json.NewDecoder(r.Body).Decode(&updatedCategory); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	updatedCategory.ID = id
-	if err := h.service.UpdateCategory(&updatedCategory); err != nil {
-		http.Error(w, "Failed to update category: "+err.Error(), http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(updatedCategory)
+	_ = json.NewEncoder(w).Encode(updated)
 }
 
-func (h *CategoryHandler) deleteCategory(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/category/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+// DeleteCategory - DELETE /api/categories/{id}
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "category")
+	if !ok {
 		return
 	}
 
-	if err := h.service.DeleteCategory(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	err := audited(h.audit, r, models.AuditDelete, "category", func(ctx context.Context) (int, any, any, error) {
+		before, err := h.service.GetCategoryByID(ctx, id)
+		if err != nil {
+			return id, nil, nil, err
+		}
+		return id, before, nil, h.service.DeleteCategory(ctx, id)
+	})
+	if err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
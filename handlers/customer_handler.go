@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+)
+
+type CustomerHandler struct {
+	service *services.CustomerService
+}
+
+func NewCustomerHandler(service *services.CustomerService) *CustomerHandler {
+	return &CustomerHandler{service: service}
+}
+
+// GetAllCustomers - GET /api/customers
+func (h *CustomerHandler) GetAllCustomers(w http.ResponseWriter, r *http.Request) {
+	// ?phone= dipakai di kasir untuk mengenali pelanggan dari nomor HP
+	if phone := r.URL.Query().Get("phone"); phone != "" {
+		customer, err := h.service.GetByPhone(r.Context(), phone)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]models.Customer{*customer})
+		return
+	}
+
+	customers, err := h.service.GetAll(r.Context(), r.URL.Query().Get("q"))
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(customers)
+}
+
+// CreateCustomer - POST /api/customers
+func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	var c models.Customer
+	if !decodeJSON(w, r, r.Body, &c) {
+		return
+	}
+	if err := h.service.Create(r.Context(), &c); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(c)
+}
+
+// GetCustomerByID - GET /api/customers/{id}
+func (h *CustomerHandler) GetCustomerByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "customer")
+	if !ok {
+		return
+	}
+
+	customer, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(customer)
+}
+
+// UpdateCustomer - PUT /api/customers/{id}
+func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "customer")
+	if !ok {
+		return
+	}
+
+	body, ok := readBody(w, r, r.Body)
+	if !ok {
+		return
+	}
+
+	type UpdateReq struct {
+		Name        *string       `json:"name"`
+		Phone       *string       `json:"phone"`
+		Email       *string       `json:"email"`
+		Notes       *string       `json:"notes"`
+		CreditLimit *money.Amount `json:"credit_limit"`
+		// PriceListID membedakan "missing" dari null (null → harga eceran)
+		PriceListID optional[int] `json:"price_list_id"`
+	}
+	var req UpdateReq
+	if !decodeJSON(w, r, bytes.NewReader(body), &req) {
+		return
+	}
+
+	old, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if req.Name != nil {
+		old.Name = *req.Name
+	}
+	if req.Phone != nil {
+		old.Phone = *req.Phone
+	}
+	if req.Email != nil {
+		old.Email = *req.Email
+	}
+	if req.Notes != nil {
+		old.Notes = *req.Notes
+	}
+	if req.CreditLimit != nil {
+		old.CreditLimit = *req.CreditLimit
+	}
+	if req.PriceListID.Set {
+		old.PriceListID = req.PriceListID.Value
+	}
+
+	if err := h.service.Update(r.Context(), old); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(old)
+}
+
+// DeleteCustomer - DELETE /api/customers/{id}
+func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "customer")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "Customer deleted successfully",
+	})
+}
+
+// GetCustomerTransactions - GET /api/customers/{id}/transactions
+func (h *CustomerHandler) GetCustomerTransactions(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "customer")
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	transactions, err := h.service.GetTransactions(r.Context(), id, limit)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(transactions)
+}
+
+// GetCustomerSummary - GET /api/customers/{id}/summary
+func (h *CustomerHandler) GetCustomerSummary(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "customer")
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.GetSummary(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(summary)
+}
+
+// GetCustomerPoints - GET /api/customers/{id}/points
+func (h *CustomerHandler) GetCustomerPoints(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "customer")
+	if !ok {
+		return
+	}
+
+	balance, err := h.service.GetPoints(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(balance)
+}
+
+// GetCustomerReceivables - GET /api/customers/{id}/receivables
+func (h *CustomerHandler) GetCustomerReceivables(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "customer")
+	if !ok {
+		return
+	}
+
+	credit, err := h.service.GetReceivables(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(credit)
+}
+
+// CreateRepayment - POST /api/customers/{id}/repayments
+func (h *CustomerHandler) CreateRepayment(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "customer")
+	if !ok {
+		return
+	}
+
+	var p models.ReceivablePayment
+	if !decodeJSON(w, r, r.Body, &p) {
+		return
+	}
+	p.CustomerID = id
+	if err := h.service.Repay(r.Context(), &p); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(p)
+}
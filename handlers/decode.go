@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// decodeJSON membaca body JSON ke dst. Field asing dan tipe yang salah dikumpulkan
+// semuanya lalu dilaporkan sekaligus dalam satu 422; jika ada, validasi service (validate,
+// tanpa efek samping) tetap dijalankan atas isi yang berhasil dibaca dan hasilnya
+// digabung, supaya klien melihat semua kesalahannya dalam satu respons. Body yang
+// melebihi batas middleware.MaxBodySize menjadi 413 dan JSON yang rusak 400.
+// Mengembalikan false jika respons error sudah ditulis.
+func decodeJSON(w http.ResponseWriter, r *http.Request, body io.Reader, dst any, validate ...func() error) bool {
+	data, ok := readBody(w, r, body)
+	if !ok {
+		return false
+	}
+
+	var raw any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return false
+	}
+	t := reflect.TypeOf(dst).Elem()
+	if !jsonMatches(raw, t) {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request: body must be "+jsonType(t))
+		return false
+	}
+
+	var errs models.ValidationErrors
+	checkJSON(raw, t, "", &errs)
+	// nilai yang tipenya salah dilewati; field lain tetap terisi untuk validasi di bawah
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(dst); err != nil && len(errs) == 0 {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return false
+	}
+	if len(errs) == 0 {
+		return true
+	}
+
+	reported := map[string]bool{}
+	for _, e := range errs {
+		reported[e.Field] = true
+	}
+	for _, fn := range validate {
+		var fields models.ValidationErrors
+		if !errors.As(fn(), &fields) {
+			continue
+		}
+		for _, f := range fields {
+			// "is required" untuk field yang tipenya salah hanya mengulang error di atas
+			if !reported[f.Field] {
+				errs = append(errs, f)
+			}
+		}
+	}
+	problem.Error(w, r, errs)
+	return false
+}
+
+// readBody membaca seluruh body, untuk handler yang perlu memeriksa JSON mentah (mis.
+// membedakan field yang tidak dikirim dari null) sebelum atau sesudah decodeJSON.
+func readBody(w http.ResponseWriter, r *http.Request, body io.Reader) ([]byte, bool) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var sizeErr *http.MaxBytesError
+		if errors.As(err, &sizeErr) {
+			problem.Write(w, r, http.StatusRequestEntityTooLarge, "request body must not exceed "+strconv.FormatInt(sizeErr.Limit, 10)+" bytes")
+		} else {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid request: "+err.Error())
+		}
+		return nil, false
+	}
+	return data, true
+}
+
+// optional membedakan field yang tidak dikirim (Set false) dari null (Set true, Value nil)
+// pada update parsial, mis. category_id: null untuk melepas kategori. Tipe nilainya tetap
+// diperiksa checkJSON seperti field biasa.
+type optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (o *optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+func (o *optional[T]) valueType() reflect.Type { return reflect.TypeFor[T]() }
+
+type optionalField interface{ valueType() reflect.Type }
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	optionalFieldType   = reflect.TypeFor[optionalField]()
+)
+
+// optionalValue mengembalikan tipe nilai T jika t adalah optional[T].
+func optionalValue(t reflect.Type) (reflect.Type, bool) {
+	if !reflect.PointerTo(t).Implements(optionalFieldType) {
+		return nil, false
+	}
+	return reflect.New(t).Interface().(optionalField).valueType(), true
+}
+
+// checkJSON membandingkan nilai JSON v dengan tipe tujuan t dan mencatat field asing serta
+// tipe yang salah, dengan nama field seperti validasi service (mis. items[0].quantity).
+func checkJSON(v any, t reflect.Type, path string, errs *models.ValidationErrors) {
+	if v == nil {
+		return // null dibiarkan seperti encoding/json
+	}
+	if t.Kind() == reflect.Pointer {
+		checkJSON(v, t.Elem(), path, errs)
+		return
+	}
+	if vt, ok := optionalValue(t); ok {
+		checkJSON(v, vt, path, errs)
+		return
+	}
+	if custom(t) {
+		return // diperiksa UnmarshalJSON/UnmarshalText milik tipe itu sendiri
+	}
+	if !jsonMatches(v, t) {
+		*errs = append(*errs, models.FieldError{Field: path, Message: "must be " + jsonType(t)})
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj := v.(map[string]any)
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(obj) {
+			f, ok := fields[key]
+			if !ok {
+				// encoding/json mencocokkan nama field tanpa membedakan huruf besar/kecil
+				f, ok = fields[strings.ToLower(key)]
+			}
+			if !ok {
+				*errs = append(*errs, models.FieldError{Field: joinPath(path, key), Message: "is not a known field"})
+				continue
+			}
+			checkJSON(obj[key], f.Type, joinPath(path, f.name), errs)
+		}
+	case reflect.Map:
+		obj := v.(map[string]any)
+		for _, key := range sortedKeys(obj) {
+			checkJSON(obj[key], t.Elem(), joinPath(path, key), errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if arr, ok := v.([]any); ok {
+			for i, elem := range arr {
+				checkJSON(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+}
+
+// jsonMatches melaporkan apakah nilai JSON v bisa dibaca ke tipe t tanpa UnmarshalTypeError.
+func jsonMatches(v any, t reflect.Type) bool {
+	if vt, ok := optionalValue(t); ok {
+		return jsonMatches(v, vt)
+	}
+	if v == nil || custom(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonMatches(v, t.Elem())
+	case reflect.Interface:
+		return true
+	case reflect.Struct, reflect.Map:
+		_, ok := v.(map[string]any)
+		return ok
+	case reflect.Slice:
+		if _, ok := v.(string); ok && t.Elem().Kind() == reflect.Uint8 {
+			return true // []byte ditulis sebagai base64
+		}
+		_, ok := v.([]any)
+		return ok
+	case reflect.Array:
+		_, ok := v.([]any)
+		return ok
+	case reflect.String:
+		_, ok := v.(string)
+		return ok
+	case reflect.Bool:
+		_, ok := v.(bool)
+		return ok
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseInt(string(n), 10, t.Bits())
+		return err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseUint(string(n), 10, t.Bits())
+		return err == nil
+	case reflect.Float32, reflect.Float64:
+		_, ok := v.(json.Number)
+		return ok
+	}
+	return true
+}
+
+func custom(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return p.Implements(jsonUnmarshalerType) || p.Implements(textUnmarshalerType)
+}
+
+type jsonField struct {
+	reflect.StructField
+	name string
+}
+
+// jsonFields mengembalikan field struct menurut nama JSON-nya (juga dalam huruf kecil),
+// termasuk field dari struct yang di-embed.
+func jsonFields(t reflect.Type) map[string]jsonField {
+	fields := map[string]jsonField{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			et := f.Type
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				for k, ef := range jsonFields(et) {
+					if _, ok := fields[k]; !ok {
+						fields[k] = ef
+					}
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		jf := jsonField{StructField: f, name: name}
+		fields[name] = jf
+		if _, ok := fields[strings.ToLower(name)]; !ok {
+			fields[strings.ToLower(name)] = jf
+		}
+	}
+	return fields
+}
+
+func sortedKeys(obj map[string]any) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// jsonType menamai tipe Go dengan istilah JSON untuk pesan error ke klien.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonType(t.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,133 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"kasir-api/auth"
+	"kasir-api/handlers"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/problem"
+	"kasir-api/repositories/memory"
+	"kasir-api/router"
+	"kasir-api/services"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Header uji untuk memilih peran user yang "login"; autentikasi sungguhan tidak diuji di sini.
+const testRoleHeader = "X-Test-Role"
+
+// supervisorPIN adalah satu-satunya override yang diterima fakeVerifier.
+const supervisorPIN = "1234"
+
+type fakeVerifier struct{}
+
+func (fakeVerifier) VerifyOverride(ctx context.Context, username, pin string) (*models.Principal, error) {
+	if username != "spv" || pin != supervisorPIN {
+		return nil, services.ErrInvalidOverride
+	}
+	return &models.Principal{TenantID: models.DefaultTenantID, UserID: 2, Username: "spv", Role: auth.RoleSupervisor}, nil
+}
+
+// newTestAPI merakit route katalog, checkout, dan laporan di atas memory.Store, dengan
+// user kasir (id 1) atau peran dari header X-Test-Role.
+func newTestAPI(t *testing.T) (*memory.Store, http.Handler) {
+	t.Helper()
+	store := memory.New()
+	guard := middleware.NewGuard(fakeVerifier{})
+
+	productHandler := handlers.NewProductHandler(services.NewProductService(store.Products(), store.Categories(), nil, nil), nil, nil)
+	categoryHandler := handlers.NewCategoryHandler(services.NewCategoryService(store.Categories()), nil)
+	transactionHandler := handlers.NewTransactionHandler(
+		services.NewTransactionService(store.Transactions(), store.Products(), nil, nil, nil, 10), guard, nil)
+	reportHandler := handlers.NewReportHandler(services.NewReportService(store.Reports(), nil))
+
+	rt := router.New()
+	rt.HandleFunc("GET /api/products", guard.Require(auth.ProductsRead, productHandler.GetAll))
+	rt.HandleFunc("POST /api/products", guard.Require(auth.ProductsWrite, productHandler.Create))
+	rt.HandleFunc("GET /api/products/{id}", guard.Require(auth.ProductsRead, productHandler.GetByID))
+	rt.HandleFunc("PUT /api/products/{id}", guard.Require(auth.ProductsWrite, productHandler.Update))
+	rt.HandleFunc("DELETE /api/products/{id}", guard.Require(auth.ProductsWrite, productHandler.Delete))
+	rt.HandleFunc("POST /api/categories", guard.Require(auth.CategoriesWrite, categoryHandler.CreateCategory))
+	rt.HandleFunc("POST /api/checkout", guard.Require(auth.TransactionsCreate, transactionHandler.Checkout))
+	rt.HandleFunc("GET /api/transactions/{id}", guard.Require(auth.TransactionsRead, transactionHandler.GetByID))
+	rt.HandleFunc("POST /api/transactions/{id}/refund", guard.Require(auth.TransactionsVoid, transactionHandler.Refund))
+	rt.HandleFunc("GET /api/report/today", guard.Require(auth.ReportsRead, reportHandler.HandleReportToday))
+
+	return store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := r.Header.Get(testRoleHeader)
+		if role == "" {
+			role = auth.RoleCashier
+		}
+		p := &models.Principal{TenantID: models.DefaultTenantID, UserID: 1, Username: role, Role: role}
+		rt.ServeHTTP(w, r.WithContext(middleware.WithPrincipal(r.Context(), p)))
+	})
+}
+
+// do mengirim request ke h; header berpasangan nama, nilai.
+func do(t *testing.T, h http.Handler, method, path, body string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	var rd io.Reader
+	if body != "" {
+		rd = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, rd)
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func itoa(i int) string { return strconv.Itoa(i) }
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %T from %q: %v", v, rec.Body.String(), err)
+	}
+	return v
+}
+
+// wantProblem memastikan respons adalah dokumen problem+json dengan status tersebut.
+func wantProblem(t *testing.T, rec *httptest.ResponseRecorder, status int) problem.Details {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, status, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, problem.ContentType)
+	}
+	p := decode[problem.Details](t, rec)
+	if p.Status != status {
+		t.Errorf("problem status = %d, want %d", p.Status, status)
+	}
+	return p
+}
+
+func seedProduct(t *testing.T, store *memory.Store, name string, price int64, stock float64) int {
+	t.Helper()
+	p := models.Product{Name: name, Price: money.Amount(price), Stock: stock, Unit: "pcs"}
+	if err := store.Products().Create(context.Background(), &p); err != nil {
+		t.Fatalf("seed %s: %v", name, err)
+	}
+	return p.ID
+}
+
+func stock(t *testing.T, store *memory.Store, id int) float64 {
+	t.Helper()
+	p, err := store.Products().GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get product %d: %v", id, err)
+	}
+	return p.Stock
+}
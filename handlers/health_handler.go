@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// readinessTimeout membatasi semua pemeriksaan dalam satu /readyz; probe orchestrator
+// biasanya menyerah setelah beberapa detik.
+const readinessTimeout = 3 * time.Second
+
+// ReadinessCheck memeriksa satu dependensi (database, migrasi, dsb.). Error berarti
+// instance ini belum boleh menerima trafik.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// BuildInfo ditampilkan di /version.
+type BuildInfo struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// HealthHandler melayani endpoint untuk orchestrator/load balancer; semuanya tanpa
+// autentikasi dan di luar tenant mana pun.
+type HealthHandler struct {
+	build        BuildInfo
+	checks       []ReadinessCheck
+	shuttingDown atomic.Bool
+}
+
+func NewHealthHandler(build BuildInfo, checks ...ReadinessCheck) *HealthHandler {
+	return &HealthHandler{build: build, checks: checks}
+}
+
+// SetShuttingDown membuat /readyz gagal seterusnya, dipanggil begitu shutdown dimulai
+// agar load balancer berhenti mengirim request baru ke instance ini.
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Healthz handles GET /healthz: proses hidup dan bisa melayani HTTP. Sengaja tidak
+// memeriksa database, supaya gangguan database tidak membuat proses di-restart.
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, healthResponse{Status: "ok"})
+}
+
+// Readyz handles GET /readyz: 200 jika semua pemeriksaan lolos, 503 jika ada yang gagal
+// atau server sedang shutdown. Pesan error hanya dicatat di log (endpoint ini publik).
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		writeHealth(w, http.StatusServiceUnavailable, healthResponse{Status: "shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	resp := healthResponse{Status: "ok", Checks: make(map[string]string, len(h.checks))}
+	status := http.StatusOK
+	for _, c := range h.checks {
+		if err := c.Check(ctx); err != nil {
+			slog.WarnContext(r.Context(), "readiness check failed", "check", c.Name, "err", err)
+			resp.Checks[c.Name] = "failing"
+			resp.Status, status = "unavailable", http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[c.Name] = "ok"
+	}
+	writeHealth(w, status, resp)
+}
+
+// Version handles GET /version
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.build)
+}
+
+func writeHealth(w http.ResponseWriter, status int, resp healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
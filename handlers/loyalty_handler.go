@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+)
+
+type LoyaltyHandler struct {
+	service *services.LoyaltyService
+}
+
+func NewLoyaltyHandler(service *services.LoyaltyService) *LoyaltyHandler {
+	return &LoyaltyHandler{service: service}
+}
+
+// GetRules - GET /api/loyalty/rules
+func (h *LoyaltyHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetRules(r.Context())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(rules)
+}
+
+// UpdateRules - PUT /api/loyalty/rules
+func (h *LoyaltyHandler) UpdateRules(w http.ResponseWriter, r *http.Request) {
+	var rules models.LoyaltyRules
+	if !decodeJSON(w, r, r.Body, &rules) {
+		return
+	}
+	if err := h.service.UpdateRules(r.Context(), &rules); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(rules)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+)
+
+type ModifierHandler struct {
+	service *services.ModifierService
+}
+
+func NewModifierHandler(service *services.ModifierService) *ModifierHandler {
+	return &ModifierHandler{service: service}
+}
+
+// GetAllGroups - GET /api/modifier-groups
+func (h *ModifierHandler) GetAllGroups(w http.ResponseWriter, r *http.Request) {
+	// ?product_id={id} → hanya grup yang berlaku untuk produk tersebut
+	productID := 0
+	if v := r.URL.Query().Get("product_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid product_id")
+			return
+		}
+		productID = id
+	}
+
+	groups, err := h.service.GetAll(r.Context(), productID)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(groups)
+}
+
+// CreateGroup - POST /api/modifier-groups
+func (h *ModifierHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var g models.ModifierGroup
+	if !decodeJSON(w, r, r.Body, &g) {
+		return
+	}
+	if err := h.service.Create(r.Context(), &g); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(g)
+}
+
+// GetGroupByID - GET /api/modifier-groups/{id}
+func (h *ModifierHandler) GetGroupByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "modifier group")
+	if !ok {
+		return
+	}
+
+	group, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(group)
+}
+
+// UpdateGroup - PUT /api/modifier-groups/{id}
+func (h *ModifierHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "modifier group")
+	if !ok {
+		return
+	}
+
+	// Update menerima grup lengkap; options menggantikan seluruh daftar opsi
+	var g models.ModifierGroup
+	if !decodeJSON(w, r, r.Body, &g) {
+		return
+	}
+
+	g.ID = id
+	if err := h.service.Update(r.Context(), &g); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(g)
+}
+
+// DeleteGroup - DELETE /api/modifier-groups/{id}
+func (h *ModifierHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "modifier group")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "Modifier group deleted successfully",
+	})
+}
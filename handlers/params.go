@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"kasir-api/problem"
+	"net/http"
+	"strconv"
+)
+
+// pathID membaca parameter path {name} (lihat pola route di routes.go) sebagai ID.
+// Mengembalikan false jika respons 400 sudah ditulis.
+func pathID(w http.ResponseWriter, r *http.Request, name, what string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid "+what+" ID")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+)
+
+type PriceListHandler struct {
+	service *services.PriceListService
+}
+
+func NewPriceListHandler(service *services.PriceListService) *PriceListHandler {
+	return &PriceListHandler{service: service}
+}
+
+// GetAllPriceLists - GET /api/price-lists
+func (h *PriceListHandler) GetAllPriceLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.service.GetAll(r.Context())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(lists)
+}
+
+// CreatePriceList - POST /api/price-lists
+func (h *PriceListHandler) CreatePriceList(w http.ResponseWriter, r *http.Request) {
+	var l models.PriceList
+	if !decodeJSON(w, r, r.Body, &l) {
+		return
+	}
+	if err := h.service.Create(r.Context(), &l); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(l)
+}
+
+// GetPriceListByID - GET /api/price-lists/{id}
+func (h *PriceListHandler) GetPriceListByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "price list")
+	if !ok {
+		return
+	}
+
+	list, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// UpdatePriceList - PUT /api/price-lists/{id}
+func (h *PriceListHandler) UpdatePriceList(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "price list")
+	if !ok {
+		return
+	}
+
+	type UpdateReq struct {
+		Code *string `json:"code"`
+		Name *string `json:"name"`
+	}
+	var req UpdateReq
+	if !decodeJSON(w, r, r.Body, &req) {
+		return
+	}
+
+	old, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if req.Code != nil {
+		old.Code = *req.Code
+	}
+	if req.Name != nil {
+		old.Name = *req.Name
+	}
+
+	if err := h.service.Update(r.Context(), old); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(old)
+}
+
+// ReplaceItems - PUT /api/price-lists/{id}/items
+func (h *PriceListHandler) ReplaceItems(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "price list")
+	if !ok {
+		return
+	}
+
+	var items []models.PriceListItem
+	if !decodeJSON(w, r, r.Body, &items) {
+		return
+	}
+	if _, err := h.service.GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if err := h.service.ReplaceItems(r.Context(), id, items); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	list, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// DeletePriceList - DELETE /api/price-lists/{id}
+func (h *PriceListHandler) DeletePriceList(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "price list")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "Price list deleted successfully",
+	})
+}
@@ -1,65 +1,57 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
-	"io"
 	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/problem"
 	"kasir-api/services"
+	"log/slog"
 	"net/http"
-	"strconv"
-	"strings"
-
-	"github.com/jackc/pgx/v5"
 )
 
 type ProductHandler struct {
 	service *services.ProductService
+	prices  *services.PriceHistoryService
+	audit   *services.AuditService
 }
 
-func NewProductHandler(service *services.ProductService) *ProductHandler {
-	return &ProductHandler{service: service}
-}
-
-// HandleProducts - GET /api/products|POST /api/products
-func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetAll(w, r)
-	case http.MethodPost:
-		h.Create(w, r)
-	case http.MethodOptions:
-		w.WriteHeader(http.StatusNoContent)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
+func NewProductHandler(service *services.ProductService, prices *services.PriceHistoryService, audit *services.AuditService) *ProductHandler {
+	return &ProductHandler{service: service, prices: prices, audit: audit}
 }
 
+// GetAll - GET /api/products
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	name := r.URL.Query().Get("name")
-	fmt.Println("Filtering products by name:", name)
-	products, err := h.service.GetAll(name)
+	slog.DebugContext(r.Context(), "filtering products by name", "name", name)
+	products, err := h.service.GetAll(r.Context(), name)
 	if err != nil {
-		http.Error(w, "Failed to get all products: "+err.Error(), http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(products); err != nil {
-		http.Error(w, "Failed to encode products: "+err.Error(), http.StatusInternalServerError)
+		problem.Error(w, r, err)
 	}
 }
 
+// Create - POST /api/products
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var newProduct models.Product
-	if err := json.NewDecoder(r.Body).Decode(&newProduct); err != nil {
-		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, r.Body, &newProduct, func() error { return h.service.Validate(r.Context(), &newProduct) }) {
 		return
 	}
+	newProduct.ChangedBy = currentUserID(r)
 
-	if err := h.service.Create(&newProduct); err != nil {
-		http.Error(w, "Failed to create product: "+err.Error(), http.StatusInternalServerError)
+	err := audited(h.audit, r, models.AuditCreate, "product", func(ctx context.Context) (int, any, any, error) {
+		err := h.service.Create(ctx, &newProduct)
+		return newProduct.ID, nil, newProduct, err
+	})
+	if err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -68,38 +60,16 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(newProduct)
 }
 
-// HandleProductByID - GET|PUT|DEL /api/product/{id}
-func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		h.GetByID(w, r)
-	case http.MethodPut:
-		h.Update(w, r)
-	case http.MethodDelete:
-		h.Delete(w, r)
-	case http.MethodOptions:
-		w.WriteHeader(http.StatusNoContent)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
+// GetByID - GET /api/products/{id}
 func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	// perbaiki prefix: gunakan /api/product/
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+	id, ok := pathID(w, r, "id", "product")
+	if !ok {
 		return
 	}
 
-	product, err := h.service.GetByID(id)
+	product, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) || strings.Contains(strings.ToLower(err.Error()), "not found") {
-			http.Error(w, "Product not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to get product: "+err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -107,127 +77,198 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(product)
 }
 
+// Update - PUT /api/products/{id}
 func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+	id, ok := pathID(w, r, "id", "product")
+	if !ok {
 		return
 	}
 
 	// Baca body sekali
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+	body, ok := readBody(w, r, r.Body)
+	if !ok {
 		return
 	}
-	defer r.Body.Close()
 
 	// Struct untuk field lain (pointer → partial update)
 	type UpdateReq struct {
-		Name  *string `json:"name"`
-		Price *int    `json:"price"`
-		Stock *int    `json:"stock"`
-		// CategoryID akan diproses manual untuk bedakan "missing" vs "null"
+		Name       *string       `json:"name"`
+		Price      *money.Amount `json:"price"`
+		Stock      *float64      `json:"stock"`
+		Unit       *string       `json:"unit"`
+		DecimalQty *bool         `json:"decimal_qty"`
+		PLU        *string       `json:"plu"`
+		// OptionAxes mengganti seluruh daftar sumbu varian (mis. ["size", "color"])
+		OptionAxes *[]string `json:"option_axes"`
+		// CategoryID membedakan "missing" (tidak diubah) dari null (kategori dilepas)
+		CategoryID optional[int] `json:"category_id"`
 	}
 
 	var req UpdateReq
-	if len(body) > 0 {
-		if err := json.Unmarshal(body, &req); err != nil {
-			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
-			return
-		}
+	if len(body) > 0 && !decodeJSON(w, r, bytes.NewReader(body), &req) {
+		return
 	}
 
-	// Deteksi presence key "category_id" dan nil vs value
-	var raw map[string]*json.RawMessage
-	if err := json.Unmarshal(body, &raw); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
-		return
-	}
-	categoryPresent := false
-	var categoryIDDecoded *int // jika present & bukan null → pointer ke int, jika present & null → tetap nil
-	if raw != nil {
-		if rm, ok := raw["category_id"]; ok {
-			categoryPresent = true
-			if rm != nil {
-				var v int
-				if err := json.Unmarshal(*rm, &v); err != nil {
-					http.Error(w, "Invalid category_id (must be number or null)", http.StatusBadRequest)
-					return
-				}
-				categoryIDDecoded = &v
-			} else {
-				// rm == nil → "category_id": null
-				categoryIDDecoded = nil
-			}
+	var updated *models.Product
+	err := audited(h.audit, r, models.AuditUpdate, "product", func(ctx context.Context) (int, any, any, error) {
+		old, err := h.service.GetByID(ctx, id)
+		if err != nil {
+			return id, nil, nil, err
 		}
-	}
+		// snapshot sebelum diubah untuk audit log
+		before := *old
 
-	old, err := h.service.GetByID(id)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) || strings.Contains(strings.ToLower(err.Error()), "not found") {
-			http.Error(w, "Product not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to get product: "+err.Error(), http.StatusInternalServerError)
+		if req.Name != nil {
+			old.Name = *req.Name
+		}
+		if req.Price != nil {
+			old.Price = *req.Price
+		}
+		if req.Stock != nil {
+			old.Stock = *req.Stock
 		}
+		if req.Unit != nil {
+			old.Unit = *req.Unit
+		}
+		if req.DecimalQty != nil {
+			old.DecimalQty = *req.DecimalQty
+		}
+		if req.PLU != nil {
+			old.PLU = *req.PLU
+		}
+		if req.OptionAxes != nil {
+			old.OptionAxes = *req.OptionAxes
+		}
+
+		// category_id: hanya ubah kalau key hadir; null → NULL di DB
+		if req.CategoryID.Set {
+			old.CategoryID = req.CategoryID.Value
+		}
+		old.ChangedBy = currentUserID(r)
+
+		if err := h.service.Update(ctx, old); err != nil {
+			return id, nil, nil, err
+		}
+		// Re-fetch setelah update agar category_name hasil JOIN ikut terbarui
+		updated, err = h.service.GetByID(ctx, id)
+		return id, before, updated, err
+	})
+	if err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
-	if req.Name != nil {
-		old.Name = *req.Name
-	}
-	if req.Price != nil {
-		old.Price = *req.Price
-	}
-	if req.Stock != nil {
-		old.Stock = *req.Stock
-	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(updated)
+}
 
-	// category_id: hanya ubah kalau key hadir
-	if categoryPresent {
-		// present + null → unset (set NULL di DB)
-		// present + number → set new value
-		old.CategoryID = categoryIDDecoded
+// Delete - DELETE /api/products/{id}
+func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "product")
+	if !ok {
+		return
 	}
 
-	if err := h.service.Update(old); err != nil {
-		http.Error(w, "Failed to update product: "+err.Error(), http.StatusBadRequest)
+	err := audited(h.audit, r, models.AuditDelete, "product", func(ctx context.Context) (int, any, any, error) {
+		// snapshot untuk audit log
+		before, err := h.service.GetByID(ctx, id)
+		if err != nil {
+			return id, nil, nil, err
+		}
+		return id, before, nil, h.service.Delete(ctx, id)
+	})
+	if err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
-	// Re-fetch setelah update agar category_name hasil JOIN ikut terbarui
-	updated, err := h.service.GetByID(id)
-	if err == nil {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(updated)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "Product deleted successfully",
+	})
+}
+
+// GetPriceHistory - GET /api/products/{id}/price-history
+func (h *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "product")
+	if !ok {
 		return
 	}
-
-	// Fallback: jika re-fetch gagal, set category_name sesuai perubahan category_id
-	if categoryPresent && categoryIDDecoded == nil {
-		old.CategoryName = ""
+	history, err := h.prices.GetHistory(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
 	}
-
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(old)
+	_ = json.NewEncoder(w).Encode(history)
 }
 
-func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/api/product/")
-	id, err := strconv.Atoi(idStr)
+// GetScheduledPrices - GET /api/products/{id}/scheduled-prices
+func (h *ProductHandler) GetScheduledPrices(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "product")
+	if !ok {
+		return
+	}
+	scheduled, err := h.prices.GetScheduled(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		problem.Error(w, r, err)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(scheduled)
+}
 
-	if err := h.service.Delete(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// SchedulePrice - POST /api/products/{id}/scheduled-prices {"price": 15000, "effective_at": "2025-01-01T00:00:00+07:00"}
+func (h *ProductHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "product")
+	if !ok {
+		return
+	}
+	var sp models.ScheduledPrice
+	if !decodeJSON(w, r, r.Body, &sp) {
+		return
+	}
+	sp.ProductID = id
+	sp.CreatedBy = currentUserID(r)
+
+	err := audited(h.audit, r, models.AuditCreate, "scheduled_price", func(ctx context.Context) (int, any, any, error) {
+		err := h.prices.Schedule(ctx, &sp)
+		return sp.ID, nil, sp, err
+	})
+	if err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{
-		"message": "Product deleted successfully",
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(sp)
+}
+
+// CancelScheduledPrice - DELETE /api/products/{id}/scheduled-prices/{sid}
+func (h *ProductHandler) CancelScheduledPrice(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "product")
+	if !ok {
+		return
+	}
+	sid, ok := pathID(w, r, "sid", "scheduled price")
+	if !ok {
+		return
+	}
+	var after models.ScheduledPrice
+	err := audited(h.audit, r, models.AuditUpdate, "scheduled_price", func(ctx context.Context) (int, any, any, error) {
+		before, err := h.prices.Cancel(ctx, id, sid)
+		if err != nil {
+			return sid, nil, nil, err
+		}
+		after = *before
+		after.Status = models.ScheduledPriceCancelled
+		return sid, before, after, nil
 	})
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(after)
 }
@@ -0,0 +1,98 @@
+package handlers_test
+
+import (
+	"kasir-api/auth"
+	"kasir-api/models"
+	"net/http"
+	"testing"
+)
+
+func TestProductCRUD(t *testing.T) {
+	_, api := newTestAPI(t)
+	manager := []string{testRoleHeader, auth.RoleManager}
+
+	rec := do(t, api, http.MethodPost, "/api/categories", `{"name":"Minuman"}`, manager...)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create category: %d %s", rec.Code, rec.Body.String())
+	}
+	category := decode[models.Category](t, rec)
+
+	rec = do(t, api, http.MethodPost, "/api/products",
+		`{"name":"Teh Botol","price":5000,"stock":24,"category_id":`+itoa(category.ID)+`}`, manager...)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create product: %d %s", rec.Code, rec.Body.String())
+	}
+	created := decode[models.Product](t, rec)
+	if created.ID == 0 || created.Unit != "pcs" {
+		t.Errorf("created = %+v, want id and default unit pcs", created)
+	}
+
+	rec = do(t, api, http.MethodGet, "/api/products/"+itoa(created.ID), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get product: %d %s", rec.Code, rec.Body.String())
+	}
+	if got := decode[models.Product](t, rec); got.CategoryName != "Minuman" || got.Price != 5000 {
+		t.Errorf("product = %+v", got)
+	}
+
+	rec = do(t, api, http.MethodPut, "/api/products/"+itoa(created.ID), `{"price":5500,"category_id":null}`, manager...)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update product: %d %s", rec.Code, rec.Body.String())
+	}
+	if got := decode[models.Product](t, rec); got.Price != 5500 || got.CategoryID != nil || got.Name != "Teh Botol" {
+		t.Errorf("updated = %+v, want price 5500, no category, name kept", got)
+	}
+
+	rec = do(t, api, http.MethodGet, "/api/products?name=teh", "")
+	if got := decode[[]models.Product](t, rec); len(got) != 1 {
+		t.Errorf("filtered products = %+v, want 1", got)
+	}
+
+	if rec := do(t, api, http.MethodDelete, "/api/products/"+itoa(created.ID), "", manager...); rec.Code/100 != 2 {
+		t.Fatalf("delete product: %d %s", rec.Code, rec.Body.String())
+	}
+	wantProblem(t, do(t, api, http.MethodGet, "/api/products/"+itoa(created.ID), ""), http.StatusNotFound)
+}
+
+func TestProductErrors(t *testing.T) {
+	_, api := newTestAPI(t)
+	manager := []string{testRoleHeader, auth.RoleManager}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		role   []string
+		status int
+		fields []string
+	}{
+		{"cashier cannot create", http.MethodPost, "/api/products", `{"name":"X","price":1}`, nil, http.StatusForbidden, nil},
+		{"invalid id", http.MethodGet, "/api/products/abc", "", nil, http.StatusBadRequest, nil},
+		{"unknown product", http.MethodGet, "/api/products/42", "", nil, http.StatusNotFound, nil},
+		{"malformed json", http.MethodPost, "/api/products", `{"name":`, manager, http.StatusBadRequest, nil},
+		{"wrong type", http.MethodPost, "/api/products", `{"name":"X","price":"mahal"}`, manager, http.StatusUnprocessableEntity, []string{"price"}},
+		{"invalid fields", http.MethodPost, "/api/products", `{"name":"","price":-1,"stock":-5,"category_id":99}`, manager,
+			http.StatusUnprocessableEntity, []string{"name", "price", "stock", "category_id"}},
+		{"update wrong types", http.MethodPut, "/api/products/1", `{"price":"mahal","category_id":"minuman"}`, manager,
+			http.StatusUnprocessableEntity, []string{"price", "category_id"}},
+		{"method not allowed", http.MethodPatch, "/api/products/1", `{}`, manager, http.StatusMethodNotAllowed, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := wantProblem(t, do(t, api, tt.method, tt.path, tt.body, tt.role...), tt.status)
+			if len(tt.fields) == 0 {
+				return
+			}
+			got := map[string]bool{}
+			for _, f := range p.Errors {
+				got[f.Field] = true
+			}
+			for _, f := range tt.fields {
+				if !got[f] {
+					t.Errorf("missing field error %q in %+v", f, p.Errors)
+				}
+			}
+		})
+	}
+}
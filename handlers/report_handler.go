@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"kasir-api/problem"
 	"kasir-api/services"
 	"net/http"
 )
@@ -15,13 +16,22 @@ func NewReportHandler(service *services.ReportService) *ReportHandler {
 }
 
 func (h *ReportHandler) HandleReportToday(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	// ?group_by=variant memecah produk terlaris per varian
+	byVariant := r.URL.Query().Get("group_by") == "variant"
+	report, err := h.service.GetTodayReport(r.Context(), byVariant)
+	if err != nil {
+		problem.Error(w, r, err)
 		return
 	}
-	report, err := h.service.GetTodayReport()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// HandleReceivablesAging handles GET /api/report/receivables-aging
+func (h *ReportHandler) HandleReceivablesAging(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.GetReceivablesAging(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to get report: "+err.Error(), http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
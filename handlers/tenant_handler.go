@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+)
+
+type TenantHandler struct {
+	service *services.TenantService
+}
+
+func NewTenantHandler(service *services.TenantService) *TenantHandler {
+	return &TenantHandler{service: service}
+}
+
+// GetAllTenants - GET /api/tenants
+func (h *TenantHandler) GetAllTenants(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.service.GetAll(r.Context())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tenants)
+}
+
+// CreateTenant - POST /api/tenants
+func (h *TenantHandler) CreateTenant(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTenantRequest
+	if !decodeJSON(w, r, r.Body, &req) {
+		return
+	}
+	t, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(t)
+}
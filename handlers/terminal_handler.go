@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+)
+
+type TerminalHandler struct {
+	service *services.TerminalService
+}
+
+func NewTerminalHandler(service *services.TerminalService) *TerminalHandler {
+	return &TerminalHandler{service: service}
+}
+
+// GetAllTerminals - GET /api/terminals
+func (h *TerminalHandler) GetAllTerminals(w http.ResponseWriter, r *http.Request) {
+	terminals, err := h.service.GetAll(r.Context())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(terminals)
+}
+
+// CreateTerminal - POST /api/terminals
+func (h *TerminalHandler) CreateTerminal(w http.ResponseWriter, r *http.Request) {
+	t := models.Terminal{Active: true}
+	if !decodeJSON(w, r, r.Body, &t) {
+		return
+	}
+	if err := h.service.Create(r.Context(), &t); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(t)
+}
+
+// GetTerminalByID - GET /api/terminals/{id}
+func (h *TerminalHandler) GetTerminalByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "terminal")
+	if !ok {
+		return
+	}
+
+	terminal, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(terminal)
+}
+
+// UpdateTerminal - PUT /api/terminals/{id}
+func (h *TerminalHandler) UpdateTerminal(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "terminal")
+	if !ok {
+		return
+	}
+
+	type UpdateReq struct {
+		Name    *string `json:"name"`
+		Active  *bool   `json:"active"`
+		UserIDs *[]int  `json:"user_ids"`
+	}
+	var req UpdateReq
+	if !decodeJSON(w, r, r.Body, &req) {
+		return
+	}
+
+	old, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if req.Name != nil {
+		old.Name = *req.Name
+	}
+	if req.Active != nil {
+		old.Active = *req.Active
+	}
+	if req.UserIDs != nil {
+		old.UserIDs = *req.UserIDs
+	}
+
+	if err := h.service.Update(r.Context(), old); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(old)
+}
+
+// DeleteTerminal - DELETE /api/terminals/{id}
+func (h *TerminalHandler) DeleteTerminal(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "terminal")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "Terminal deleted successfully",
+	})
+}
@@ -1,41 +1,109 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"kasir-api/middleware"
 	"kasir-api/models"
+	"kasir-api/problem"
 	"kasir-api/services"
 	"net/http"
 )
 
 type TransactionHandler struct {
 	services *services.TransactionService
+	guard    *middleware.Guard
+	audit    *services.AuditService
 }
 
-func NewTransactionHandler(services *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{services: services}
+func NewTransactionHandler(services *services.TransactionService, guard *middleware.Guard, audit *services.AuditService) *TransactionHandler {
+	return &TransactionHandler{services: services, guard: guard, audit: audit}
 }
 
-// multiple item apa aja, quantity nya
-func (h *TransactionHandler) HandleCheckout(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodPost:
-		h.Checkout(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// Checkout - POST /api/checkout
+func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	var req models.CheckoutRequest
+	if !decodeJSON(w, r, r.Body, &req, func() error { return h.services.ValidateCheckout(&req) }) {
+		return
+	}
+
+	// kasir & terminal diambil dari sesi login, bukan dari body request
+	principal, _ := middleware.PrincipalFrom(r.Context())
+	if principal != nil && principal.UserID != 0 {
+		req.CashierID = &principal.UserID
+		req.TerminalID = principal.TerminalID
+	}
+
+	// ubah harga / diskon besar: kasir butuh persetujuan supervisor lewat header override
+	for _, p := range h.services.RequiredPermissions(&req) {
+		approver, err := h.guard.Authorize(r, p)
+		if err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+		if principal == nil || approver.UserID != principal.UserID {
+			req.ApprovedBy = &approver.UserID
+		}
 	}
+
+	var transaction *models.Transaction
+	err := audited(h.audit, r, models.AuditCheckout, "transaction", func(ctx context.Context) (int, any, any, error) {
+		var err error
+		if transaction, err = h.services.Checkout(ctx, &req, true); err != nil {
+			return 0, nil, nil, err
+		}
+		return transaction.ID, nil, transaction, nil
+	})
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(transaction)
 }
 
-func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
-	var req models.CheckoutRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
+// GetByID - GET /api/transactions/{id}
+func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "transaction")
+	if !ok {
+		return
+	}
+
+	transaction, err := h.services.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		problem.Error(w, r, err)
 		return
 	}
 
-	transaction, err := h.services.Checkout(req.Items, true)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(transaction)
+}
+
+// Refund dibungkus guard transactions:void di main.go, jadi approver sudah ada di context.
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "transaction")
+	if !ok {
+		return
+	}
+
+	var refundedBy *int
+	if approver, ok := middleware.ApproverFrom(r.Context()); ok && approver.UserID != 0 {
+		refundedBy = &approver.UserID
+	}
+	var transaction *models.Transaction
+	err := audited(h.audit, r, models.AuditRefund, "transaction", func(ctx context.Context) (int, any, any, error) {
+		before, err := h.services.GetByID(ctx, id)
+		if err != nil {
+			return id, nil, nil, err
+		}
+		if transaction, err = h.services.Refund(ctx, id, refundedBy); err != nil {
+			return id, nil, nil, err
+		}
+		return id, before, transaction, nil
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -0,0 +1,143 @@
+package handlers_test
+
+import (
+	"kasir-api/auth"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestCheckoutAndRefund(t *testing.T) {
+	store, api := newTestAPI(t)
+	tea := seedProduct(t, store, "Teh", 5000, 10)
+
+	rec := do(t, api, http.MethodPost, "/api/checkout", `{"items":[{"product_id":`+itoa(tea)+`,"quantity":3}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout: %d %s", rec.Code, rec.Body.String())
+	}
+	tx := decode[models.Transaction](t, rec)
+	if tx.TotalAmount.Amount != 15000 || tx.CashierID == nil || *tx.CashierID != 1 {
+		t.Errorf("transaction = %+v, want total 15000 by cashier 1", tx)
+	}
+	if got := stock(t, store, tea); got != 7 {
+		t.Errorf("stock = %g, want 7", got)
+	}
+
+	rec = do(t, api, http.MethodGet, "/api/transactions/"+itoa(tx.ID), "")
+	if got := decode[models.Transaction](t, rec); len(got.Details) != 1 || got.Details[0].ProductName != "Teh" {
+		t.Errorf("transaction details = %+v", got.Details)
+	}
+
+	// kasir tidak boleh refund sendiri; PIN supervisor salah juga 403
+	wantProblem(t, do(t, api, http.MethodPost, "/api/transactions/"+itoa(tx.ID)+"/refund", ""), http.StatusForbidden)
+	wantProblem(t, do(t, api, http.MethodPost, "/api/transactions/"+itoa(tx.ID)+"/refund", "",
+		middleware.SupervisorUsernameHeader, "spv", middleware.SupervisorPINHeader, "0000"), http.StatusForbidden)
+
+	rec = do(t, api, http.MethodPost, "/api/transactions/"+itoa(tx.ID)+"/refund", "",
+		middleware.SupervisorUsernameHeader, "spv", middleware.SupervisorPINHeader, supervisorPIN)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refund: %d %s", rec.Code, rec.Body.String())
+	}
+	if got := decode[models.Transaction](t, rec); got.Status != models.TransactionRefunded || got.RefundedBy == nil || *got.RefundedBy != 2 {
+		t.Errorf("refunded = %+v, want refunded by supervisor 2", got)
+	}
+	if got := stock(t, store, tea); got != 10 {
+		t.Errorf("stock after refund = %g, want 10", got)
+	}
+	wantProblem(t, do(t, api, http.MethodPost, "/api/transactions/"+itoa(tx.ID)+"/refund", "",
+		testRoleHeader, auth.RoleSupervisor), http.StatusConflict)
+}
+
+func TestCheckoutInsufficientStock(t *testing.T) {
+	store, api := newTestAPI(t)
+	tea := seedProduct(t, store, "Teh", 5000, 10)
+	coffee := seedProduct(t, store, "Kopi", 8000, 1)
+
+	rec := do(t, api, http.MethodPost, "/api/checkout",
+		`{"items":[{"product_id":`+itoa(tea)+`,"quantity":2},{"product_id":`+itoa(coffee)+`,"quantity":2}]}`)
+	p := wantProblem(t, rec, http.StatusConflict)
+	if p.Type != problem.TypeInsufficientStock || p.ProductID == nil || *p.ProductID != coffee ||
+		p.Available == nil || *p.Available != 1 || p.Requested == nil || *p.Requested != 2 {
+		t.Errorf("problem = %+v", p)
+	}
+	if got := stock(t, store, tea); got != 10 {
+		t.Errorf("tea stock = %g after rejected checkout, want 10", got)
+	}
+}
+
+// Override harga dan diskon besar butuh PIN supervisor; tanpa izin hasilnya 403, bukan 500.
+func TestCheckoutOverrideRequiresSupervisor(t *testing.T) {
+	store, api := newTestAPI(t)
+	tea := seedProduct(t, store, "Teh", 5000, 10)
+	body := `{"items":[{"product_id":` + itoa(tea) + `,"quantity":1,"price_override":4000}],"discount_percent":20}`
+
+	wantProblem(t, do(t, api, http.MethodPost, "/api/checkout", body), http.StatusForbidden)
+	wantProblem(t, do(t, api, http.MethodPost, "/api/checkout", body,
+		middleware.SupervisorUsernameHeader, "spv", middleware.SupervisorPINHeader, "9999"), http.StatusForbidden)
+	if got := stock(t, store, tea); got != 10 {
+		t.Errorf("stock = %g after rejected checkout, want 10", got)
+	}
+
+	rec := do(t, api, http.MethodPost, "/api/checkout", body,
+		middleware.SupervisorUsernameHeader, "spv", middleware.SupervisorPINHeader, supervisorPIN)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("checkout with override: %d %s", rec.Code, rec.Body.String())
+	}
+	tx := decode[models.Transaction](t, rec)
+	if tx.ApprovedBy == nil || *tx.ApprovedBy != 2 || tx.TotalAmount.Amount != 3200 {
+		t.Errorf("transaction = %+v, want approved by 2 and total 3200", tx)
+	}
+}
+
+// Field asing, tipe yang salah, dan aturan service dilaporkan bersama dalam satu 422.
+func TestCheckoutValidationErrors(t *testing.T) {
+	_, api := newTestAPI(t)
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"empty cart", `{"items":[]}`, []string{"items"}},
+		{"unknown field", `{"items":[{"product_id":1,"quantity":1,"qty":1}]}`, []string{"items[0].qty"}},
+		{
+			"decode and service errors merged",
+			`{"items":[{"product_id":1,"qty":1},{"product_id":"2","quantity":1}],"note":"x","discount_percent":150}`,
+			[]string{"items[0].qty", "items[1].product_id", "note", "items[0].quantity", "discount_percent"},
+		},
+		// product_id yang tipenya salah tidak dilaporkan ulang sebagai "is required"
+		{"no duplicate field", `{"items":[{"product_id":true,"quantity":1}]}`, []string{"items[0].product_id"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := wantProblem(t, do(t, api, http.MethodPost, "/api/checkout", tt.body), http.StatusUnprocessableEntity)
+			var got []string
+			for _, e := range p.Errors {
+				got = append(got, e.Field)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("fields = %q, want %q (%+v)", got, tt.want, p.Errors)
+			}
+		})
+	}
+}
+
+func TestReportToday(t *testing.T) {
+	store, api := newTestAPI(t)
+	tea := seedProduct(t, store, "Teh", 5000, 10)
+	if rec := do(t, api, http.MethodPost, "/api/checkout", `{"items":[{"product_id":`+itoa(tea)+`,"quantity":2}]}`); rec.Code != http.StatusOK {
+		t.Fatalf("checkout: %d %s", rec.Code, rec.Body.String())
+	}
+
+	wantProblem(t, do(t, api, http.MethodGet, "/api/report/today", ""), http.StatusForbidden)
+	rec := do(t, api, http.MethodGet, "/api/report/today", "", testRoleHeader, auth.RoleSupervisor)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("report: %d %s", rec.Code, rec.Body.String())
+	}
+	report := decode[models.TodayReport](t, rec)
+	if report.TotalTransactions != 1 || report.TotalRevenue.Amount != 10000 {
+		t.Errorf("report = %+v", report)
+	}
+}
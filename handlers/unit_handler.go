@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+)
+
+type UnitHandler struct {
+	service *services.UnitService
+}
+
+func NewUnitHandler(service *services.UnitService) *UnitHandler {
+	return &UnitHandler{service: service}
+}
+
+// GetUnitsByProduct - GET /api/product-units
+func (h *UnitHandler) GetUnitsByProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.Atoi(r.URL.Query().Get("product_id"))
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid product_id")
+		return
+	}
+
+	units, err := h.service.GetByProductID(r.Context(), productID)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(units)
+}
+
+// CreateUnit - POST /api/product-units
+func (h *UnitHandler) CreateUnit(w http.ResponseWriter, r *http.Request) {
+	var u models.ProductUnit
+	if !decodeJSON(w, r, r.Body, &u) {
+		return
+	}
+	if err := h.service.Create(r.Context(), &u); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(u)
+}
+
+// GetUnitByID - GET /api/product-units/{id}
+func (h *UnitHandler) GetUnitByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "product unit")
+	if !ok {
+		return
+	}
+
+	unit, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(unit)
+}
+
+// UpdateUnit - PUT /api/product-units/{id}
+func (h *UnitHandler) UpdateUnit(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "product unit")
+	if !ok {
+		return
+	}
+
+	body, ok := readBody(w, r, r.Body)
+	if !ok {
+		return
+	}
+
+	type UpdateReq struct {
+		Name        *string  `json:"name"`
+		Factor      *float64 `json:"factor"`
+		Barcode     *string  `json:"barcode"`
+		Sellable    *bool    `json:"sellable"`
+		Purchasable *bool    `json:"purchasable"`
+		// Price membedakan "missing" dari null (null → harga dasar × factor)
+		Price optional[money.Amount] `json:"price"`
+	}
+	var req UpdateReq
+	if !decodeJSON(w, r, bytes.NewReader(body), &req) {
+		return
+	}
+
+	old, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if req.Name != nil {
+		old.Name = *req.Name
+	}
+	if req.Factor != nil {
+		old.Factor = *req.Factor
+	}
+	if req.Barcode != nil {
+		old.Barcode = *req.Barcode
+	}
+	if req.Sellable != nil {
+		old.Sellable = *req.Sellable
+	}
+	if req.Purchasable != nil {
+		old.Purchasable = *req.Purchasable
+	}
+	if req.Price.Set {
+		old.Price = req.Price.Value
+	}
+
+	if err := h.service.Update(r.Context(), old); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(old)
+}
+
+// DeleteUnit - DELETE /api/product-units/{id}
+func (h *UnitHandler) DeleteUnit(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "product unit")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "Unit deleted successfully",
+	})
+}
+
+// HandleReceiveStock - POST /api/stock/receive (penerimaan barang dalam satuan beli)
+func (h *UnitHandler) HandleReceiveStock(w http.ResponseWriter, r *http.Request) {
+	var receipt models.StockReceipt
+	if !decodeJSON(w, r, r.Body, &receipt) {
+		return
+	}
+	if err := h.service.ReceiveStock(r.Context(), &receipt); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(receipt)
+}
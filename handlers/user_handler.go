@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+)
+
+type UserHandler struct {
+	service *services.UserService
+}
+
+func NewUserHandler(service *services.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// GetAllUsers - GET /api/users
+func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.service.GetAll(r.Context())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(users)
+}
+
+// CreateUser - POST /api/users
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	u := models.User{Active: true}
+	if !decodeJSON(w, r, r.Body, &u) {
+		return
+	}
+	if err := h.service.Create(r.Context(), &u); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(u)
+}
+
+// GetUserByID - GET /api/users/{id}
+func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "user")
+	if !ok {
+		return
+	}
+
+	user, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(user)
+}
+
+// UpdateUser - PUT /api/users/{id}
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "user")
+	if !ok {
+		return
+	}
+
+	type UpdateReq struct {
+		Username *string `json:"username"`
+		Name     *string `json:"name"`
+		Role     *string `json:"role"`
+		Password *string `json:"password"`
+		PIN      *string `json:"pin"`
+		Active   *bool   `json:"active"`
+	}
+	var req UpdateReq
+	if !decodeJSON(w, r, r.Body, &req) {
+		return
+	}
+
+	old, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if req.Username != nil {
+		old.Username = *req.Username
+	}
+	if req.Name != nil {
+		old.Name = *req.Name
+	}
+	if req.Role != nil {
+		old.Role = *req.Role
+	}
+	if req.Password != nil {
+		old.Password = *req.Password
+	}
+	if req.PIN != nil {
+		old.PIN = *req.PIN
+	}
+	if req.Active != nil {
+		old.Active = *req.Active
+	}
+
+	if err := h.service.Update(r.Context(), old); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(old)
+}
+
+// DeleteUser - DELETE /api/users/{id}
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "user")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "User deleted successfully",
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/problem"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+)
+
+type VariantHandler struct {
+	service *services.VariantService
+}
+
+func NewVariantHandler(service *services.VariantService) *VariantHandler {
+	return &VariantHandler{service: service}
+}
+
+// GetVariantsByProduct - GET /api/variants
+func (h *VariantHandler) GetVariantsByProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.Atoi(r.URL.Query().Get("product_id"))
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid product_id")
+		return
+	}
+
+	variants, err := h.service.GetByProductID(r.Context(), productID)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(variants)
+}
+
+// CreateVariant - POST /api/variants
+func (h *VariantHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	var v models.ProductVariant
+	if !decodeJSON(w, r, r.Body, &v) {
+		return
+	}
+	if err := h.service.Create(r.Context(), &v); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// GetVariantByID - GET /api/variants/{id}
+func (h *VariantHandler) GetVariantByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "variant")
+	if !ok {
+		return
+	}
+
+	variant, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(variant)
+}
+
+// UpdateVariant - PUT /api/variants/{id}
+func (h *VariantHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "variant")
+	if !ok {
+		return
+	}
+
+	type UpdateReq struct {
+		SKU     *string            `json:"sku"`
+		Name    *string            `json:"name"`
+		Options *map[string]string `json:"options"`
+		Price   *money.Amount      `json:"price"`
+		Stock   *float64           `json:"stock"`
+	}
+	var req UpdateReq
+	if !decodeJSON(w, r, r.Body, &req) {
+		return
+	}
+
+	old, err := h.service.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	if req.SKU != nil {
+		old.SKU = *req.SKU
+	}
+	if req.Options != nil {
+		old.Options = *req.Options
+		// nama lama mengikuti opsi lama, susun ulang kecuali dikirim eksplisit
+		old.Name = ""
+	}
+	if req.Name != nil {
+		old.Name = *req.Name
+	}
+	if req.Price != nil {
+		old.Price = *req.Price
+	}
+	if req.Stock != nil {
+		old.Stock = *req.Stock
+	}
+
+	if err := h.service.Update(r.Context(), old); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(old)
+}
+
+// DeleteVariant - DELETE /api/variants/{id}
+func (h *VariantHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "variant")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"message": "Variant deleted successfully",
+	})
+}
@@ -1,23 +1,79 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"kasir-api/database"
+	"kasir-api/auth"
 	"kasir-api/handlers"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/money"
 	"kasir-api/repositories"
+	"kasir-api/router"
 	"kasir-api/services"
-	"log"
+	"kasir-api/tenant"
+	"log/slog"
+	"math"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Port   string `mapstructure:"PORT"`
-	DBConn string `mapstructure:"DB_CONN"`
+	Port         string `mapstructure:"PORT"`
+	DBConn       string `mapstructure:"DB_CONN"`
+	Currency     string `mapstructure:"CURRENCY"`
+	CashRounding int64  `mapstructure:"CASH_ROUNDING"`
+	RoundingMode string `mapstructure:"ROUNDING_MODE"`
+	// TaxPercent adalah pajak (%) atas total setelah diskon, mis. 11 untuk PPN; 0 = tanpa pajak
+	TaxPercent float64 `mapstructure:"TAX_PERCENT"`
+
+	// MaxCashierDiscount adalah diskon manual (%) terbesar tanpa PIN supervisor
+	MaxCashierDiscount float64 `mapstructure:"MAX_CASHIER_DISCOUNT"`
+
+	JWTSecret       string        `mapstructure:"JWT_SECRET"`
+	AccessTokenTTL  time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+	AdminUsername   string        `mapstructure:"ADMIN_USERNAME"`
+	AdminPassword   string        `mapstructure:"ADMIN_PASSWORD"`
+
+	// AutoMigrate menjalankan migrasi skema saat server start (bawaan true); jika false
+	// jalankan manual dengan "kasir-api migrate up"
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
+
+	// Batas waktu operasi database per jenis (lihat repositories.Timeouts); kosong = bawaan
+	DBQueryTimeout  time.Duration `mapstructure:"DB_QUERY_TIMEOUT"`
+	DBWriteTimeout  time.Duration `mapstructure:"DB_WRITE_TIMEOUT"`
+	DBReportTimeout time.Duration `mapstructure:"DB_REPORT_TIMEOUT"`
+
+	// PriceSchedulerInterval adalah seberapa sering harga terjadwal diperiksa
+	PriceSchedulerInterval time.Duration `mapstructure:"PRICE_SCHEDULER_INTERVAL"`
+
+	// MultiTenant: tenant ditentukan dari token, kunci, header X-Tenant, atau subdomain
+	// TenantBaseDomain (mis. kasir.example -> tokoabc.kasir.example). Jika false semua
+	// request memakai tenant bawaan.
+	MultiTenant      bool   `mapstructure:"MULTI_TENANT"`
+	TenantBaseDomain string `mapstructure:"TENANT_BASE_DOMAIN"`
+	// PlatformToken membuka /api/tenants untuk operator platform; kosong = dimatikan
+	PlatformToken string `mapstructure:"PLATFORM_TOKEN"`
+
+	// Batas server HTTP (lihat server.go); kosong = bawaan
+	HTTPReadTimeout  time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout  time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	// ShutdownTimeout adalah lama menunggu request yang sedang berjalan saat SIGTERM
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay: /readyz sudah gagal tetapi listener belum ditutup (bawaan 0)
+	ShutdownDelay time.Duration `mapstructure:"SHUTDOWN_DELAY"`
+	// MaxBodyBytes membatasi ukuran body request (JSON)
+	MaxBodyBytes int64 `mapstructure:"MAX_BODY_BYTES"`
+
+	// LogFormat "text" (bawaan) atau "json" untuk agregator log; LogLevel debug|info|warn|error
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+	LogLevel  string `mapstructure:"LOG_LEVEL"`
 }
 
 func main() {
@@ -30,86 +86,203 @@ func main() {
 	}
 
 	config := Config{
-		Port:   viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+		Port:         viper.GetString("PORT"),
+		DBConn:       viper.GetString("DB_CONN"),
+		Currency:     viper.GetString("CURRENCY"),
+		CashRounding: viper.GetInt64("CASH_ROUNDING"),
+		RoundingMode: viper.GetString("ROUNDING_MODE"),
+		TaxPercent:   viper.GetFloat64("TAX_PERCENT"),
+
+		MaxCashierDiscount: viper.GetFloat64("MAX_CASHIER_DISCOUNT"),
+
+		JWTSecret:       viper.GetString("JWT_SECRET"),
+		AccessTokenTTL:  viper.GetDuration("ACCESS_TOKEN_TTL"),
+		RefreshTokenTTL: viper.GetDuration("REFRESH_TOKEN_TTL"),
+		AdminUsername:   viper.GetString("ADMIN_USERNAME"),
+		AdminPassword:   viper.GetString("ADMIN_PASSWORD"),
+
+		AutoMigrate: viper.GetBool("AUTO_MIGRATE"),
+
+		DBQueryTimeout:  viper.GetDuration("DB_QUERY_TIMEOUT"),
+		DBWriteTimeout:  viper.GetDuration("DB_WRITE_TIMEOUT"),
+		DBReportTimeout: viper.GetDuration("DB_REPORT_TIMEOUT"),
+
+		PriceSchedulerInterval: viper.GetDuration("PRICE_SCHEDULER_INTERVAL"),
+
+		MultiTenant:      viper.GetBool("MULTI_TENANT"),
+		TenantBaseDomain: viper.GetString("TENANT_BASE_DOMAIN"),
+		PlatformToken:    viper.GetString("PLATFORM_TOKEN"),
+
+		HTTPReadTimeout:  viper.GetDuration("HTTP_READ_TIMEOUT"),
+		HTTPWriteTimeout: viper.GetDuration("HTTP_WRITE_TIMEOUT"),
+		HTTPIdleTimeout:  viper.GetDuration("HTTP_IDLE_TIMEOUT"),
+		ShutdownTimeout:  viper.GetDuration("SHUTDOWN_TIMEOUT"),
+		ShutdownDelay:    viper.GetDuration("SHUTDOWN_DELAY"),
+		MaxBodyBytes:     viper.GetInt64("MAX_BODY_BYTES"),
+
+		LogFormat: viper.GetString("LOG_FORMAT"),
+		LogLevel:  viper.GetString("LOG_LEVEL"),
 	}
+	setupLogger(config)
 
 	if config.Port == "" {
 		config.Port = "8080"
 	}
 	if config.DBConn == "" {
-		log.Fatal("DB_CONN is empty. Ensure .env has DB_CONN=<connection string>")
+		fatal("DB_CONN is empty. Ensure .env has DB_CONN=<connection string>")
+	}
+	if !viper.IsSet("MAX_CASHIER_DISCOUNT") {
+		config.MaxCashierDiscount = 10
+	}
+	if config.AccessTokenTTL <= 0 {
+		config.AccessTokenTTL = 15 * time.Minute
+	}
+	if config.RefreshTokenTTL <= 0 {
+		config.RefreshTokenTTL = 30 * 24 * time.Hour
+	}
+	if !viper.IsSet("AUTO_MIGRATE") {
+		config.AutoMigrate = true
+	}
+	if config.PriceSchedulerInterval <= 0 {
+		config.PriceSchedulerInterval = time.Minute
+	}
+	if config.HTTPReadTimeout <= 0 {
+		config.HTTPReadTimeout = defaultReadTimeout
+	}
+	if config.HTTPWriteTimeout <= 0 {
+		config.HTTPWriteTimeout = defaultWriteTimeout
+	}
+	if config.HTTPIdleTimeout <= 0 {
+		config.HTTPIdleTimeout = defaultIdleTimeout
+	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = defaultShutdownTimeout
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultMaxBodyBytes
 	}
 
-	// Setup DB (pgxpool)
-	pool, err := database.InitDB(config.DBConn)
+	// Aturan uang: mata uang, pembulatan total tunai (mis. CASH_ROUNDING=100), mode pembulatan, pajak
+	roundingMode, err := money.ParseRoundingMode(config.RoundingMode)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
-	}
-	defer pool.Close()
-
-	// Injeksi pgxpool ke repository (pastikan constructor repo menerima *pgxpool.Pool)
-	productRepo := repositories.NewProductRepository(pool)
-	productService := services.NewProductService(productRepo)
-	productHandler := handlers.NewProductHandler(productService)
-	// Category
-	categoryRepo := repositories.NewCategoryRepository(pool)
-	categoryService := services.NewCategoryService(categoryRepo)
-	categoryHandler := handlers.NewCategoryHandler(categoryService)
-	// Transaction
-	transactionRepo := repositories.NewTransactionRepository(pool)
-	transactionService := services.NewTransactionService(transactionRepo)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
-	// Report
-	reportRepo := repositories.NewReportRepository(pool)
-	reportService := services.NewReportService(reportRepo)
-	reportHandler := handlers.NewReportHandler(reportService)
-
-	// Setup routes
-	http.HandleFunc("/api/products", productHandler.HandleProducts)
-	http.HandleFunc("/api/product/", productHandler.HandleProductByID)
-	http.HandleFunc("/api/categories", categoryHandler.HandleCategories)
-	http.HandleFunc("/api/category/", categoryHandler.HandleCategoryByID)
-	//post /api/checkout
-	http.HandleFunc("/api/checkout", transactionHandler.HandleCheckout)
-	http.HandleFunc("/api/report/today", reportHandler.HandleReportToday)
-
-	//localhost:8080/api
-	http.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(map[string]any{
-			"status":  "Success",
-			"message": "Welcome to the Cashier API",
-			"endpoints": []string{
-				"GET /api/products",
-				"POST /api/products",
-				"GET /api/product/{id}",
-				"PUT /api/product/{id}",
-				"DELETE /api/product/{id}",
-				"GET /api/products?name={name}",
-
-				"GET /api/categories",
-				"POST /api/categories",
-				"GET /api/category/{id}",
-				"PUT /api/category/{id}",
-				"DELETE /api/category/{id}",
-
-				"POST /api/checkout",
-				"GET /api/report/today",
-				"Comming Soon GET /api/report?date={date}",
-			},
-		}); err != nil {
-			http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+		fatal("invalid ROUNDING_MODE", "err", err)
+	}
+	if config.TaxPercent < 0 || config.TaxPercent > 100 {
+		fatal("TAX_PERCENT must be between 0 and 100", "tax_percent", config.TaxPercent)
+	}
+	money.Configure(money.Config{
+		Currency:     config.Currency,
+		CashRounding: money.Amount(config.CashRounding),
+		Mode:         roundingMode,
+		TaxRate:      int64(math.Round(config.TaxPercent * 100)),
+	})
+	repositories.ConfigureTimeouts(repositories.Timeouts{
+		Query:  config.DBQueryTimeout,
+		Write:  config.DBWriteTimeout,
+		Report: config.DBReportTimeout,
 	})
 
-	// Bind ke semua interface (IPv4/IPv6)
-	addr := ":" + config.Port
-	fmt.Println("Server running in", addr)
-
-	err = http.ListenAndServe(addr, nil)
+	// Setup DB: Postgres, atau SQLite jika DB_CONN=sqlite://path (lihat backend.go); ditutup
+	// saat main selesai, setelah semua request dan scheduler berhenti
+	db, err := openBackend(config.DBConn)
 	if err != nil {
-		fmt.Println("Error starting server:", err)
+		fatal("failed to initialize database", "err", err)
+	}
+	defer db.close()
+
+	// Subcommand: kasir-api migrate up|down [steps]|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db.migrator, os.Args[2:]); err != nil {
+			fatal("migrate failed", "err", err)
+		}
+		return
+	}
+	if config.AutoMigrate {
+		if _, err := db.migrator.up(); err != nil {
+			fatal("failed to migrate database", "err", err)
+		}
+	}
+
+	// subcommand migrate tidak butuh secret; hanya server yang menerbitkan token
+	if config.JWTSecret == "" {
+		fatal("JWT_SECRET is empty. Ensure .env has JWT_SECRET=<random secret>")
 	}
+
+	// Admin pertama untuk tenant bawaan; tenant lain mendapat admin saat dibuat lewat /api/tenants
+	defaultUsers := services.NewUserService(db.stores(models.DefaultTenantID).Users)
+	if created, err := defaultUsers.EnsureAdmin(context.Background(), config.AdminUsername, config.AdminPassword); err != nil {
+		fatal("failed to seed admin user", "err", err)
+	} else if created {
+		slog.Info("created initial user", "username", config.AdminUsername)
+	}
+
+	// Harga terjadwal diterapkan untuk semua tenant sekaligus (ApplyDue lintas tenant),
+	// jadi scheduler cukup satu selama server hidup
+	scheduler := services.NewPriceHistoryService(db.stores(models.DefaultTenantID).PriceHistory, nil)
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		scheduler.RunScheduler(schedulerCtx, config.PriceSchedulerInterval)
+	}()
+
+	issuer := auth.NewIssuer(config.JWTSecret, config.AccessTokenTTL)
+	limiter := middleware.NewRateLimiter()
+	tenantHandler := handlers.NewTenantHandler(services.NewTenantService(db.tenants))
+
+	// Setiap tenant punya rangkaian handler sendiri; mode satu tenant langsung memakai tenant bawaan
+	var app http.Handler
+	if config.MultiTenant {
+		resolver := tenant.NewResolver(db.tenants, issuer, config.TenantBaseDomain)
+		app = tenant.NewRouter(resolver, func(tenantID int) http.Handler {
+			return newTenantHandler(db.stores(tenantID), config, issuer, limiter, tenantID)
+		})
+	} else {
+		app = newTenantHandler(db.stores(models.DefaultTenantID), config, issuer, limiter, models.DefaultTenantID)
+	}
+
+	health := handlers.NewHealthHandler(buildInfo(),
+		handlers.ReadinessCheck{Name: "database", Check: db.ping},
+		handlers.ReadinessCheck{Name: "migrations", Check: func(ctx context.Context) error {
+			return migrationsApplied(db.pending(ctx))
+		}},
+	)
+
+	// Endpoint platform dan health check berada di luar tenant mana pun
+	rt := router.New()
+	handleHealth(rt, health)
+	rt.HandleFunc("GET /api/tenants", middleware.RequirePlatformToken(config.PlatformToken, tenantHandler.GetAllTenants))
+	rt.HandleFunc("POST /api/tenants", middleware.RequirePlatformToken(config.PlatformToken, tenantHandler.CreateTenant))
+	rt.Handle("/", app)
+
+	if err := listenAndServe(newServer(config, rt), config, health); err != nil {
+		slog.Error("server stopped", "err", err)
+	}
+	// request sudah selesai; hentikan scheduler sebelum database ditutup (defer di atas)
+	stopScheduler()
+	<-schedulerDone
+}
+
+// setupLogger memasang logger slog bawaan; log dari paket log standar ikut diteruskan ke sini.
+func setupLogger(config Config) {
+	var level slog.Level
+	if config.LogLevel != "" {
+		if err := level.UnmarshalText([]byte(config.LogLevel)); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid LOG_LEVEL %q, using info\n", config.LogLevel)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	if strings.EqualFold(config.LogFormat, "json") {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		h = slog.NewTextHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(middleware.NewLogHandler(h)))
+}
+
+// fatal mencatat error start-up lalu keluar, pengganti log.Fatal untuk logger terstruktur.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
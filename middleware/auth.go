@@ -0,0 +1,110 @@
+// Package middleware berisi pembungkus http.Handler yang dipakai semua route.
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"net/http"
+	"strings"
+)
+
+type contextKey int
+
+const (
+	principalKey contextKey = iota
+	approverKey
+	requestIDKey
+	accessLogKey
+)
+
+// APIKeyHeader membawa kunci API untuk klien mesin, sebagai ganti access token.
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator memeriksa access token atau kunci API dan mengembalikan identitas pemiliknya.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*models.Principal, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.Principal, error)
+}
+
+// Authenticate menolak request tanpa access token atau kunci API yang valid, kecuali
+// path yang terdaftar di public (dicocokkan persis) dan preflight OPTIONS.
+func Authenticate(a Authenticator, public ...string) func(http.Handler) http.Handler {
+	open := map[string]bool{}
+	for _, p := range public {
+		open[p] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if open[r.URL.Path] || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if key := r.Header.Get(APIKeyHeader); key != "" {
+				principal, err := a.AuthenticateAPIKey(r.Context(), key)
+				if err != nil {
+					problem.Write(w, r, http.StatusUnauthorized, "Invalid API key")
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+				return
+			}
+
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				problem.Write(w, r, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+			principal, err := a.Authenticate(r.Context(), token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				problem.Write(w, r, http.StatusUnauthorized, "Invalid or expired token")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func WithPrincipal(ctx context.Context, p *models.Principal) context.Context {
+	if e, ok := ctx.Value(accessLogKey).(*accessEntry); ok {
+		e.user, e.tenantID = p.Username, p.TenantID
+	}
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// PrincipalFrom mengembalikan identitas yang sudah terautentikasi pada request.
+func PrincipalFrom(ctx context.Context) (*models.Principal, bool) {
+	p, ok := ctx.Value(principalKey).(*models.Principal)
+	return p, ok
+}
+
+func withApprover(ctx context.Context, p *models.Principal) context.Context {
+	return context.WithValue(ctx, approverKey, p)
+}
+
+// ApproverFrom mengembalikan siapa yang mengizinkan request ini; berbeda dari
+// PrincipalFrom jika aksi disetujui supervisor lewat override PIN.
+func ApproverFrom(ctx context.Context) (*models.Principal, bool) {
+	p, ok := ctx.Value(approverKey).(*models.Principal)
+	return p, ok
+}
+
+// PlatformTokenHeader membawa token operator platform untuk mengelola tenant.
+const PlatformTokenHeader = "X-Platform-Token"
+
+// RequirePlatformToken melindungi endpoint tingkat platform (di luar tenant mana pun).
+// Token kosong berarti endpoint dimatikan.
+func RequirePlatformToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got := r.Header.Get(PlatformTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			problem.Write(w, r, http.StatusUnauthorized, "Invalid platform token")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"kasir-api/problem"
+	"net/http"
+	"strconv"
+)
+
+// MaxBodySize menolak body request yang lebih besar dari limit byte. Content-Length yang
+// sudah kelewat batas langsung dijawab 413; body tanpa panjang (chunked) dipotong dengan
+// http.MaxBytesReader sehingga decoder JSON gagal saat batas terlampaui.
+func MaxBodySize(limit int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				problem.Write(w, r, http.StatusRequestEntityTooLarge, "request body must not exceed "+strconv.FormatInt(limit, 10)+" bytes")
+				return
+			}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import "net/http"
+
+// Middleware membungkus handler, mis. Authenticate(...) atau AccessLog(logger).
+type Middleware func(http.Handler) http.Handler
+
+// Chain memasang middleware sesuai urutan tulisan: yang pertama menjadi lapisan terluar
+// dan melihat request paling awal.
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"kasir-api/problem"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// AccessLog mencatat satu baris per request: method, path, status, latency, bytes, dan
+// user/tenant jika request terautentikasi. Respons 5xx dicatat di level error. Request ke
+// quietPaths (mis. probe /healthz yang datang tiap beberapa detik) selalu di level debug.
+func AccessLog(logger *slog.Logger, quietPaths ...string) Middleware {
+	quiet := map[string]bool{}
+	for _, p := range quietPaths {
+		quiet[p] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w}
+			entry := &accessEntry{}
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), accessLogKey, entry)))
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", rec.status()),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int64("bytes", rec.bytes),
+			}
+			if entry.user != "" {
+				attrs = append(attrs, slog.String("user", entry.user), slog.Int("tenant_id", entry.tenantID))
+			}
+			level := slog.LevelInfo
+			switch {
+			case quiet[r.URL.Path]:
+				level = slog.LevelDebug
+			case rec.status() >= http.StatusInternalServerError:
+				level = slog.LevelError
+			}
+			logger.LogAttrs(r.Context(), level, "request", attrs...)
+		})
+	}
+}
+
+// accessEntry diisi WithPrincipal: Authenticate berjalan di lapisan dalam dengan context
+// baru yang tidak terlihat oleh AccessLog, jadi identitasnya dititipkan lewat pointer ini.
+type accessEntry struct {
+	user     string
+	tenantID int
+}
+
+// Recover menangkap panic di handler: stack trace dicatat dan klien menerima 500 problem
+// JSON jika respons belum mulai dikirim. http.ErrAbortHandler diteruskan apa adanya.
+func Recover(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &responseRecorder{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				logger.ErrorContext(r.Context(), "panic serving request",
+					"method", r.Method, "path", r.URL.Path, "panic", v, "stack", string(debug.Stack()))
+				if rec.code == 0 {
+					problem.Write(rec, r, http.StatusInternalServerError, "internal server error")
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// responseRecorder mencatat status dan jumlah byte yang dikirim handler.
+type responseRecorder struct {
+	http.ResponseWriter
+	code  int
+	bytes int64
+}
+
+func (w *responseRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap agar http.ResponseController tetap bisa menjangkau writer aslinya.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseRecorder) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+// LogHandler menambahkan request_id dari context ke setiap log yang ditulis dengan
+// varian *Context (slog.InfoContext, dst.), sehingga log handler dan service bisa
+// dikaitkan dengan baris access log-nya.
+type LogHandler struct {
+	slog.Handler
+}
+
+func NewLogHandler(h slog.Handler) *LogHandler {
+	return &LogHandler{Handler: h}
+}
+
+func (h *LogHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if id := RequestIDFrom(ctx); id != "" {
+		rec.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, rec)
+}
+
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithGroup(name)}
+}
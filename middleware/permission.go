@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/problem"
+	"net/http"
+)
+
+// Header untuk persetujuan supervisor atas aksi yang tidak boleh dilakukan kasir sendiri.
+const (
+	SupervisorUsernameHeader = "X-Supervisor-Username"
+	SupervisorPINHeader      = "X-Supervisor-PIN"
+)
+
+var ErrForbidden = models.Forbidden("permission denied")
+
+// OverrideVerifier memeriksa username + PIN supervisor.
+type OverrideVerifier interface {
+	VerifyOverride(ctx context.Context, username, pin string) (*models.Principal, error)
+}
+
+// Guard memeriksa izin peran per route dan menangani override PIN supervisor.
+type Guard struct {
+	verifier OverrideVerifier
+}
+
+func NewGuard(verifier OverrideVerifier) *Guard {
+	return &Guard{verifier: verifier}
+}
+
+// Authorize mengembalikan siapa yang mengizinkan aksi p: user yang login jika perannya
+// cukup, atau supervisor dari header override jika p boleh di-override.
+func (g *Guard) Authorize(r *http.Request, p auth.Permission) (*models.Principal, error) {
+	principal, ok := PrincipalFrom(r.Context())
+	if !ok {
+		return nil, ErrForbidden
+	}
+	if auth.Allowed(principal.Role, principal.Scopes, p) {
+		return principal, nil
+	}
+
+	// override hanya untuk kasir yang login, bukan untuk API key
+	username := r.Header.Get(SupervisorUsernameHeader)
+	if !auth.Overridable(p) || username == "" || principal.APIKeyID != nil {
+		return nil, ErrForbidden
+	}
+	approver, err := g.verifier.VerifyOverride(r.Context(), username, r.Header.Get(SupervisorPINHeader))
+	if err != nil {
+		return nil, err
+	}
+	if !auth.Can(approver.Role, p) {
+		return nil, ErrForbidden
+	}
+	return approver, nil
+}
+
+// Require membungkus handler sehingga hanya bisa dipanggil dengan izin p.
+func (g *Guard) Require(p auth.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		approver, err := g.Authorize(r, p)
+		if errors.Is(err, models.ErrForbidden) {
+			problem.Write(w, r, http.StatusForbidden, "Forbidden: "+err.Error()+" ("+string(p)+")")
+			return
+		}
+		if err != nil {
+			// PIN supervisor terkunci (429) atau gagal membaca data user
+			problem.Error(w, r, err)
+			return
+		}
+		next(w, r.WithContext(withApprover(r.Context(), approver)))
+	}
+}
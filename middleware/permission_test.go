@@ -0,0 +1,163 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"kasir-api/auth"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	supervisorPIN = "1234"
+	maxAttempts   = 3
+)
+
+// lockingVerifier meniru AuthService.VerifyOverride: PIN salah ditolak, dan setelah
+// maxAttempts kali salah PIN terkunci, walaupun PIN berikutnya benar.
+type lockingVerifier struct {
+	failures map[string]int
+	calls    int
+}
+
+func (v *lockingVerifier) VerifyOverride(ctx context.Context, username, pin string) (*models.Principal, error) {
+	v.calls++
+	roles := map[string]string{"spv": auth.RoleSupervisor, "kasir2": auth.RoleCashier}
+	role, ok := roles[username]
+	if !ok {
+		return nil, services.ErrInvalidOverride
+	}
+	if v.failures[username] >= maxAttempts {
+		return nil, services.ErrPINLocked
+	}
+	if pin != supervisorPIN {
+		v.failures[username]++
+		return nil, services.ErrInvalidOverride
+	}
+	v.failures[username] = 0
+	return &models.Principal{TenantID: 1, UserID: 2, Username: username, Role: role}, nil
+}
+
+func newRequest(p *models.Principal, header ...string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/transactions/1/refund", nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	if p != nil {
+		r = r.WithContext(middleware.WithPrincipal(r.Context(), p))
+	}
+	return r
+}
+
+func override(username, pin string) []string {
+	return []string{middleware.SupervisorUsernameHeader, username, middleware.SupervisorPINHeader, pin}
+}
+
+var (
+	cashier    = &models.Principal{TenantID: 1, UserID: 1, Username: "kasir", Role: auth.RoleCashier}
+	supervisor = &models.Principal{TenantID: 1, UserID: 3, Username: "spv2", Role: auth.RoleSupervisor}
+	apiKeyID   = 9
+	apiKey     = &models.Principal{TenantID: 1, Username: "integrasi", APIKeyID: &apiKeyID, Scopes: []string{"transactions:create"}}
+)
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *http.Request
+		p        auth.Permission
+		approver int   // UserID yang diharapkan memberi izin
+		err      error // nil jika diizinkan
+	}{
+		{"own role", newRequest(supervisor), auth.TransactionsVoid, 3, nil},
+		{"own role ignores override headers", newRequest(supervisor, override("spv", "0000")...), auth.TransactionsVoid, 3, nil},
+		{"no principal", newRequest(nil), auth.TransactionsRead, 0, models.ErrForbidden},
+		{"cashier without override", newRequest(cashier), auth.TransactionsVoid, 0, models.ErrForbidden},
+		{"override success", newRequest(cashier, override("spv", supervisorPIN)...), auth.TransactionsVoid, 2, nil},
+		{"override bad PIN", newRequest(cashier, override("spv", "0000")...), auth.PricesOverride, 0, models.ErrForbidden},
+		{"override unknown supervisor", newRequest(cashier, override("nobody", supervisorPIN)...), auth.DiscountsLarge, 0, models.ErrForbidden},
+		{"approver lacks permission", newRequest(cashier, override("kasir2", supervisorPIN)...), auth.TransactionsVoid, 0, models.ErrForbidden},
+		{"not overridable", newRequest(cashier, override("spv", supervisorPIN)...), auth.ProductsWrite, 0, models.ErrForbidden},
+		{"api key cannot borrow a PIN", newRequest(apiKey, override("spv", supervisorPIN)...), auth.TransactionsVoid, 0, models.ErrForbidden},
+		{"api key scope", newRequest(apiKey), auth.TransactionsCreate, 9, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := middleware.NewGuard(&lockingVerifier{failures: map[string]int{}})
+			approver, err := guard.Authorize(tt.req, tt.p)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("err = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			id := approver.UserID
+			if approver.APIKeyID != nil {
+				id = *approver.APIKeyID
+			}
+			if id != tt.approver {
+				t.Errorf("approver = %+v, want %d", approver, tt.approver)
+			}
+		})
+	}
+}
+
+// Require memetakan PIN salah ke 403 dan PIN terkunci ke 429; handler hanya jalan setelah
+// persetujuan, dengan supervisor sebagai approver.
+func TestRequireOverrideLockout(t *testing.T) {
+	verifier := &lockingVerifier{failures: map[string]int{}}
+	guard := middleware.NewGuard(verifier)
+	var approvedBy int
+	h := guard.Require(auth.TransactionsVoid, func(w http.ResponseWriter, r *http.Request) {
+		if a, ok := middleware.ApproverFrom(r.Context()); ok {
+			approvedBy = a.UserID
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	serve := func(pin string) int {
+		rec := httptest.NewRecorder()
+		h(rec, newRequest(cashier, override("spv", pin)...))
+		return rec.Code
+	}
+
+	if code := serve(supervisorPIN); code != http.StatusNoContent || approvedBy != 2 {
+		t.Fatalf("override = %d approved by %d, want 204 by supervisor 2", code, approvedBy)
+	}
+	for i := range maxAttempts {
+		if code := serve("0000"); code != http.StatusForbidden {
+			t.Fatalf("wrong PIN #%d = %d, want 403", i+1, code)
+		}
+	}
+	approvedBy = 0
+	if code := serve(supervisorPIN); code != http.StatusTooManyRequests || approvedBy != 0 {
+		t.Fatalf("correct PIN while locked = %d (approved by %d), want 429 and handler not called", code, approvedBy)
+	}
+
+	// PIN supervisor tidak diperiksa sama sekali jika kasir sendiri tidak butuh override
+	calls := verifier.calls
+	rec := httptest.NewRecorder()
+	guard.Require(auth.TransactionsCreate, func(w http.ResponseWriter, r *http.Request) {})(rec, newRequest(cashier, override("spv", "0000")...))
+	if rec.Code != http.StatusOK || verifier.calls != calls {
+		t.Errorf("cashier checkout = %d with %d verifier calls, want 200 without verifying", rec.Code, verifier.calls-calls)
+	}
+}
+
+// OPTIONS tidak boleh melewati pemeriksaan izin; preflight dijawab router, bukan handler.
+func TestRequireChecksOptions(t *testing.T) {
+	guard := middleware.NewGuard(&lockingVerifier{failures: map[string]int{}})
+	called := false
+	h := guard.Require(auth.TransactionsVoid, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodOptions, "/api/transactions/1/refund", nil))
+	if called || rec.Code != http.StatusForbidden {
+		t.Errorf("OPTIONS without principal = %d (handler called: %v), want 403", rec.Code, called)
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"kasir-api/problem"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter membatasi jumlah request per menit untuk tiap API key (jendela tetap per menit,
+// disimpan di memori proses). Request dari user yang login tidak dibatasi.
+type RateLimiter struct {
+	mu      sync.Mutex
+	windows map[int]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{windows: map[int]*rateWindow{}}
+}
+
+// allow mencatat satu request untuk key dan melaporkan apakah masih dalam batas,
+// beserta sisa waktu sampai jendela berikutnya.
+func (l *RateLimiter) allow(key, limit int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	win, ok := l.windows[key]
+	if !ok || now.Sub(win.start) >= time.Minute {
+		win = &rateWindow{start: now.Truncate(time.Minute)}
+		l.windows[key] = win
+	}
+	if win.count >= limit {
+		return false, win.start.Add(time.Minute).Sub(now)
+	}
+	win.count++
+	return true, 0
+}
+
+// Limit harus dipasang di dalam Authenticate agar identitas API key sudah ada di context.
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFrom(r.Context())
+		if !ok || principal.APIKeyID == nil || principal.RateLimit <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		allowed, retryAfter := l.allow(*principal.APIKeyID, principal.RateLimit, time.Now())
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			problem.Write(w, r, http.StatusTooManyRequests, "Rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir-api/database"
+	"kasir-api/repositories/sqlite"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const migrateUsage = "usage: kasir-api migrate up | down [steps] | status"
+
+// migrator menyatukan runner migrasi Postgres dan SQLite untuk subcommand "migrate".
+type migrator struct {
+	up     func() ([]database.Migration, error)
+	down   func(steps int) ([]database.Migration, error)
+	status func() ([]database.MigrationState, error)
+}
+
+func postgresMigrator(pool *pgxpool.Pool) migrator {
+	return migrator{
+		up:     func() ([]database.Migration, error) { return database.MigrateUp(pool) },
+		down:   func(steps int) ([]database.Migration, error) { return database.MigrateDown(pool, steps) },
+		status: func() ([]database.MigrationState, error) { return database.MigrationStatus(pool) },
+	}
+}
+
+func sqliteMigrator(db *sql.DB) migrator {
+	return migrator{
+		up:     func() ([]database.Migration, error) { return sqlite.MigrateUp(db) },
+		down:   func(steps int) ([]database.Migration, error) { return sqlite.MigrateDown(db, steps) },
+		status: func() ([]database.MigrationState, error) { return sqlite.MigrationStatus(db) },
+	}
+}
+
+// runMigrate menjalankan subcommand "migrate": up menerapkan semua migrasi yang belum
+// jalan, down membatalkan steps migrasi terakhir (bawaan 1), status menampilkan daftarnya.
+func runMigrate(m migrator, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := m.up()
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return nil
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("steps must be a positive number")
+			}
+			steps = n
+		}
+		reverted, err := m.down(steps)
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No migrations to revert")
+		}
+		return nil
+	case "status":
+		states, err := m.status()
+		if err != nil {
+			return err
+		}
+		for _, s := range states {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-28s %s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// APIKey adalah kunci untuk klien mesin (sinkronisasi akuntansi, plugin toko online).
+// Kunci utuh hanya dikembalikan saat dibuat atau dirotasi; yang disimpan hanya hash-nya.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // awal kunci, untuk mengenali kunci tanpa membukanya
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rate_limit"` // request per menit
+	Key        string     `json:"key,omitempty"`
+	KeyHash    string     `json:"-"`
+	CreatedBy  *int       `json:"created_by,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Aksi yang dicatat di audit log. Void transaksi dicatat sebagai refund.
+const (
+	AuditCreate   = "create"
+	AuditUpdate   = "update"
+	AuditDelete   = "delete"
+	AuditCheckout = "checkout"
+	AuditRefund   = "refund"
+)
+
+// AuditLog adalah satu catatan perubahan data. Log hanya bisa ditambah, tidak bisa diubah
+// atau dihapus. Diff berisi field yang berubah: {"field": {"before": .., "after": ..}}.
+type AuditLog struct {
+	ID            int             `json:"id"`
+	ActorUserID   *int            `json:"actor_user_id,omitempty"`
+	ActorAPIKeyID *int            `json:"actor_api_key_id,omitempty"`
+	ActorName     string          `json:"actor_name"`
+	ApprovedBy    *int            `json:"approved_by,omitempty"` // supervisor yang menyetujui lewat override PIN
+	Action        string          `json:"action"`
+	Entity        string          `json:"entity"`
+	EntityID      *int            `json:"entity_id,omitempty"`
+	Before        json.RawMessage `json:"before,omitempty"`
+	After         json.RawMessage `json:"after,omitempty"`
+	Diff          json.RawMessage `json:"diff,omitempty"`
+	SourceIP      string          `json:"source_ip"`
+	RequestID     string          `json:"request_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AuditFilter menyaring GET /api/audit-logs; field kosong berarti tidak disaring.
+type AuditFilter struct {
+	Entity      string
+	EntityID    *int
+	Action      string
+	ActorUserID *int
+	RequestID   string
+	From        *time.Time
+	To          *time.Time
+	Limit       int
+}
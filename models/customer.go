@@ -0,0 +1,27 @@
+package models
+
+import (
+	"kasir-api/money"
+	"time"
+)
+
+type Customer struct {
+	ID          int          `json:"id"`
+	Name        string       `json:"name"`
+	Phone       string       `json:"phone,omitempty"`
+	Email       string       `json:"email,omitempty"`
+	Notes       string       `json:"notes,omitempty"`
+	PriceListID *int         `json:"price_list_id,omitempty"` // daftar harga default (mis. member/grosir)
+	CreditLimit money.Amount `json:"credit_limit"`            // batas kasbon; 0 = tidak boleh kasbon
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// CustomerSummary merangkum nilai belanja pelanggan sepanjang waktu.
+type CustomerSummary struct {
+	CustomerID        int         `json:"customer_id"`
+	TotalTransactions int         `json:"total_transactions"`
+	LifetimeValue     money.Money `json:"lifetime_value"`
+	AverageBasket     money.Money `json:"average_basket"`
+	FirstPurchaseAt   *time.Time  `json:"first_purchase_at,omitempty"`
+	LastPurchaseAt    *time.Time  `json:"last_purchase_at,omitempty"`
+}
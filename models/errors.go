@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/money"
+	"strings"
+)
+
+// Jenis error domain. Repository dan service membungkus error-nya dengan salah satu
+// jenis ini (cek dengan errors.Is), dan handler menerjemahkannya ke status HTTP.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrValidation = errors.New("validation failed")
+	ErrConflict   = errors.New("conflict")
+	// ErrForbidden: identitas diketahui tetapi tidak berhak (izin peran, PIN supervisor salah)
+	ErrForbidden = errors.New("forbidden")
+	// ErrTooManyAttempts: aksi ditolak sementara karena terlalu sering gagal (PIN terkunci)
+	ErrTooManyAttempts = errors.New("too many attempts")
+	// ErrUnsupported: fitur tidak didukung backend penyimpanan yang dipakai (memory, SQLite)
+	ErrUnsupported = errors.New("not supported by this storage backend")
+	// ErrOverflow: hasil hitungan uang melebihi batas int64 (lihat money.ErrOverflow)
+	ErrOverflow = money.ErrOverflow
+)
+
+// DomainError adalah error dengan pesan untuk klien dan jenis (ErrNotFound, dst.).
+type DomainError struct {
+	Kind    error
+	Message string
+}
+
+func (e *DomainError) Error() string { return e.Message }
+func (e *DomainError) Unwrap() error { return e.Kind }
+
+// NotFound menghasilkan error "<what> not found", mis. NotFound("product").
+func NotFound(what string) error {
+	return &DomainError{Kind: ErrNotFound, Message: what + " not found"}
+}
+
+// Validationf untuk input yang ditolak aturan bisnis (mis. "price must not be negative").
+func Validationf(format string, args ...any) error {
+	return &DomainError{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// Forbidden untuk aksi yang ditolak karena izin, mis. Forbidden("permission denied").
+func Forbidden(message string) error {
+	return &DomainError{Kind: ErrForbidden, Message: message}
+}
+
+// TooManyAttempts untuk aksi yang dikunci sementara setelah gagal berulang kali.
+func TooManyAttempts(message string) error {
+	return &DomainError{Kind: ErrTooManyAttempts, Message: message}
+}
+
+// Conflictf untuk aksi yang bertentangan dengan keadaan data saat ini (mis. sudah direfund,
+// kode sudah dipakai).
+func Conflictf(format string, args ...any) error {
+	return &DomainError{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// InsufficientStockError dikembalikan checkout jika stok tidak cukup. Quantity dalam
+// satuan dasar produk; VariantID diisi jika stok yang kurang adalah stok varian.
+type InsufficientStockError struct {
+	ProductID int
+	VariantID *int
+	Available float64
+	Requested float64
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %d: %g available, %g requested", e.ProductID, e.Available, e.Requested)
+}
+
+// Unwrap: stok kurang adalah konflik dengan keadaan stok saat ini.
+func (e *InsufficientStockError) Unwrap() error { return ErrConflict }
+
+// FieldError adalah satu field input yang ditolak validasi. Field memakai nama JSON,
+// dengan indeks untuk elemen array (mis. "items[2].quantity").
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors berisi semua field yang gagal sekaligus, agar klien bisa menandai
+// semuanya dalam satu kali submit.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, f := range e {
+		msgs[i] = f.Field + " " + f.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e ValidationErrors) Unwrap() error { return ErrValidation }
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"kasir-api/money"
+	"time"
+)
+
+// Jenis entri buku poin loyalti
+const (
+	LoyaltyEarn     = "earn"
+	LoyaltyRedeem   = "redeem"
+	LoyaltyReversal = "reversal"
+	LoyaltyExpire   = "expire"
+)
+
+// LoyaltyRules adalah aturan program loyalti yang berlaku untuk semua pelanggan.
+// SpendPerPoint 0 berarti pelanggan tidak mendapat poin dari belanja.
+type LoyaltyRules struct {
+	SpendPerPoint       money.Amount         `json:"spend_per_point"` // belanja (Rp) untuk 1 poin
+	PointValue          money.Amount         `json:"point_value"`     // nilai tukar 1 poin (Rp)
+	ExpiryDays          int                  `json:"expiry_days"`     // 0 = poin tidak kedaluwarsa
+	CategoryMultipliers []CategoryMultiplier `json:"category_multipliers"`
+}
+
+type CategoryMultiplier struct {
+	CategoryID int     `json:"category_id"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+type LoyaltyEntry struct {
+	ID            int        `json:"id"`
+	CustomerID    int        `json:"customer_id"`
+	TransactionID *int       `json:"transaction_id,omitempty"`
+	Kind          string     `json:"kind"`
+	Points        int        `json:"points"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type LoyaltyBalance struct {
+	CustomerID int            `json:"customer_id"`
+	Points     int            `json:"points"`
+	Value      money.Amount   `json:"value"`
+	Entries    []LoyaltyEntry `json:"entries"`
+}
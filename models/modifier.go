@@ -0,0 +1,23 @@
+package models
+
+import "kasir-api/money"
+
+// ModifierGroup adalah kumpulan pilihan tambahan (mis. "Extra", "Milk", "Sugar level")
+// yang melekat ke satu produk atau ke semua produk dalam satu kategori.
+type ModifierGroup struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	ProductID  *int       `json:"product_id,omitempty"`
+	CategoryID *int       `json:"category_id,omitempty"`
+	Required   bool       `json:"required"`
+	MinSelect  int        `json:"min_select"`
+	MaxSelect  int        `json:"max_select"` // 0 = tanpa batas
+	Options    []Modifier `json:"options"`
+}
+
+type Modifier struct {
+	ID         int          `json:"id"`
+	GroupID    int          `json:"group_id"`
+	Name       string       `json:"name"`
+	PriceDelta money.Amount `json:"price_delta"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"kasir-api/money"
+	"time"
+)
+
+// Sumber perubahan harga produk.
+const (
+	PriceSourceCreate    = "create"    // harga awal saat produk dibuat
+	PriceSourceManual    = "manual"    // diubah lewat PUT /api/products/{id}
+	PriceSourceScheduled = "scheduled" // diterapkan scheduler dari ScheduledPrice
+)
+
+// Status perubahan harga terjadwal.
+const (
+	ScheduledPricePending   = "pending"
+	ScheduledPriceApplied   = "applied"
+	ScheduledPriceCancelled = "cancelled"
+)
+
+// PriceChange adalah satu baris riwayat harga produk. OldPrice nil untuk harga awal.
+type PriceChange struct {
+	ID               int           `json:"id"`
+	ProductID        int           `json:"product_id"`
+	OldPrice         *money.Amount `json:"old_price"`
+	NewPrice         money.Amount  `json:"new_price"`
+	Source           string        `json:"source"`
+	ScheduledPriceID *int          `json:"scheduled_price_id,omitempty"`
+	ChangedBy        *int          `json:"changed_by,omitempty"`
+	ChangedAt        time.Time     `json:"changed_at"`
+}
+
+// ScheduledPrice adalah perubahan harga yang direncanakan; scheduler di server
+// menerapkannya begitu EffectiveAt terlewati.
+type ScheduledPrice struct {
+	ID          int          `json:"id"`
+	ProductID   int          `json:"product_id"`
+	Price       money.Amount `json:"price"`
+	EffectiveAt time.Time    `json:"effective_at"`
+	Status      string       `json:"status"`
+	CreatedBy   *int         `json:"created_by,omitempty"`
+	AppliedAt   *time.Time   `json:"applied_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
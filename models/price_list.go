@@ -0,0 +1,23 @@
+package models
+
+import "kasir-api/money"
+
+// PriceList adalah daftar harga khusus (mis. member, grosir, reseller). Produk yang
+// tidak ada di daftar tetap memakai harga dasar produk (harga eceran).
+type PriceList struct {
+	ID    int             `json:"id"`
+	Code  string          `json:"code"`
+	Name  string          `json:"name"`
+	Items []PriceListItem `json:"items,omitempty"`
+}
+
+// PriceListItem adalah harga per satuan dasar yang berlaku mulai MinQuantity
+// (mis. 12+ pcs), sehingga satu produk bisa punya beberapa tingkat harga.
+type PriceListItem struct {
+	ID          int          `json:"id"`
+	PriceListID int          `json:"price_list_id"`
+	ProductID   int          `json:"product_id"`
+	VariantID   *int         `json:"variant_id,omitempty"`
+	MinQuantity float64      `json:"min_quantity"`
+	Price       money.Amount `json:"price"`
+}
@@ -1,10 +1,21 @@
 package models
 
+import "kasir-api/money"
+
 type Product struct {
-	ID           int    `json:"id"`
-	Name         string `json:"name"`
-	Price        int    `json:"price"`
-	Stock        int    `json:"stock"`
-	CategoryID   *int   `json:"category_id,omitempty"`
-	CategoryName string `json:"category_name,omitempty"`
+	ID           int              `json:"id"`
+	Name         string           `json:"name"`
+	Price        money.Amount     `json:"price"`
+	Stock        float64          `json:"stock"`
+	Unit         string           `json:"unit"`
+	DecimalQty   bool             `json:"decimal_qty"`
+	PLU          string           `json:"plu,omitempty"`
+	CategoryID   *int             `json:"category_id,omitempty"`
+	CategoryName string           `json:"category_name,omitempty"`
+	OptionAxes   []string         `json:"option_axes,omitempty"`
+	Variants     []ProductVariant `json:"variants,omitempty"`
+	Units        []ProductUnit    `json:"units,omitempty"`
+
+	// ChangedBy diisi handler dari user yang login, untuk riwayat harga
+	ChangedBy *int `json:"-"`
 }
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"kasir-api/money"
+	"time"
+)
+
+// Status piutang (kasbon)
+const (
+	ReceivableOpen = "open"
+	ReceivablePaid = "paid"
+	ReceivableVoid = "void" // transaksinya di-refund
+)
+
+// Receivable adalah piutang dari satu transaksi "bayar nanti".
+type Receivable struct {
+	ID            int          `json:"id"`
+	CustomerID    int          `json:"customer_id"`
+	TransactionID int          `json:"transaction_id"`
+	Amount        money.Amount `json:"amount"`
+	PaidAmount    money.Amount `json:"paid_amount"`
+	Outstanding   money.Amount `json:"outstanding"`
+	Status        string       `json:"status"`
+	CreatedAt     time.Time    `json:"created_at"`
+	SettledAt     *time.Time   `json:"settled_at,omitempty"`
+}
+
+// ReceivablePayment adalah cicilan/pelunasan kasbon; dialokasikan ke piutang terlama lebih dulu.
+type ReceivablePayment struct {
+	ID          int                    `json:"id"`
+	CustomerID  int                    `json:"customer_id"`
+	Amount      money.Amount           `json:"amount"`
+	Note        string                 `json:"note,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	Allocations []ReceivableAllocation `json:"allocations"`
+}
+
+type ReceivableAllocation struct {
+	ReceivableID int          `json:"receivable_id"`
+	Amount       money.Amount `json:"amount"`
+}
+
+// CustomerCredit adalah posisi kasbon pelanggan saat ini.
+type CustomerCredit struct {
+	CustomerID      int          `json:"customer_id"`
+	CreditLimit     money.Amount `json:"credit_limit"`
+	Outstanding     money.Amount `json:"outstanding"`
+	AvailableCredit money.Amount `json:"available_credit"`
+	Receivables     []Receivable `json:"receivables"`
+}
+
+// AgingRow adalah sisa piutang satu pelanggan per umur piutang.
+type AgingRow struct {
+	CustomerID   int          `json:"customer_id"`
+	CustomerName string       `json:"customer_name"`
+	Days0To30    money.Amount `json:"days_0_30"`
+	Days31To60   money.Amount `json:"days_31_60"`
+	Days61Plus   money.Amount `json:"days_61_plus"`
+	Total        money.Amount `json:"total"`
+}
+
+type AgingReport struct {
+	Currency  string     `json:"currency"`
+	AsOf      time.Time  `json:"as_of"`
+	Customers []AgingRow `json:"customers"`
+	Totals    AgingRow   `json:"totals"`
+}
@@ -1,12 +1,37 @@
 package models
 
+import "kasir-api/money"
+
+// UnitQuantity adalah jumlah terjual dalam satu satuan jual (mis. 2 "box").
+type UnitQuantity struct {
+	Unit     string  `json:"unit"`
+	Quantity float64 `json:"quantity"`
+}
+
+// BestsellingProduct adalah satu produk (atau varian) terlaris. QtySold dalam satuan
+// dasar produk (Unit); Units memecahnya menurut satuan yang benar-benar dijual.
 type BestsellingProduct struct {
-	Name    string `json:"name"`
-	QtySold int    `json:"qty_sold"`
+	ProductID   int            `json:"product_id"`
+	Name        string         `json:"name"`
+	VariantID   *int           `json:"variant_id,omitempty"`
+	VariantName string         `json:"variant_name,omitempty"`
+	Unit        string         `json:"unit"`
+	QtySold     float64        `json:"qty_sold"`
+	Units       []UnitQuantity `json:"units"`
+}
+
+// BestsellingModifier diurutkan menurut jumlah baris transaksi yang memakainya; Units
+// berisi jumlah barang yang diberi modifier itu per satuan jual.
+type BestsellingModifier struct {
+	ModifierID   int            `json:"modifier_id"`
+	Name         string         `json:"name"`
+	TimesOrdered int            `json:"times_ordered"`
+	Units        []UnitQuantity `json:"units"`
 }
 
 type TodayReport struct {
-	TotalRevenue        int                  `json:"total_revenue"`
-	TotalTransactions   int                  `json:"total_transactions"`
-	BestsellingProducts []BestsellingProduct `json:"bestselling_products"`
+	TotalRevenue         money.Money           `json:"total_revenue"`
+	TotalTransactions    int                   `json:"total_transactions"`
+	BestsellingProducts  []BestsellingProduct  `json:"bestselling_products"`
+	BestsellingModifiers []BestsellingModifier `json:"bestselling_modifiers"`
 }
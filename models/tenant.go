@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// DefaultTenantID adalah toko bawaan: semua data lama dan mode satu tenant memakai ID ini.
+const DefaultTenantID = 1
+
+// Tenant adalah satu toko. Setiap tabel menyimpan tenant_id, dan setiap repository
+// dibuat untuk satu tenant sehingga query tidak bisa membaca data toko lain.
+type Tenant struct {
+	ID        int       `json:"id"`
+	Slug      string    `json:"slug"` // subdomain, mis. "tokoabc" untuk tokoabc.kasir.example
+	Name      string    `json:"name"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateTenantRequest membuat toko baru beserta admin pertamanya.
+type CreateTenantRequest struct {
+	Slug          string `json:"slug"`
+	Name          string `json:"name"`
+	AdminUsername string `json:"admin_username"`
+	AdminPassword string `json:"admin_password"`
+}
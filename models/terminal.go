@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Terminal adalah perangkat kasir terdaftar. Kasir hanya bisa login PIN di terminal
+// tempat ia didaftarkan; Key hanya dikembalikan sekali saat terminal dibuat.
+type Terminal struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Active     bool       `json:"active"`
+	UserIDs    []int      `json:"user_ids"`
+	Key        string     `json:"key,omitempty"`
+	KeyHash    string     `json:"-"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type PINLoginRequest struct {
+	Username string `json:"username"`
+	PIN      string `json:"pin"`
+}
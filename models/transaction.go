@@ -1,25 +1,104 @@
 package models
 
+import (
+	"kasir-api/money"
+	"time"
+)
+
+// Status transaksi
+const (
+	TransactionCompleted = "completed"
+	TransactionRefunded  = "refunded"
+)
+
+// Metode pembayaran. Pembulatan total hanya berlaku untuk tunai; PaymentPayLater
+// mencatat total sebagai piutang (kasbon) pelanggan.
+const (
+	PaymentCash     = "cash"
+	PaymentCard     = "card"
+	PaymentTransfer = "transfer"
+	PaymentQRIS     = "qris"
+	PaymentPayLater = "pay_later"
+)
+
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	Details     []TransactionDetail `json:"details"`
+	ID                 int                 `json:"id"`
+	Status             string              `json:"status"`
+	Subtotal           money.Amount        `json:"subtotal"` // sebelum diskon, potongan poin & pembulatan
+	DiscountPercent    float64             `json:"discount_percent,omitempty"`
+	Discount           money.Amount        `json:"discount"`
+	LoyaltyDiscount    money.Amount        `json:"loyalty_discount"`
+	Tax                money.Amount        `json:"tax"`          // atas total setelah diskon & potongan poin
+	TotalAmount        money.Money         `json:"total_amount"` // mata uangnya berlaku untuk semua nominal transaksi
+	RoundingAdjustment money.Amount        `json:"rounding_adjustment"`
+	PaymentMethod      string              `json:"payment_method"`
+	CustomerID         *int                `json:"customer_id,omitempty"`
+	PointsEarned       int                 `json:"points_earned"`
+	PointsRedeemed     int                 `json:"points_redeemed"`
+	CashierID          *int                `json:"cashier_id,omitempty"`
+	TerminalID         *int                `json:"terminal_id,omitempty"`
+	ApprovedBy         *int                `json:"approved_by,omitempty"` // supervisor yang menyetujui override
+	CreatedAt          time.Time           `json:"created_at"`
+	RefundedAt         *time.Time          `json:"refunded_at,omitempty"`
+	RefundedBy         *int                `json:"refunded_by,omitempty"`
+	Details            []TransactionDetail `json:"details"`
 }
 
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID            int                         `json:"id"`
+	TransactionID int                         `json:"transaction_id"`
+	ProductID     int                         `json:"product_id"`
+	ProductName   string                      `json:"product_name"`
+	VariantID     *int                        `json:"variant_id,omitempty"`
+	VariantName   string                      `json:"variant_name,omitempty"`
+	Quantity      float64                     `json:"quantity"`
+	Unit          string                      `json:"unit"`
+	BaseQuantity  float64                     `json:"base_quantity"`
+	PriceListID   *int                        `json:"price_list_id,omitempty"`
+	UnitPrice     money.Amount                `json:"unit_price"`
+	PriceOverride bool                        `json:"price_override,omitempty"` // harga diubah manual di kasir
+	Subtotal      money.Amount                `json:"subtotal"`
+	Modifiers     []TransactionDetailModifier `json:"modifiers,omitempty"`
+}
+
+// TransactionDetailModifier menyimpan salinan nama & harga modifier saat transaksi,
+// sehingga struk tetap benar walaupun modifier diubah atau dihapus kemudian.
+type TransactionDetailModifier struct {
+	ModifierID int          `json:"modifier_id"`
+	Name       string       `json:"name"`
+	PriceDelta money.Amount `json:"price_delta"`
 }
 
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID   int     `json:"product_id"`
+	VariantID   *int    `json:"variant_id,omitempty"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit,omitempty"`
+	Barcode     string  `json:"barcode,omitempty"`
+	ModifierIDs []int   `json:"modifier_ids,omitempty"`
+	// PriceOverride mengganti harga per satuan jual (termasuk modifier); butuh izin prices:override.
+	PriceOverride *money.Amount `json:"price_override,omitempty"`
+	// EmbeddedPrice diisi service dari barcode timbangan berisi harga; subtotal baris
+	// memakai harga ini apa adanya.
+	EmbeddedPrice *money.Amount `json:"-"`
 }
 
 type CheckoutRequest struct {
 	Items []CheckoutItem `json:"items"`
+	// pelanggan boleh dikenali lewat id atau nomor HP yang disebutkan di kasir
+	CustomerID    *int   `json:"customer_id,omitempty"`
+	CustomerPhone string `json:"customer_phone,omitempty"`
+	// RedeemPoints adalah poin loyalti pelanggan yang ditukar sebagai potongan harga
+	RedeemPoints int `json:"redeem_points,omitempty"`
+	// daftar harga eksplisit; jika kosong dipakai daftar harga pelanggan
+	PriceListID   *int   `json:"price_list_id,omitempty"`
+	PriceListCode string `json:"price_list_code,omitempty"`
+	PaymentMethod string `json:"payment_method,omitempty"` // default cash
+	// DiscountPercent adalah diskon manual atas subtotal (mis. 12.5 = 12,5%).
+	DiscountPercent float64 `json:"discount_percent,omitempty"`
+	// CashierID/TerminalID diisi handler dari sesi login; ApprovedBy diisi jika
+	// override harga/diskon disetujui supervisor.
+	CashierID  *int `json:"-"`
+	TerminalID *int `json:"-"`
+	ApprovedBy *int `json:"-"`
 }
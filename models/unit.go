@@ -0,0 +1,33 @@
+package models
+
+import (
+	"kasir-api/money"
+	"math"
+)
+
+// ProductUnit adalah satuan alternatif sebuah produk, dikonversi ke satuan dasar
+// produk lewat Factor (mis. 1 karton = 24 pcs → Factor 24).
+type ProductUnit struct {
+	ID          int           `json:"id"`
+	ProductID   int           `json:"product_id"`
+	Name        string        `json:"name"`
+	Factor      float64       `json:"factor"`
+	Price       *money.Amount `json:"price,omitempty"` // nil → harga dasar × factor
+	Barcode     string        `json:"barcode,omitempty"`
+	Sellable    bool          `json:"sellable"`
+	Purchasable bool          `json:"purchasable"`
+}
+
+// StockReceipt adalah penerimaan barang dalam satuan beli (mis. 3 karton).
+type StockReceipt struct {
+	ProductID    int     `json:"product_id"`
+	VariantID    *int    `json:"variant_id,omitempty"`
+	Unit         string  `json:"unit,omitempty"`
+	Quantity     float64 `json:"quantity"`
+	BaseQuantity float64 `json:"base_quantity"`
+}
+
+// RoundQuantity membulatkan kuantitas ke 3 desimal, sesuai presisi kolom stok/kuantitas.
+func RoundQuantity(q float64) float64 {
+	return math.Round(q*1000) / 1000
+}
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// User adalah akun yang boleh masuk ke API (kasir, admin, dst).
+type User struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	Name         string `json:"name"`
+	Role         string `json:"role"`
+	Password     string `json:"password,omitempty"` // hanya untuk input, tidak pernah dikembalikan
+	PasswordHash string `json:"-"`
+	PIN          string `json:"pin,omitempty"` // PIN angka untuk override supervisor, hanya input
+	PINHash      string `json:"-"`
+	HasPIN       bool   `json:"has_pin"`
+	// PIN salah berturut-turut mengunci login PIN sampai PINLockedUntil
+	FailedPINAttempts int        `json:"-"`
+	PINLockedUntil    *time.Time `json:"pin_locked_until,omitempty"`
+	Active            bool       `json:"active"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// Session adalah satu login; refresh token disimpan sebagai hash dan diganti setiap refresh.
+type Session struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	TerminalID  *int       `json:"terminal_id,omitempty"` // diisi untuk sesi login PIN
+	RefreshHash string     `json:"-"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // detik
+}
+
+// Principal adalah identitas yang sudah terautentikasi untuk satu request: user yang
+// login (izin dari Role) atau API key (izin dari Scopes, UserID kosong).
+type Principal struct {
+	TenantID   int    `json:"tenant_id"`
+	UserID     int    `json:"user_id,omitempty"`
+	Username   string `json:"username"`
+	Role       string `json:"role,omitempty"`
+	SessionID  int    `json:"session_id,omitempty"`
+	TerminalID *int   `json:"terminal_id,omitempty"`
+
+	APIKeyID  *int     `json:"api_key_id,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	RateLimit int      `json:"-"` // request per menit untuk API key
+}
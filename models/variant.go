@@ -0,0 +1,13 @@
+package models
+
+import "kasir-api/money"
+
+type ProductVariant struct {
+	ID        int               `json:"id"`
+	ProductID int               `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Name      string            `json:"name"`
+	Options   map[string]string `json:"options"`
+	Price     money.Amount      `json:"price"`
+	Stock     float64           `json:"stock"`
+}
@@ -0,0 +1,35 @@
+package money
+
+// Config adalah aturan uang yang berlaku untuk seluruh aplikasi.
+type Config struct {
+	Currency string // kode ISO 4217, mis. "IDR"
+	// CashRounding adalah kelipatan pembulatan total tunai (mis. 100 → Rp100); 0 atau 1 = tanpa pembulatan
+	CashRounding Amount
+	Mode         RoundingMode
+	// TaxRate adalah pajak atas total setelah diskon dalam basis poin (1100 = PPN 11%); 0 = tanpa pajak
+	TaxRate int64
+}
+
+var current = Config{Currency: "IDR", Mode: HalfUp}
+
+// Configure mengganti konfigurasi uang. Dipanggil sekali saat startup, sebelum server menerima request.
+func Configure(c Config) {
+	if c.Currency == "" {
+		c.Currency = "IDR"
+	}
+	if c.Mode == "" {
+		c.Mode = HalfUp
+	}
+	current = c
+}
+
+// Current mengembalikan konfigurasi uang yang sedang berlaku.
+func Current() Config {
+	return current
+}
+
+// Tax menghitung pajak atas nominal lewat Percent dengan mode pembulatan konfigurasi,
+// sama seperti diskon persen.
+func (c Config) Tax(base Amount) (Amount, error) {
+	return base.Percent(c.TaxRate, c.Mode)
+}
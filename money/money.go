@@ -0,0 +1,193 @@
+// Package money menyediakan tipe nominal uang dengan aritmetika yang dicek overflow
+// dan aturan pembulatan yang konsisten untuk subtotal, diskon persen, pajak, dan
+// pembulatan total tunai.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Amount adalah nominal dalam satuan terkecil mata uang (untuk IDR: 1 = Rp1).
+// Di JSON dan database ditulis sebagai bilangan bulat biasa.
+type Amount int64
+
+var (
+	ErrOverflow         = errors.New("money: amount overflow")
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+)
+
+// RoundingMode menentukan arah pembulatan saat hasil perhitungan jatuh di antara dua nilai.
+type RoundingMode string
+
+const (
+	HalfUp   RoundingMode = "half_up"   // 0.5 menjauhi nol
+	HalfEven RoundingMode = "half_even" // 0.5 ke bilangan genap terdekat (banker's rounding)
+	Down     RoundingMode = "down"      // selalu mendekati nol
+	Up       RoundingMode = "up"        // selalu menjauhi nol
+)
+
+// ParseRoundingMode membaca mode pembulatan dari konfigurasi; string kosong berarti HalfUp.
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch m := RoundingMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return HalfUp, nil
+	case HalfUp, HalfEven, Down, Up:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown rounding mode %q", s)
+	}
+}
+
+// quantityScale adalah presisi kuantitas (3 desimal), sama dengan kolom stok.
+const quantityScale = 1000
+
+func (a Amount) Add(b Amount) (Amount, error) {
+	c := a + b
+	if (b > 0 && c < a) || (b < 0 && c > a) {
+		return 0, ErrOverflow
+	}
+	return c, nil
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	c := a - b
+	if (b > 0 && c > a) || (b < 0 && c < a) {
+		return 0, ErrOverflow
+	}
+	return c, nil
+}
+
+// Mul mengalikan dengan bilangan bulat (mis. jumlah barang utuh).
+func (a Amount) Mul(n int64) (Amount, error) {
+	c, ok := mul64(int64(a), n)
+	if !ok {
+		return 0, ErrOverflow
+	}
+	return Amount(c), nil
+}
+
+// MulQuantity mengalikan dengan kuantitas pecahan (mis. 0.75 kg). Kuantitas dibaca
+// dengan presisi 3 desimal lalu hasilnya dibulatkan sesuai mode.
+func (a Amount) MulQuantity(q float64, mode RoundingMode) (Amount, error) {
+	scaled := math.Round(q * quantityScale)
+	if scaled > math.MaxInt64 || scaled < math.MinInt64 {
+		return 0, ErrOverflow
+	}
+	c, ok := mul64(int64(a), int64(scaled))
+	if !ok {
+		return 0, ErrOverflow
+	}
+	return Amount(divRound(c, quantityScale, mode)), nil
+}
+
+// Percent menghitung bagian persen dari nominal dalam basis poin (100 bp = 1%),
+// dipakai untuk diskon persen dan pajak agar aturan pembulatannya sama di semua tempat.
+func (a Amount) Percent(basisPoints int64, mode RoundingMode) (Amount, error) {
+	c, ok := mul64(int64(a), basisPoints)
+	if !ok {
+		return 0, ErrOverflow
+	}
+	return Amount(divRound(c, 10000, mode)), nil
+}
+
+// RoundTo membulatkan ke kelipatan step (mis. total tunai ke Rp100 terdekat).
+// step <= 1 mengembalikan nominal apa adanya.
+func (a Amount) RoundTo(step Amount, mode RoundingMode) (Amount, error) {
+	if step <= 1 {
+		return a, nil
+	}
+	return Amount(divRound(int64(a), int64(step), mode)).Mul(int64(step))
+}
+
+// Money adalah nominal beserta mata uangnya. Di JSON ditulis sebagai
+// {"amount": 15000, "currency": "IDR"}, jadi klien tidak perlu menebak mata uang total.
+type Money struct {
+	Amount   Amount `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New membuat Money dalam mata uang yang sedang dikonfigurasi.
+func New(a Amount) Money {
+	return Money{Amount: a, Currency: current.Currency}
+}
+
+// Add menjumlahkan dua nominal; mata uang yang berbeda ditolak, bukan dikonversi.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	a, err := m.Amount.Add(o.Amount)
+	return Money{Amount: a, Currency: m.Currency}, err
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	a, err := m.Amount.Sub(o.Amount)
+	return Money{Amount: a, Currency: m.Currency}, err
+}
+
+func (m Money) Percent(basisPoints int64, mode RoundingMode) (Money, error) {
+	a, err := m.Amount.Percent(basisPoints, mode)
+	return Money{Amount: a, Currency: m.Currency}, err
+}
+
+func (m Money) RoundTo(step Amount, mode RoundingMode) (Money, error) {
+	a, err := m.Amount.RoundTo(step, mode)
+	return Money{Amount: a, Currency: m.Currency}, err
+}
+
+// Sum menjumlahkan beberapa nominal dengan pengecekan overflow.
+func Sum(amounts ...Amount) (Amount, error) {
+	var total Amount
+	for _, a := range amounts {
+		var err error
+		if total, err = total.Add(a); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+func mul64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return c, true
+}
+
+// divRound membagi n dengan d (d > 0) dan membulatkan hasilnya sesuai mode.
+func divRound(n, d int64, mode RoundingMode) int64 {
+	q, r := n/d, n%d
+	if r == 0 {
+		return q
+	}
+	sign := int64(1)
+	if n < 0 {
+		sign, r = -1, -r
+	}
+	switch mode {
+	case Down:
+		return q
+	case Up:
+		return q + sign
+	case HalfEven:
+		if 2*r > d || (2*r == d && q%2 != 0) {
+			return q + sign
+		}
+		return q
+	default: // HalfUp
+		if 2*r >= d {
+			return q + sign
+		}
+		return q
+	}
+}
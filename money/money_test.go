@@ -0,0 +1,196 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+var modes = []RoundingMode{HalfUp, HalfEven, Down, Up}
+
+func TestOverflow(t *testing.T) {
+	const maxAmount, minAmount = Amount(math.MaxInt64), Amount(math.MinInt64)
+	tests := []struct {
+		name string
+		op   func() (Amount, error)
+		want Amount
+		// overflow: hasil tidak muat di int64 dan harus ditolak
+		overflow bool
+	}{
+		{name: "add", op: func() (Amount, error) { return Amount(1500).Add(2500) }, want: 4000},
+		{name: "add max", op: func() (Amount, error) { return maxAmount.Add(1) }, overflow: true},
+		{name: "add min", op: func() (Amount, error) { return minAmount.Add(-1) }, overflow: true},
+		{name: "add max negative", op: func() (Amount, error) { return maxAmount.Add(-1) }, want: maxAmount - 1},
+		{name: "sub", op: func() (Amount, error) { return Amount(1500).Sub(2500) }, want: -1000},
+		{name: "sub min", op: func() (Amount, error) { return minAmount.Sub(1) }, overflow: true},
+		{name: "sub max", op: func() (Amount, error) { return maxAmount.Sub(-1) }, overflow: true},
+		{name: "sub from zero", op: func() (Amount, error) { return Amount(0).Sub(minAmount) }, overflow: true},
+		{name: "mul", op: func() (Amount, error) { return Amount(15000).Mul(3) }, want: 45000},
+		{name: "mul zero", op: func() (Amount, error) { return Amount(0).Mul(math.MaxInt64) }, want: 0},
+		{name: "mul max", op: func() (Amount, error) { return maxAmount.Mul(2) }, overflow: true},
+		{name: "mul min by -1", op: func() (Amount, error) { return minAmount.Mul(-1) }, overflow: true},
+		{name: "mul -1 by min", op: func() (Amount, error) { return Amount(-1).Mul(math.MinInt64) }, overflow: true},
+		{name: "wholesale order", op: func() (Amount, error) { return Amount(5_000_000_000_000).Mul(10_000_000) }, overflow: true},
+		{name: "mul quantity", op: func() (Amount, error) { return Amount(15000).MulQuantity(0.75, HalfUp) }, want: 11250},
+		{name: "mul quantity max", op: func() (Amount, error) { return maxAmount.MulQuantity(2, HalfUp) }, overflow: true},
+		{name: "mul quantity huge", op: func() (Amount, error) { return Amount(1).MulQuantity(1e19, HalfUp) }, overflow: true},
+		{name: "sum", op: func() (Amount, error) { return Sum(1000, 2000, 3000) }, want: 6000},
+		{name: "sum max", op: func() (Amount, error) { return Sum(maxAmount, 1) }, overflow: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if errors.Is(err, ErrOverflow) != tt.overflow {
+				t.Fatalf("err = %v, want overflow %v", err, tt.overflow)
+			}
+			if got != tt.want {
+				t.Errorf("= %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// want berisi hasil per mode, urut seperti modes: HalfUp, HalfEven, Down, Up.
+func TestDivRound(t *testing.T) {
+	tests := []struct {
+		n, d int64
+		want [4]int64
+	}{
+		{n: 10, d: 5, want: [4]int64{2, 2, 2, 2}},
+		{n: 24, d: 10, want: [4]int64{2, 2, 2, 3}},
+		{n: 25, d: 10, want: [4]int64{3, 2, 2, 3}},
+		{n: 26, d: 10, want: [4]int64{3, 3, 2, 3}},
+		{n: 35, d: 10, want: [4]int64{4, 4, 3, 4}},
+		{n: -24, d: 10, want: [4]int64{-2, -2, -2, -3}},
+		{n: -25, d: 10, want: [4]int64{-3, -2, -2, -3}},
+		{n: -26, d: 10, want: [4]int64{-3, -3, -2, -3}},
+		{n: -35, d: 10, want: [4]int64{-4, -4, -3, -4}},
+		{n: 1, d: 3, want: [4]int64{0, 0, 0, 1}},
+		{n: 2, d: 3, want: [4]int64{1, 1, 0, 1}},
+	}
+	for _, tt := range tests {
+		for i, mode := range modes {
+			if got := divRound(tt.n, tt.d, mode); got != tt.want[i] {
+				t.Errorf("divRound(%d, %d, %s) = %d, want %d", tt.n, tt.d, mode, got, tt.want[i])
+			}
+		}
+	}
+	// mode kosong (belum dikonfigurasi) berlaku seperti HalfUp
+	if got := divRound(25, 10, ""); got != 3 {
+		t.Errorf("divRound(25, 10, \"\") = %d, want 3", got)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		amount      Amount
+		basisPoints int64
+		want        [4]Amount
+	}{
+		{amount: 1250, basisPoints: 1000, want: [4]Amount{125, 125, 125, 125}},      // 10%, pas
+		{amount: 1150, basisPoints: 1100, want: [4]Amount{127, 126, 126, 127}},      // PPN 11% = 126.5
+		{amount: 1050, basisPoints: 1100, want: [4]Amount{116, 116, 115, 116}},      // 115.5
+		{amount: 1001, basisPoints: 1000, want: [4]Amount{100, 100, 100, 101}},      // 100.1
+		{amount: 33333, basisPoints: 1250, want: [4]Amount{4167, 4167, 4166, 4167}}, // 12.5% = 4166.625
+		{amount: -1150, basisPoints: 1100, want: [4]Amount{-127, -126, -126, -127}},
+		{amount: 20000, basisPoints: 0, want: [4]Amount{0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		for i, mode := range modes {
+			got, err := tt.amount.Percent(tt.basisPoints, mode)
+			if err != nil || got != tt.want[i] {
+				t.Errorf("%d.Percent(%d, %s) = %d, %v; want %d", tt.amount, tt.basisPoints, mode, got, err, tt.want[i])
+			}
+		}
+	}
+	if _, err := Amount(math.MaxInt64).Percent(2, HalfUp); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Percent overflow: err = %v, want ErrOverflow", err)
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		step   Amount
+		want   [4]Amount
+	}{
+		{amount: 12300, step: 100, want: [4]Amount{12300, 12300, 12300, 12300}},
+		{amount: 12301, step: 100, want: [4]Amount{12300, 12300, 12300, 12400}},
+		{amount: 12350, step: 100, want: [4]Amount{12400, 12400, 12300, 12400}},
+		{amount: 12250, step: 100, want: [4]Amount{12300, 12200, 12200, 12300}},
+		{amount: 12375, step: 500, want: [4]Amount{12500, 12500, 12000, 12500}},
+		{amount: -12350, step: 100, want: [4]Amount{-12400, -12400, -12300, -12400}},
+		{amount: 12345, step: 1, want: [4]Amount{12345, 12345, 12345, 12345}},
+		{amount: 12345, step: 0, want: [4]Amount{12345, 12345, 12345, 12345}},
+	}
+	for _, tt := range tests {
+		for i, mode := range modes {
+			got, err := tt.amount.RoundTo(tt.step, mode)
+			if err != nil || got != tt.want[i] {
+				t.Errorf("%d.RoundTo(%d, %s) = %d, %v; want %d", tt.amount, tt.step, mode, got, err, tt.want[i])
+			}
+		}
+	}
+	if _, err := Amount(math.MaxInt64).RoundTo(100, Up); !errors.Is(err, ErrOverflow) {
+		t.Errorf("RoundTo overflow: err = %v, want ErrOverflow", err)
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for in, want := range map[string]RoundingMode{"": HalfUp, "half_even": HalfEven, " DOWN ": Down, "up": Up} {
+		if got, err := ParseRoundingMode(in); err != nil || got != want {
+			t.Errorf("ParseRoundingMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseRoundingMode("nearest"); err == nil {
+		t.Error("ParseRoundingMode(nearest) succeeded")
+	}
+}
+
+func TestMoney(t *testing.T) {
+	idr := Money{Amount: 15000, Currency: "IDR"}
+	if got, err := idr.Add(Money{Amount: 2500, Currency: "IDR"}); err != nil || got != (Money{Amount: 17500, Currency: "IDR"}) {
+		t.Errorf("Add = %+v, %v", got, err)
+	}
+	if _, err := idr.Add(Money{Amount: 1, Currency: "USD"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add USD to IDR: err = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := idr.Sub(Money{Amount: 1, Currency: "USD"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub USD from IDR: err = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := (Money{Amount: math.MinInt64, Currency: "IDR"}).Sub(Money{Amount: 1, Currency: "IDR"}); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Sub overflow: err = %v, want ErrOverflow", err)
+	}
+	if got, err := idr.Percent(1100, HalfUp); err != nil || got != (Money{Amount: 1650, Currency: "IDR"}) {
+		t.Errorf("Percent = %+v, %v", got, err)
+	}
+	if got, err := (Money{Amount: 12350, Currency: "IDR"}).RoundTo(100, Down); err != nil || got != (Money{Amount: 12300, Currency: "IDR"}) {
+		t.Errorf("RoundTo = %+v, %v", got, err)
+	}
+
+	b, err := json.Marshal(idr)
+	if err != nil || string(b) != `{"amount":15000,"currency":"IDR"}` {
+		t.Errorf("json = %s, %v", b, err)
+	}
+}
+
+// Pajak memakai Percent dengan mode pembulatan konfigurasi, sama seperti diskon persen.
+func TestConfigTax(t *testing.T) {
+	tests := []struct {
+		config Config
+		base   Amount
+		want   Amount
+	}{
+		{config: Config{TaxRate: 1100, Mode: HalfUp}, base: 1150, want: 127},
+		{config: Config{TaxRate: 1100, Mode: HalfEven}, base: 1150, want: 126},
+		{config: Config{TaxRate: 1100, Mode: Down}, base: 1150, want: 126},
+		{config: Config{TaxRate: 1100, Mode: Up}, base: 1001, want: 111},
+		{config: Config{Mode: HalfUp}, base: 1150, want: 0},
+	}
+	for _, tt := range tests {
+		if got, err := tt.config.Tax(tt.base); err != nil || got != tt.want {
+			t.Errorf("Tax(%d) with %+v = %d, %v; want %d", tt.base, tt.config, got, err, tt.want)
+		}
+	}
+}
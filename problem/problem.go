@@ -0,0 +1,105 @@
+// Package problem menulis respons error sebagai dokumen JSON RFC 7807
+// (application/problem+json), dipakai semua handler dan middleware.
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"log/slog"
+	"net/http"
+)
+
+const ContentType = "application/problem+json"
+
+// TypeInsufficientStock membedakan stok kurang dari konflik lain (status 409 juga).
+const TypeInsufficientStock = "urn:kasir-api:problem:insufficient-stock"
+
+// Details adalah isi respons error. Type "about:blank" berarti Title sama dengan teks
+// status HTTP; field ekstensi hanya diisi untuk jenis error tertentu.
+type Details struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// stok tidak cukup (TypeInsufficientStock), dalam satuan dasar produk
+	ProductID *int     `json:"product_id,omitempty"`
+	VariantID *int     `json:"variant_id,omitempty"`
+	Available *float64 `json:"available,omitempty"`
+	Requested *float64 `json:"requested,omitempty"`
+
+	// validasi per field (422)
+	Errors models.ValidationErrors `json:"errors,omitempty"`
+}
+
+// New membuat dokumen error dengan Type "about:blank".
+func New(r *http.Request, status int, detail string) *Details {
+	return &Details{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	}
+}
+
+// Write menulis error dengan status dan pesan yang sudah ditentukan pemanggil, untuk
+// kesalahan yang dikenali handler sendiri (ID tidak valid, body bukan JSON, dsb.).
+func Write(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	Send(w, New(r, status, detail))
+}
+
+// Send menulis dokumen error apa adanya.
+func Send(w http.ResponseWriter, p *Details) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// Error menerjemahkan error dari service/repository ke status HTTP. Error yang tidak
+// dikenali menjadi 500 tanpa pesan aslinya (bisa berisi detail database) dan dicatat di log.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	Send(w, FromError(r, err))
+}
+
+func FromError(r *http.Request, err error) *Details {
+	var (
+		stock  *models.InsufficientStockError
+		fields models.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &stock):
+		p := New(r, http.StatusConflict, err.Error())
+		p.Type, p.Title = TypeInsufficientStock, "Insufficient stock"
+		p.ProductID, p.VariantID = &stock.ProductID, stock.VariantID
+		p.Available, p.Requested = &stock.Available, &stock.Requested
+		return p
+	case errors.As(err, &fields):
+		p := New(r, http.StatusUnprocessableEntity, "one or more fields are invalid")
+		p.Errors = fields
+		return p
+	case errors.Is(err, models.ErrNotFound):
+		return New(r, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrValidation):
+		return New(r, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, models.ErrConflict):
+		return New(r, http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrForbidden):
+		return New(r, http.StatusForbidden, err.Error())
+	case errors.Is(err, models.ErrTooManyAttempts):
+		return New(r, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, models.ErrOverflow):
+		return New(r, http.StatusUnprocessableEntity, "amount is too large")
+	case errors.Is(err, models.ErrUnsupported):
+		return New(r, http.StatusNotImplemented, err.Error())
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return New(r, http.StatusServiceUnavailable, "request timed out or was cancelled")
+	}
+
+	slog.ErrorContext(r.Context(), "unhandled error", "method", r.Method, "path", r.URL.Path, "err", err)
+	return New(r, http.StatusInternalServerError, "internal server error")
+}
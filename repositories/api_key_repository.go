@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool, tenantID int) *APIKeyRepository {
+	return &APIKeyRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+const apiKeyColumns = `id, name, prefix, scopes, rate_limit, key_hash, created_by, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row pgx.Row, k *models.APIKey) error {
+	return row.Scan(&k.ID, &k.Name, &k.Prefix, &k.Scopes, &k.RateLimit, &k.KeyHash, &k.CreatedBy, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt)
+}
+
+func (repo *APIKeyRepository) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.pool.Query(ctx, `
+		SELECT `+apiKeyColumns+` FROM api_keys WHERE tenant_id = $1 ORDER BY created_at DESC, id DESC
+	`, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		var k models.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (repo *APIKeyRepository) GetByID(ctx context.Context, id int) (*models.APIKey, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var k models.APIKey
+	if err := scanAPIKey(repo.pool.QueryRow(ctx, `
+		SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1 AND tenant_id = $2
+	`, id, repo.tenantID), &k); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("api key")
+		}
+		return nil, err
+	}
+	return &k, nil
+}
+
+// GetActiveByHash mencari kunci yang belum dicabut dan mencatat pemakaiannya. last_used_at
+// paling sering diperbarui sekali per menit agar tidak menulis di setiap request.
+func (repo *APIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var k models.APIKey
+	err := scanAPIKey(repo.pool.QueryRow(ctx, `
+		SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1 AND tenant_id = $2 AND revoked_at IS NULL
+	`, keyHash, repo.tenantID), &k)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("api key")
+		}
+		return nil, err
+	}
+
+	_, err = repo.pool.Exec(ctx, `
+		UPDATE api_keys SET last_used_at = now()
+		WHERE id = $1 AND tenant_id = $2 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`, k.ID, repo.tenantID)
+	return &k, err
+}
+
+func (repo *APIKeyRepository) Create(ctx context.Context, k *models.APIKey) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	return repo.pool.QueryRow(ctx, `
+		INSERT INTO api_keys (tenant_id, name, prefix, scopes, rate_limit, key_hash, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, repo.tenantID, k.Name, k.Prefix, k.Scopes, k.RateLimit, k.KeyHash, k.CreatedBy).Scan(&k.ID, &k.CreatedAt)
+}
+
+func (repo *APIKeyRepository) Update(ctx context.Context, k *models.APIKey) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `
+		UPDATE api_keys SET name = $1, scopes = $2, rate_limit = $3 WHERE id = $4 AND tenant_id = $5
+	`, k.Name, k.Scopes, k.RateLimit, k.ID, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("api key")
+	}
+	return nil
+}
+
+// Rotate mengganti kunci; kunci lama langsung tidak berlaku.
+func (repo *APIKeyRepository) Rotate(ctx context.Context, id int, prefix, keyHash string) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `
+		UPDATE api_keys SET prefix = $1, key_hash = $2, last_used_at = NULL
+		WHERE id = $3 AND tenant_id = $4 AND revoked_at IS NULL
+	`, prefix, keyHash, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("active api key")
+	}
+	return nil
+}
+
+func (repo *APIKeyRepository) Revoke(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `
+		UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+	`, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("active api key")
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditRepository hanya menambah dan membaca audit_logs. Tabelnya juga dijaga trigger
+// yang menolak UPDATE/DELETE, jadi log tetap append-only walau diakses di luar API.
+type AuditRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewAuditRepository(pool *pgxpool.Pool, tenantID int) *AuditRepository {
+	return &AuditRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+const auditColumns = `id, actor_user_id, actor_api_key_id, actor_name, approved_by, action, entity, entity_id,
+	before, after, diff, source_ip, request_id, created_at`
+
+func scanAudit(row pgx.Row, a *models.AuditLog) error {
+	return row.Scan(&a.ID, &a.ActorUserID, &a.ActorAPIKeyID, &a.ActorName, &a.ApprovedBy, &a.Action, &a.Entity, &a.EntityID,
+		&a.Before, &a.After, &a.Diff, &a.SourceIP, &a.RequestID, &a.CreatedAt)
+}
+
+// Record menjalankan change lalu menulis audit log yang dikembalikannya dalam satu
+// transaksi database. Perubahan yang dilakukan change lewat repository Postgres mana pun
+// (dengan ctx yang diterimanya) ikut dibatalkan jika change atau penulisan audit gagal.
+func (repo *AuditRepository) Record(ctx context.Context, change func(ctx context.Context) (*models.AuditLog, error)) error {
+	return repo.pool.atomic(ctx, func(ctx context.Context) error {
+		a, err := change(ctx)
+		if err != nil {
+			return err
+		}
+		return repo.Create(ctx, a)
+	})
+}
+
+func (repo *AuditRepository) Create(ctx context.Context, a *models.AuditLog) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	return repo.pool.QueryRow(ctx, `
+		INSERT INTO audit_logs (tenant_id, actor_user_id, actor_api_key_id, actor_name, approved_by, action, entity, entity_id,
+		                        before, after, diff, source_ip, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at
+	`, repo.tenantID, a.ActorUserID, a.ActorAPIKeyID, a.ActorName, a.ApprovedBy, a.Action, a.Entity, a.EntityID,
+		a.Before, a.After, a.Diff, a.SourceIP, a.RequestID).Scan(&a.ID, &a.CreatedAt)
+}
+
+// GetAll mengembalikan log terbaru lebih dulu sesuai filter.
+func (repo *AuditRepository) GetAll(ctx context.Context, f models.AuditFilter) ([]models.AuditLog, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + auditColumns + ` FROM audit_logs WHERE tenant_id = $1`
+	args := []any{repo.tenantID}
+	where := func(cond string, v any) {
+		args = append(args, v)
+		query += fmt.Sprintf(" AND "+cond, len(args))
+	}
+	if f.Entity != "" {
+		where("entity = $%d", f.Entity)
+	}
+	if f.EntityID != nil {
+		where("entity_id = $%d", *f.EntityID)
+	}
+	if f.Action != "" {
+		where("action = $%d", f.Action)
+	}
+	if f.ActorUserID != nil {
+		where("actor_user_id = $%d", *f.ActorUserID)
+	}
+	if f.RequestID != "" {
+		where("request_id = $%d", f.RequestID)
+	}
+	if f.From != nil {
+		where("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		where("created_at < $%d", *f.To)
+	}
+	args = append(args, f.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := repo.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := make([]models.AuditLog, 0)
+	for rows.Next() {
+		var a models.AuditLog
+		if err := scanAudit(rows, &a); err != nil {
+			return nil, err
+		}
+		logs = append(logs, a)
+	}
+	return logs, rows.Err()
+}
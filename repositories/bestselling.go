@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"kasir-api/models"
+	"sort"
+)
+
+// SoldLine adalah penjualan satu produk (atau varian) dalam satu satuan jual, hasil
+// GROUP BY backend. BaseQuantity dalam satuan dasar produk (BaseUnit).
+type SoldLine struct {
+	ProductID    int
+	Name         string
+	BaseUnit     string
+	VariantID    *int
+	VariantName  string
+	Unit         string
+	Quantity     float64
+	BaseQuantity float64
+}
+
+// SoldModifier adalah pemakaian satu modifier pada barang dalam satu satuan jual; Lines
+// adalah jumlah baris transaksi yang memakainya.
+type SoldModifier struct {
+	ModifierID int
+	Name       string
+	Unit       string
+	Quantity   float64
+	Lines      int
+}
+
+// BestsellingProducts menggabungkan baris per produk (dan varian, jika VariantID diisi)
+// lalu mengambil limit teratas menurut jumlah dalam satuan dasar. Produk dibedakan menurut
+// ID, bukan nama, dan jumlah per satuan jual tidak dicampur.
+func BestsellingProducts(lines []SoldLine, limit int) []models.BestsellingProduct {
+	type key struct{ product, variant int }
+	index := map[key]int{}
+	best := make([]models.BestsellingProduct, 0)
+	for _, l := range lines {
+		k := key{product: l.ProductID}
+		if l.VariantID != nil {
+			k.variant = *l.VariantID
+		}
+		i, ok := index[k]
+		if !ok {
+			i = len(best)
+			index[k] = i
+			best = append(best, models.BestsellingProduct{
+				ProductID: l.ProductID, Name: l.Name, VariantID: l.VariantID, VariantName: l.VariantName,
+				Unit: l.BaseUnit, Units: make([]models.UnitQuantity, 0),
+			})
+		}
+		best[i].QtySold += l.BaseQuantity
+		best[i].Units = addUnit(best[i].Units, l.Unit, l.Quantity)
+	}
+	sort.SliceStable(best, func(i, j int) bool {
+		a, b := best[i], best[j]
+		if a.QtySold != b.QtySold {
+			return a.QtySold > b.QtySold
+		}
+		if a.ProductID != b.ProductID {
+			return a.ProductID < b.ProductID
+		}
+		return variantID(a.VariantID) < variantID(b.VariantID)
+	})
+	return best[:min(limit, len(best))]
+}
+
+// BestsellingModifiers menggabungkan baris per modifier lalu mengambil limit teratas
+// menurut jumlah baris transaksi yang memakainya.
+func BestsellingModifiers(lines []SoldModifier, limit int) []models.BestsellingModifier {
+	index := map[int]int{}
+	best := make([]models.BestsellingModifier, 0)
+	for _, l := range lines {
+		i, ok := index[l.ModifierID]
+		if !ok {
+			i = len(best)
+			index[l.ModifierID] = i
+			best = append(best, models.BestsellingModifier{ModifierID: l.ModifierID, Name: l.Name, Units: make([]models.UnitQuantity, 0)})
+		}
+		best[i].TimesOrdered += l.Lines
+		best[i].Units = addUnit(best[i].Units, l.Unit, l.Quantity)
+	}
+	sort.SliceStable(best, func(i, j int) bool {
+		if best[i].TimesOrdered != best[j].TimesOrdered {
+			return best[i].TimesOrdered > best[j].TimesOrdered
+		}
+		return best[i].ModifierID < best[j].ModifierID
+	})
+	return best[:min(limit, len(best))]
+}
+
+// addUnit menambah quantity ke satuan unit, dengan urutan satuan menurut nama.
+func addUnit(units []models.UnitQuantity, unit string, quantity float64) []models.UnitQuantity {
+	i := sort.Search(len(units), func(i int) bool { return units[i].Unit >= unit })
+	if i < len(units) && units[i].Unit == unit {
+		units[i].Quantity += quantity
+		return units
+	}
+	units = append(units, models.UnitQuantity{})
+	copy(units[i+1:], units[i:])
+	units[i] = models.UnitQuantity{Unit: unit, Quantity: quantity}
+	return units
+}
+
+func variantID(id *int) int {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
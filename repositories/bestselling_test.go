@@ -0,0 +1,48 @@
+package repositories_test
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"reflect"
+	"testing"
+)
+
+func TestBestsellingProducts(t *testing.T) {
+	small, large := 1, 2
+	lines := []repositories.SoldLine{
+		// Beras dijual per kg dan per karung (25 kg); nama sama dengan produk lain
+		{ProductID: 1, Name: "Beras", BaseUnit: "kg", Unit: "kg", Quantity: 10, BaseQuantity: 10},
+		{ProductID: 1, Name: "Beras", BaseUnit: "kg", Unit: "karung", Quantity: 2, BaseQuantity: 50},
+		{ProductID: 2, Name: "Beras", BaseUnit: "kg", Unit: "kg", Quantity: 40, BaseQuantity: 40},
+		{ProductID: 3, Name: "Kopi", BaseUnit: "cup", VariantID: &small, VariantName: "S", Unit: "cup", Quantity: 30, BaseQuantity: 30},
+		{ProductID: 3, Name: "Kopi", BaseUnit: "cup", VariantID: &large, VariantName: "L", Unit: "cup", Quantity: 45, BaseQuantity: 45},
+	}
+	got := repositories.BestsellingProducts(lines, 3)
+	want := []models.BestsellingProduct{
+		{ProductID: 1, Name: "Beras", Unit: "kg", QtySold: 60, Units: []models.UnitQuantity{{Unit: "karung", Quantity: 2}, {Unit: "kg", Quantity: 10}}},
+		{ProductID: 3, Name: "Kopi", VariantID: &large, VariantName: "L", Unit: "cup", QtySold: 45, Units: []models.UnitQuantity{{Unit: "cup", Quantity: 45}}},
+		{ProductID: 2, Name: "Beras", Unit: "kg", QtySold: 40, Units: []models.UnitQuantity{{Unit: "kg", Quantity: 40}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BestsellingProducts =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := repositories.BestsellingProducts(nil, 1); got == nil || len(got) != 0 {
+		t.Errorf("BestsellingProducts(nil) = %#v, want empty list", got)
+	}
+}
+
+func TestBestsellingModifiers(t *testing.T) {
+	lines := []repositories.SoldModifier{
+		{ModifierID: 7, Name: "Extra shot", Unit: "cup", Quantity: 3, Lines: 2},
+		{ModifierID: 9, Name: "Gula aren", Unit: "cup", Quantity: 1, Lines: 1},
+		{ModifierID: 9, Name: "Gula aren", Unit: "liter", Quantity: 2, Lines: 2},
+	}
+	got := repositories.BestsellingModifiers(lines, 5)
+	want := []models.BestsellingModifier{
+		{ModifierID: 9, Name: "Gula aren", TimesOrdered: 3, Units: []models.UnitQuantity{{Unit: "cup", Quantity: 1}, {Unit: "liter", Quantity: 2}}},
+		{ModifierID: 7, Name: "Extra shot", TimesOrdered: 2, Units: []models.UnitQuantity{{Unit: "cup", Quantity: 3}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BestsellingModifiers =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -4,26 +4,26 @@ import (
 	"context"
 	"errors"
 	"kasir-api/models"
-	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type CategoryRepository struct {
-	pool *pgxpool.Pool
+	pool     pgDB
+	tenantID int
 }
 
-func NewCategoryRepository(pool *pgxpool.Pool) *CategoryRepository {
-	return &CategoryRepository{pool: pool}
+func NewCategoryRepository(pool *pgxpool.Pool, tenantID int) *CategoryRepository {
+	return &CategoryRepository{pool: pgDB{pool}, tenantID: tenantID}
 }
 
-func (r *CategoryRepository) GetAllCategories() ([]models.Category, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *CategoryRepository) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
-	const q = `SELECT id, name, description FROM categories ORDER BY id`
-	rows, err := r.pool.Query(ctx, q)
+	const q = `SELECT id, name, description FROM categories WHERE tenant_id = $1 ORDER BY id`
+	rows, err := r.pool.Query(ctx, q, r.tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -40,55 +40,55 @@ func (r *CategoryRepository) GetAllCategories() ([]models.Category, error) {
 	return items, rows.Err()
 }
 
-func (r *CategoryRepository) CreateCategory(c *models.Category) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *CategoryRepository) CreateCategory(ctx context.Context, c *models.Category) error {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
-	const q = `INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING id`
-	return r.pool.QueryRow(ctx, q, c.Name, c.Description).Scan(&c.ID)
+	const q = `INSERT INTO categories (tenant_id, name, description) VALUES ($1, $2, $3) RETURNING id`
+	return r.pool.QueryRow(ctx, q, r.tenantID, c.Name, c.Description).Scan(&c.ID)
 }
 
-func (r *CategoryRepository) GetCategoryByID(id int) (*models.Category, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *CategoryRepository) GetCategoryByID(ctx context.Context, id int) (*models.Category, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
-	const q = `SELECT id, name, description FROM categories WHERE id = $1`
+	const q = `SELECT id, name, description FROM categories WHERE id = $1 AND tenant_id = $2`
 	var c models.Category
-	if err := r.pool.QueryRow(ctx, q, id).Scan(&c.ID, &c.Name, &c.Description); err != nil {
+	if err := r.pool.QueryRow(ctx, q, id, r.tenantID).Scan(&c.ID, &c.Name, &c.Description); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("category not found")
+			return nil, models.NotFound("category")
 		}
 		return nil, err
 	}
 	return &c, nil
 }
 
-func (r *CategoryRepository) UpdateCategory(c *models.Category) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *CategoryRepository) UpdateCategory(ctx context.Context, c *models.Category) error {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
-	const q = `UPDATE categories SET name = $1, description = $2 WHERE id = $3`
-	ct, err := r.pool.Exec(ctx, q, c.Name, c.Description, c.ID)
+	const q = `UPDATE categories SET name = $1, description = $2 WHERE id = $3 AND tenant_id = $4`
+	ct, err := r.pool.Exec(ctx, q, c.Name, c.Description, c.ID, r.tenantID)
 	if err != nil {
 		return err
 	}
 	if ct.RowsAffected() == 0 {
-		return errors.New("category not found")
+		return models.NotFound("category")
 	}
 	return nil
 }
 
-func (r *CategoryRepository) DeleteCategory(id int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *CategoryRepository) DeleteCategory(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
-	const q = `DELETE FROM categories WHERE id = $1`
-	ct, err := r.pool.Exec(ctx, q, id)
+	const q = `DELETE FROM categories WHERE id = $1 AND tenant_id = $2`
+	ct, err := r.pool.Exec(ctx, q, id, r.tenantID)
 	if err != nil {
 		return err
 	}
 	if ct.RowsAffected() == 0 {
-		return errors.New("category not found")
+		return models.NotFound("category")
 	}
 	return nil
 }
@@ -0,0 +1,470 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/money"
+	"math"
+	"time"
+)
+
+// CheckoutTx adalah baca/tulis yang dibutuhkan Checkout dan Refund di dalam satu
+// transaksi backend (Postgres, SQLite, memori). Aturan harga, stok, loyalti, dan kasbon
+// hanya ada di Checkout dan Refund, jadi semua backend menghitung transaksi dengan cara
+// yang sama; backend cukup membaca, mengunci, dan menulis baris.
+type CheckoutTx interface {
+	LoyaltyTx
+
+	// LockProducts mengunci produk di keranjang, urut id, sebelum stok dibaca.
+	LockProducts(ctx context.Context, ids []int) error
+	// Product mengembalikan produk dengan stok terkini (termasuk perubahan di transaksi ini).
+	Product(ctx context.Context, id int) (*models.Product, error)
+	// Variant mengembalikan varian variantID milik productID beserta stok terkininya.
+	Variant(ctx context.Context, productID, variantID int) (*models.ProductVariant, error)
+	// SellUnit mengembalikan satuan alternatif yang boleh dijual untuk produk.
+	SellUnit(ctx context.Context, productID int, name string) (*models.ProductUnit, error)
+	// ModifierGroups mengembalikan grup modifier yang berlaku untuk produk (langsung atau lewat kategorinya).
+	ModifierGroups(ctx context.Context, productID int) ([]models.ModifierGroup, error)
+	// ListPrice mencari harga per satuan dasar di daftar harga: harga khusus varian
+	// didahulukan dari harga produk, lalu tingkat min_quantity tertinggi yang sudah
+	// tercapai. found false berarti produk tidak ada di daftar.
+	ListPrice(ctx context.Context, priceListID, productID int, variantID *int, baseQuantity float64) (price money.Amount, found bool, err error)
+	LoyaltyRules(ctx context.Context) (*models.LoyaltyRules, error)
+	// OutstandingCredit menjumlahkan sisa piutang yang belum lunas milik pelanggan.
+	OutstandingCredit(ctx context.Context, customerID int) (money.Amount, error)
+
+	// AdjustStock menambah (delta positif) atau mengurangi stok varian, atau produk jika variantID nil.
+	AdjustStock(ctx context.Context, productID int, variantID *int, delta float64) error
+	// InsertTransaction menyimpan kepala transaksi dan mengisi ID serta CreatedAt.
+	InsertTransaction(ctx context.Context, t *models.Transaction) error
+	// InsertDetail menyimpan satu baris beserta salinan modifiernya dan mengisi ID.
+	InsertDetail(ctx context.Context, d *models.TransactionDetail) error
+	InsertReceivable(ctx context.Context, r *models.Receivable) error
+
+	// LockTransaction mengunci transaksi untuk refund dan mengembalikannya beserta
+	// baris-barisnya (minimal produk, varian, dan kuantitas dasar).
+	LockTransaction(ctx context.Context, id int) (*models.Transaction, error)
+	MarkRefunded(ctx context.Context, id int, refundedBy *int) error
+	// VoidReceivables menghapus sisa piutang transaksi; cicilan yang sudah masuk tetap tercatat.
+	VoidReceivables(ctx context.Context, transactionID int) error
+}
+
+// LoyaltyTx adalah buku poin pelanggan di dalam satu transaksi backend.
+type LoyaltyTx interface {
+	// LockCustomer mengunci pelanggan sampai transaksi selesai, agar penukaran,
+	// kedaluwarsa poin, dan kasbon pelanggan yang sama tidak berjalan bersamaan.
+	LockCustomer(ctx context.Context, customerID int) (*models.Customer, error)
+	// LoyaltyLedger mengembalikan buku poin pelanggan urut waktu catat.
+	LoyaltyLedger(ctx context.Context, customerID int) ([]models.LoyaltyEntry, error)
+	// InsertLedger menyimpan entri dan mengisi ID serta CreatedAt.
+	InsertLedger(ctx context.Context, e *models.LoyaltyEntry) error
+}
+
+// Checkout menghitung dan menyimpan checkout di dalam tx: harga (varian, satuan jual,
+// modifier, daftar harga, ubah harga, barcode timbangan), diskon persen, penukaran dan
+// perolehan poin, pajak, pembulatan tunai, kasbon, lalu pengurangan stok. Pemanggil yang
+// membuka dan meng-commit tx; error apa pun berarti tx harus di-rollback.
+func Checkout(ctx context.Context, tx CheckoutTx, req *models.CheckoutRequest) (*models.Transaction, error) {
+	// Kunci produk di keranjang sebelum stok dibaca, supaya checkout paralel atas produk
+	// yang sama menunggu dan membaca stok terbaru alih-alih sama-sama lolos cek stok.
+	// Urutan id yang tetap mencegah deadlock antara dua keranjang; stok varian ikut
+	// terlindungi karena setiap checkout varian mengunci produk induknya dulu.
+	productIDs := make([]int, len(req.Items))
+	for i, item := range req.Items {
+		productIDs[i] = item.ProductID
+	}
+	if err := tx.LockProducts(ctx, productIDs); err != nil {
+		return nil, err
+	}
+
+	var grossAmount money.Amount
+	cfg := money.Current()
+	details := make([]models.TransactionDetail, 0, len(req.Items))
+	categories := make([]*int, 0, len(req.Items)) // kategori tiap baris, untuk multiplier poin
+
+	for _, item := range req.Items {
+		product, err := tx.Product(ctx, item.ProductID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, models.NotFound(fmt.Sprintf("product %d", item.ProductID))
+			}
+			return nil, err
+		}
+		productPrice, stock := product.Price, product.Stock
+
+		// produk bervarian: harga & stok diambil dari varian, bukan dari produk induk
+		var variantName string
+		if item.VariantID != nil {
+			v, err := tx.Variant(ctx, item.ProductID, *item.VariantID)
+			if err != nil {
+				if errors.Is(err, models.ErrNotFound) {
+					return nil, models.NotFound(fmt.Sprintf("variant %d of product %d", *item.VariantID, item.ProductID))
+				}
+				return nil, err
+			}
+			variantName, productPrice, stock = v.Name, v.Price, v.Stock
+		} else if len(product.OptionAxes) > 0 {
+			return nil, models.Validationf("variant_id is required for product with variants")
+		}
+
+		// satuan jual: default satuan dasar produk, atau satuan alternatif (mis. karton)
+		unit := product.Unit
+		factor := 1.0
+		var unitPriceOverride *money.Amount
+		if item.Unit != "" && item.Unit != product.Unit {
+			u, err := tx.SellUnit(ctx, item.ProductID, item.Unit)
+			if err != nil {
+				if errors.Is(err, models.ErrNotFound) {
+					return nil, models.Validationf("unit %q is not sold for product %d", item.Unit, item.ProductID)
+				}
+				return nil, err
+			}
+			unit, factor, unitPriceOverride = u.Name, u.Factor, u.Price
+		}
+
+		if item.Quantity <= 0 {
+			return nil, models.Validationf("quantity must be greater than zero")
+		}
+		if !product.DecimalQty && item.Quantity != math.Trunc(item.Quantity) {
+			return nil, models.Validationf("product %d can only be sold in whole %s", item.ProductID, unit)
+		}
+		baseQuantity := models.RoundQuantity(item.Quantity * factor)
+
+		if stock < baseQuantity {
+			return nil, &models.InsufficientStockError{ProductID: item.ProductID, VariantID: item.VariantID, Available: stock, Requested: baseQuantity}
+		}
+
+		groups, err := tx.ModifierGroups(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		modifiers, modifierDelta, err := selectModifiers(groups, item.ModifierIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		// daftar harga (member/grosir): harga per satuan dasar sesuai tingkat kuantitas,
+		// menggantikan harga dasar maupun harga satuan alternatif
+		var priceListID *int
+		if req.PriceListID != nil {
+			price, found, err := tx.ListPrice(ctx, *req.PriceListID, item.ProductID, item.VariantID, baseQuantity)
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				productPrice, unitPriceOverride, priceListID = price, nil, req.PriceListID
+			}
+		}
+
+		sellPrice, err := productPrice.MulQuantity(factor, cfg.Mode)
+		if err != nil {
+			return nil, err
+		}
+		if unitPriceOverride != nil {
+			sellPrice = *unitPriceOverride
+		}
+		unitPrice, err := sellPrice.Add(modifierDelta)
+		if err != nil {
+			return nil, err
+		}
+		if item.PriceOverride != nil {
+			unitPrice = *item.PriceOverride
+		}
+		subtotal, err := unitPrice.MulQuantity(item.Quantity, cfg.Mode)
+		if err != nil {
+			return nil, err
+		}
+		if item.EmbeddedPrice != nil {
+			// barcode timbangan berisi harga: harga di label adalah harga final
+			subtotal = *item.EmbeddedPrice
+		}
+		if grossAmount, err = grossAmount.Add(subtotal); err != nil {
+			return nil, err
+		}
+
+		if err := tx.AdjustStock(ctx, item.ProductID, item.VariantID, -baseQuantity); err != nil {
+			return nil, err
+		}
+
+		details = append(details, models.TransactionDetail{
+			ProductID:     item.ProductID,
+			ProductName:   product.Name,
+			VariantID:     item.VariantID,
+			VariantName:   variantName,
+			Quantity:      item.Quantity,
+			Unit:          unit,
+			BaseQuantity:  baseQuantity,
+			PriceListID:   priceListID,
+			UnitPrice:     unitPrice,
+			PriceOverride: item.PriceOverride != nil,
+			Subtotal:      subtotal,
+			Modifiers:     modifiers,
+		})
+		categories = append(categories, product.CategoryID)
+	}
+
+	// diskon manual (persen) atas subtotal, dihitung sebelum penukaran poin
+	discount, err := grossAmount.Percent(int64(math.Round(req.DiscountPercent*100)), cfg.Mode)
+	if err != nil {
+		return nil, err
+	}
+	discountedAmount, err := grossAmount.Sub(discount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Loyalti: tukar poin sebagai potongan, lalu hitung poin yang didapat dari sisa pembayaran
+	rules, err := tx.LoyaltyRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var loyaltyDiscount money.Amount
+	if req.RedeemPoints > 0 {
+		if req.CustomerID == nil {
+			return nil, models.Validationf("redeeming points requires a customer")
+		}
+		loyaltyDiscount, err = redeemPoints(ctx, tx, rules, *req.CustomerID, req.RedeemPoints, discountedAmount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pointsEarned := 0
+	if req.CustomerID != nil {
+		lines := make([]money.Amount, len(details))
+		for i := range details {
+			lines[i] = details[i].Subtotal
+		}
+		pointsEarned = earnedPoints(rules, lines, categories, grossAmount, discount+loyaltyDiscount)
+	}
+
+	netAmount, err := discountedAmount.Sub(loyaltyDiscount)
+	if err != nil {
+		return nil, err
+	}
+
+	// pajak atas total setelah diskon & potongan poin, dibulatkan dengan aturan yang sama
+	// seperti diskon persen
+	tax, err := cfg.Tax(netAmount)
+	if err != nil {
+		return nil, err
+	}
+	taxedAmount, err := netAmount.Add(tax)
+	if err != nil {
+		return nil, err
+	}
+
+	// pembulatan total tunai (mis. ke Rp100), selisihnya dicatat terpisah;
+	// pembayaran non-tunai dibayar persis sesuai nominal
+	roundedTotal := taxedAmount
+	if req.PaymentMethod == models.PaymentCash {
+		if roundedTotal, err = taxedAmount.RoundTo(cfg.CashRounding, cfg.Mode); err != nil {
+			return nil, err
+		}
+	}
+
+	// kasbon: total masuk piutang pelanggan selama tidak melewati batas kreditnya
+	if req.PaymentMethod == models.PaymentPayLater {
+		if req.CustomerID == nil {
+			return nil, models.Validationf("pay later requires a customer")
+		}
+		if err := checkCreditLimit(ctx, tx, *req.CustomerID, roundedTotal); err != nil {
+			return nil, err
+		}
+	}
+
+	t := &models.Transaction{
+		Status:             models.TransactionCompleted,
+		Subtotal:           grossAmount,
+		DiscountPercent:    req.DiscountPercent,
+		Discount:           discount,
+		LoyaltyDiscount:    loyaltyDiscount,
+		Tax:                tax,
+		TotalAmount:        money.Money{Amount: roundedTotal, Currency: cfg.Currency},
+		RoundingAdjustment: roundedTotal - taxedAmount,
+		PaymentMethod:      req.PaymentMethod,
+		CustomerID:         req.CustomerID,
+		PointsEarned:       pointsEarned,
+		PointsRedeemed:     req.RedeemPoints,
+		CashierID:          req.CashierID,
+		TerminalID:         req.TerminalID,
+		ApprovedBy:         req.ApprovedBy,
+	}
+	if err := tx.InsertTransaction(ctx, t); err != nil {
+		return nil, err
+	}
+
+	if req.PaymentMethod == models.PaymentPayLater && roundedTotal > 0 {
+		err := tx.InsertReceivable(ctx, &models.Receivable{
+			CustomerID:    *req.CustomerID,
+			TransactionID: t.ID,
+			Amount:        roundedTotal,
+			Status:        models.ReceivableOpen,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Buku poin: penukaran dan perolehan poin dicatat terpisah agar bisa dibalik saat refund
+	if req.RedeemPoints > 0 {
+		err := tx.InsertLedger(ctx, &models.LoyaltyEntry{
+			CustomerID:    *req.CustomerID,
+			TransactionID: &t.ID,
+			Kind:          models.LoyaltyRedeem,
+			Points:        -req.RedeemPoints,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if pointsEarned > 0 {
+		err := tx.InsertLedger(ctx, &models.LoyaltyEntry{
+			CustomerID:    *req.CustomerID,
+			TransactionID: &t.ID,
+			Kind:          models.LoyaltyEarn,
+			Points:        pointsEarned,
+			ExpiresAt:     pointsExpiry(rules),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range details {
+		details[i].TransactionID = t.ID
+		if err := tx.InsertDetail(ctx, &details[i]); err != nil {
+			return nil, err
+		}
+	}
+	t.Details = details
+	return t, nil
+}
+
+// Refund membatalkan seluruh transaksi di dalam tx: stok dikembalikan, status menjadi
+// refunded, poin loyalti dibalik (poin yang didapat ditarik, poin yang ditukar
+// dikembalikan), dan sisa kasbonnya dihapus. refundedBy adalah user yang menyetujui.
+func Refund(ctx context.Context, tx CheckoutTx, id int, refundedBy *int) error {
+	t, err := tx.LockTransaction(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.Status == models.TransactionRefunded {
+		return models.Conflictf("transaction already refunded")
+	}
+
+	// Kembalikan stok ke varian atau produk induk
+	for _, d := range t.Details {
+		if err := tx.AdjustStock(ctx, d.ProductID, d.VariantID, d.BaseQuantity); err != nil {
+			return err
+		}
+	}
+	if err := tx.MarkRefunded(ctx, id, refundedBy); err != nil {
+		return err
+	}
+	if err := tx.VoidReceivables(ctx, id); err != nil {
+		return err
+	}
+
+	if t.CustomerID == nil || (t.PointsEarned == 0 && t.PointsRedeemed == 0) {
+		return nil
+	}
+	if _, err := tx.LockCustomer(ctx, *t.CustomerID); err != nil {
+		return err
+	}
+	rules, err := tx.LoyaltyRules(ctx)
+	if err != nil {
+		return err
+	}
+	// Poin yang didapat ditarik walau sudah terpakai (saldo boleh minus),
+	// poin yang ditukar dikembalikan dengan masa berlaku baru.
+	if t.PointsEarned > 0 {
+		err := tx.InsertLedger(ctx, &models.LoyaltyEntry{
+			CustomerID: *t.CustomerID, TransactionID: &id, Kind: models.LoyaltyReversal, Points: -t.PointsEarned,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if t.PointsRedeemed > 0 {
+		err := tx.InsertLedger(ctx, &models.LoyaltyEntry{
+			CustomerID: *t.CustomerID, TransactionID: &id, Kind: models.LoyaltyReversal, Points: t.PointsRedeemed,
+			ExpiresAt: pointsExpiry(rules),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExpirePoints mencatat entri "expire" untuk poin pelanggan yang sudah lewat masa
+// berlakunya (lihat ExpiredPoints), lalu mengembalikan saldo poin dan buku poinnya
+// (urut waktu catat, termasuk entri expire yang baru). Pelanggan harus sudah dikunci.
+func ExpirePoints(ctx context.Context, tx LoyaltyTx, customerID int) (int, []models.LoyaltyEntry, error) {
+	ledger, err := tx.LoyaltyLedger(ctx, customerID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if expired := ExpiredPoints(ledger, time.Now()); expired > 0 {
+		e := models.LoyaltyEntry{CustomerID: customerID, Kind: models.LoyaltyExpire, Points: -expired}
+		if err := tx.InsertLedger(ctx, &e); err != nil {
+			return 0, nil, err
+		}
+		ledger = append(ledger, e)
+	}
+	balance := 0
+	for _, e := range ledger {
+		balance += e.Points
+	}
+	return balance, ledger, nil
+}
+
+// redeemPoints memeriksa saldo lalu menghitung potongan harga dari poin yang ditukar.
+func redeemPoints(ctx context.Context, tx CheckoutTx, rules *models.LoyaltyRules, customerID, points int, total money.Amount) (money.Amount, error) {
+	if rules.PointValue <= 0 {
+		return 0, models.Validationf("loyalty point redemption is not enabled")
+	}
+	if _, err := tx.LockCustomer(ctx, customerID); err != nil {
+		return 0, err
+	}
+	balance, _, err := ExpirePoints(ctx, tx, customerID)
+	if err != nil {
+		return 0, err
+	}
+	if balance < points {
+		return 0, models.Conflictf("insufficient loyalty points: balance %d, requested %d", balance, points)
+	}
+
+	discount, err := rules.PointValue.Mul(int64(points))
+	if err != nil {
+		return 0, err
+	}
+	if discount > total {
+		return 0, models.Validationf("redeemed points worth %d exceed transaction total %d", discount, total)
+	}
+	return discount, nil
+}
+
+// checkCreditLimit mengunci pelanggan lalu memastikan kasbon baru sebesar amount tidak
+// membuat total piutangnya melewati batas kredit. Penguncian mencegah dua checkout
+// bersamaan sama-sama lolos pengecekan.
+func checkCreditLimit(ctx context.Context, tx CheckoutTx, customerID int, amount money.Amount) error {
+	customer, err := tx.LockCustomer(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	outstanding, err := tx.OutstandingCredit(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	total, err := outstanding.Add(amount)
+	if err != nil {
+		return err
+	}
+	if total > customer.CreditLimit {
+		return models.Conflictf("credit limit exceeded: limit %d, outstanding %d, this sale %d", customer.CreditLimit, outstanding, amount)
+	}
+	return nil
+}
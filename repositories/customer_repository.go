@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CustomerRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewCustomerRepository(pool *pgxpool.Pool, tenantID int) *CustomerRepository {
+	return &CustomerRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+const customerColumns = `id, name, COALESCE(phone, ''), COALESCE(email, ''), notes, price_list_id, credit_limit, created_at`
+
+func scanCustomer(row pgx.Row, c *models.Customer) error {
+	return row.Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Notes, &c.PriceListID, &c.CreditLimit, &c.CreatedAt)
+}
+
+// GetAll mengembalikan pelanggan, difilter berdasarkan nama/HP/email jika search diisi.
+func (repo *CustomerRepository) GetAll(ctx context.Context, search string) ([]models.Customer, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + customerColumns + ` FROM customers WHERE tenant_id = $1`
+	args := []any{repo.tenantID}
+	if search != "" {
+		query += ` AND (name ILIKE $2 OR phone ILIKE $2 OR email ILIKE $2)`
+		args = append(args, "%"+search+"%")
+	}
+	query += ` ORDER BY name, id`
+
+	rows, err := repo.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := make([]models.Customer, 0)
+	for rows.Next() {
+		var c models.Customer
+		if err := scanCustomer(rows, &c); err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	return customers, rows.Err()
+}
+
+func (repo *CustomerRepository) GetByID(ctx context.Context, id int) (*models.Customer, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var c models.Customer
+	if err := scanCustomer(repo.pool.QueryRow(ctx, `SELECT `+customerColumns+` FROM customers WHERE id = $1 AND tenant_id = $2`, id, repo.tenantID), &c); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("customer")
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (repo *CustomerRepository) GetByPhone(ctx context.Context, phone string) (*models.Customer, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var c models.Customer
+	if err := scanCustomer(repo.pool.QueryRow(ctx, `SELECT `+customerColumns+` FROM customers WHERE phone = $1 AND tenant_id = $2`, phone, repo.tenantID), &c); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("customer")
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (repo *CustomerRepository) Create(ctx context.Context, c *models.Customer) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `
+		INSERT INTO customers (tenant_id, name, phone, email, notes, price_list_id, credit_limit)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7)
+		RETURNING id, created_at`
+	return repo.pool.QueryRow(ctx, query, repo.tenantID, c.Name, c.Phone, c.Email, c.Notes, c.PriceListID, c.CreditLimit).Scan(&c.ID, &c.CreatedAt)
+}
+
+func (repo *CustomerRepository) Update(ctx context.Context, c *models.Customer) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `UPDATE customers
+				   SET name = $1, phone = NULLIF($2, ''), email = NULLIF($3, ''), notes = $4, price_list_id = $5,
+				       credit_limit = $6
+				   WHERE id = $7 AND tenant_id = $8`
+	ct, err := repo.pool.Exec(ctx, query, c.Name, c.Phone, c.Email, c.Notes, c.PriceListID, c.CreditLimit, c.ID, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("customer")
+	}
+	return nil
+}
+
+func (repo *CustomerRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `DELETE FROM customers WHERE id = $1 AND tenant_id = $2`, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("customer")
+	}
+	return nil
+}
+
+func (repo *CustomerRepository) GetSummary(ctx context.Context, id int) (*models.CustomerSummary, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	summary := models.CustomerSummary{CustomerID: id}
+	err := repo.pool.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(total_amount), 0), MIN(created_at), MAX(created_at)
+		FROM transactions
+		WHERE customer_id = $1 AND tenant_id = $2 AND status = 'completed'
+	`, id, repo.tenantID).Scan(&summary.TotalTransactions, &summary.LifetimeValue.Amount, &summary.FirstPurchaseAt, &summary.LastPurchaseAt)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
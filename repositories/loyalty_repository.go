@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LoyaltyRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewLoyaltyRepository(pool *pgxpool.Pool, tenantID int) *LoyaltyRepository {
+	return &LoyaltyRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+func (repo *LoyaltyRepository) GetRules(ctx context.Context) (*models.LoyaltyRules, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	return loadLoyaltyRules(ctx, repo.pool, repo.tenantID)
+}
+
+func (repo *LoyaltyRepository) UpdateRules(ctx context.Context, rules *models.LoyaltyRules) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO loyalty_rules (tenant_id, spend_per_point, point_value, expiry_days)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET spend_per_point = EXCLUDED.spend_per_point,
+		    point_value = EXCLUDED.point_value,
+		    expiry_days = EXCLUDED.expiry_days
+	`, repo.tenantID, rules.SpendPerPoint, rules.PointValue, rules.ExpiryDays)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM loyalty_category_multipliers WHERE tenant_id = $1`, repo.tenantID); err != nil {
+		return err
+	}
+	for _, m := range rules.CategoryMultipliers {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO loyalty_category_multipliers (tenant_id, category_id, multiplier) VALUES ($1, $2, $3)
+		`, repo.tenantID, m.CategoryID, m.Multiplier)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetBalance menjalankan kedaluwarsa poin yang sudah jatuh tempo lalu mengembalikan
+// saldo poin pelanggan beserta buku poinnya (terbaru lebih dulu). Karena bisa mencatat
+// entri "expire", batas waktunya batas waktu tulis.
+func (repo *LoyaltyRepository) GetBalance(ctx context.Context, customerID int) (*models.LoyaltyBalance, error) {
+	ctx, cancel := WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	ltx := checkoutTx{tx, repo.tenantID}
+	if _, err := ltx.LockCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	points, entries, err := ExpirePoints(ctx, ltx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(entries)
+
+	rules, err := loadLoyaltyRules(ctx, tx, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	value, err := rules.PointValue.Mul(int64(points))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &models.LoyaltyBalance{CustomerID: customerID, Points: points, Value: value, Entries: entries}, nil
+}
+
+// rowQuerier dipenuhi oleh *pgxpool.Pool maupun pgx.Tx.
+type rowQuerier interface {
+	querier
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func loadLoyaltyRules(ctx context.Context, q rowQuerier, tenantID int) (*models.LoyaltyRules, error) {
+	rules := models.LoyaltyRules{CategoryMultipliers: make([]models.CategoryMultiplier, 0)}
+	err := q.QueryRow(ctx, `
+		SELECT spend_per_point, point_value, expiry_days FROM loyalty_rules WHERE tenant_id = $1
+	`, tenantID).Scan(&rules.SpendPerPoint, &rules.PointValue, &rules.ExpiryDays)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT category_id, multiplier FROM loyalty_category_multipliers WHERE tenant_id = $1 ORDER BY category_id
+	`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m models.CategoryMultiplier
+		if err := rows.Scan(&m.CategoryID, &m.Multiplier); err != nil {
+			return nil, err
+		}
+		rules.CategoryMultipliers = append(rules.CategoryMultipliers, m)
+	}
+	return &rules, rows.Err()
+}
+
+// ExpiredPoints menghitung poin yang sudah jatuh tempo dan belum dicatat kedaluwarsa,
+// dari buku poin satu pelanggan (urut waktu catat). Poin masuk dipakai FIFO menurut
+// tanggal kedaluwarsa: penukaran dan kedaluwarsa sebelumnya menghabiskan poin yang paling
+// cepat kedaluwarsa lebih dulu. Reversal negatif (poin ditarik saat refund) bukan
+// pemakaian; ia mengurangi poin earn dari transaksinya sendiri, sehingga refund transaksi
+// baru tidak ikut menghabiskan poin lama yang seharusnya kedaluwarsa.
+func ExpiredPoints(ledger []models.LoyaltyEntry, now time.Time) int {
+	type lot struct {
+		points    int
+		expiresAt *time.Time
+	}
+	reversed := map[int]int{}
+	for _, e := range ledger {
+		if e.Kind == models.LoyaltyReversal && e.Points < 0 && e.TransactionID != nil {
+			reversed[*e.TransactionID] -= e.Points
+		}
+	}
+
+	var lots []lot
+	used := 0
+	for _, e := range ledger {
+		switch {
+		case e.Points > 0:
+			points := e.Points
+			if e.Kind == models.LoyaltyEarn && e.TransactionID != nil {
+				taken := min(points, reversed[*e.TransactionID])
+				reversed[*e.TransactionID] -= taken
+				points -= taken
+			}
+			lots = append(lots, lot{points: points, expiresAt: e.ExpiresAt})
+		case e.Kind == models.LoyaltyRedeem, e.Kind == models.LoyaltyExpire:
+			used -= e.Points
+		}
+	}
+	// poin tanpa masa berlaku dipakai paling akhir
+	sort.SliceStable(lots, func(i, j int) bool {
+		a, b := lots[i].expiresAt, lots[j].expiresAt
+		return a != nil && (b == nil || a.Before(*b))
+	})
+
+	expired := 0
+	for _, l := range lots {
+		taken := min(l.points, used)
+		used -= taken
+		if l.expiresAt != nil && !l.expiresAt.After(now) {
+			expired += l.points - taken
+		}
+	}
+	return expired
+}
+
+// pointsExpiry menghitung tanggal kedaluwarsa poin yang diterima sekarang (nil = tidak kedaluwarsa).
+func pointsExpiry(rules *models.LoyaltyRules) *time.Time {
+	if rules.ExpiryDays <= 0 {
+		return nil
+	}
+	t := time.Now().AddDate(0, 0, rules.ExpiryDays)
+	return &t
+}
+
+// earnedPoints menghitung poin dari belanja: tiap baris dikali multiplier kategorinya,
+// lalu diperkecil sebanding dengan bagian yang dibayar pakai poin (poin tidak menghasilkan poin).
+func earnedPoints(rules *models.LoyaltyRules, lines []money.Amount, categories []*int, subtotal, discount money.Amount) int {
+	if rules.SpendPerPoint <= 0 || subtotal <= 0 {
+		return 0
+	}
+	multipliers := map[int]float64{}
+	for _, m := range rules.CategoryMultipliers {
+		multipliers[m.CategoryID] = m.Multiplier
+	}
+
+	weighted := 0.0
+	for i, line := range lines {
+		multiplier := 1.0
+		if categories[i] != nil {
+			if m, ok := multipliers[*categories[i]]; ok {
+				multiplier = m
+			}
+		}
+		weighted += float64(line) * multiplier
+	}
+	paidShare := float64(subtotal-discount) / float64(subtotal)
+	return int(math.Floor(weighted * paidShare / float64(rules.SpendPerPoint)))
+}
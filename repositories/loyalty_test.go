@@ -0,0 +1,65 @@
+package repositories_test
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"testing"
+	"time"
+)
+
+func TestExpiredPoints(t *testing.T) {
+	now := time.Date(2026, time.February, 15, 12, 0, 0, 0, time.UTC)
+	january := time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC)
+	sale1, sale2, sale3 := 1, 2, 3
+	earn := func(tx *int, points int, expires *time.Time) models.LoyaltyEntry {
+		return models.LoyaltyEntry{TransactionID: tx, Kind: models.LoyaltyEarn, Points: points, ExpiresAt: expires}
+	}
+	entry := func(kind string, tx *int, points int) models.LoyaltyEntry {
+		return models.LoyaltyEntry{TransactionID: tx, Kind: kind, Points: points}
+	}
+
+	tests := []struct {
+		name   string
+		ledger []models.LoyaltyEntry
+		want   int
+	}{
+		{"nothing due", []models.LoyaltyEntry{earn(&sale1, 100, &march)}, 0},
+		{"no expiry", []models.LoyaltyEntry{earn(&sale1, 100, nil)}, 0},
+		{"due", []models.LoyaltyEntry{earn(&sale1, 100, &january), earn(&sale2, 50, &march)}, 100},
+		// refund transaksi kedua tidak boleh menghabiskan poin Januari
+		{"refund of a later sale", []models.LoyaltyEntry{
+			earn(&sale1, 100, &january), earn(&sale2, 50, &march),
+			entry(models.LoyaltyReversal, &sale2, -50),
+		}, 100},
+		{"refund of the due sale", []models.LoyaltyEntry{
+			earn(&sale1, 100, &january), earn(&sale2, 50, &march),
+			entry(models.LoyaltyReversal, &sale1, -100),
+		}, 0},
+		// penukaran memakai poin yang paling cepat kedaluwarsa lebih dulu
+		{"redeemed first", []models.LoyaltyEntry{
+			earn(&sale1, 100, &january), earn(&sale2, 50, &march),
+			entry(models.LoyaltyRedeem, &sale3, -30),
+		}, 70},
+		{"redeemed more than due", []models.LoyaltyEntry{
+			earn(&sale1, 100, &january), earn(&sale2, 50, &march),
+			entry(models.LoyaltyRedeem, &sale3, -120),
+		}, 0},
+		// poin yang dikembalikan saat refund penukaran adalah poin masuk baru
+		{"restored redemption", []models.LoyaltyEntry{
+			earn(&sale2, 50, &march),
+			{TransactionID: &sale3, Kind: models.LoyaltyReversal, Points: 40, ExpiresAt: &january},
+		}, 40},
+		{"already expired", []models.LoyaltyEntry{
+			earn(&sale1, 100, &january), earn(&sale2, 50, &march),
+			entry(models.LoyaltyExpire, nil, -100),
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repositories.ExpiredPoints(tt.ledger, now); got != tt.want {
+				t.Errorf("ExpiredPoints = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
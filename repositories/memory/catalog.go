@@ -0,0 +1,211 @@
+package memory
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type ProductRepository struct {
+	s *Store
+}
+
+type CategoryRepository struct {
+	s *Store
+}
+
+var (
+	_ repositories.ProductStore  = (*ProductRepository)(nil)
+	_ repositories.CategoryStore = (*CategoryRepository)(nil)
+)
+
+// GetAll mengembalikan kolom ringkas seperti versi Postgres (tanpa kategori, PLU, varian).
+func (repo *ProductRepository) GetAll(ctx context.Context, nameFilter string) ([]models.Product, error) {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	filter := strings.ToLower(nameFilter)
+	products := make([]models.Product, 0)
+	for _, p := range repo.s.products {
+		if filter != "" && !strings.Contains(strings.ToLower(p.Name), filter) {
+			continue
+		}
+		products = append(products, models.Product{
+			ID: p.ID, Name: p.Name, Price: p.Price, Stock: p.Stock, Unit: p.Unit, DecimalQty: p.DecimalQty,
+		})
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
+
+func (repo *ProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	return repo.s.product(id)
+}
+
+func (repo *ProductRepository) GetByPLU(ctx context.Context, plu string) (*models.Product, error) {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	for _, p := range repo.s.products {
+		if plu != "" && p.PLU == plu {
+			return repo.s.product(p.ID)
+		}
+	}
+	return nil, models.NotFound("product")
+}
+
+func (repo *ProductRepository) Create(ctx context.Context, product *models.Product) error {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	if err := repo.s.checkProduct(product); err != nil {
+		return err
+	}
+	product.ID = repo.s.nextID("products")
+	repo.s.products[product.ID] = cloneProduct(product)
+	return nil
+}
+
+func (repo *ProductRepository) Update(ctx context.Context, product *models.Product) error {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	if _, ok := repo.s.products[product.ID]; !ok {
+		return models.NotFound("product")
+	}
+	if err := repo.s.checkProduct(product); err != nil {
+		return err
+	}
+	repo.s.products[product.ID] = cloneProduct(product)
+	return nil
+}
+
+// Delete menolak produk yang sudah pernah terjual, sama seperti foreign key di Postgres.
+func (repo *ProductRepository) Delete(ctx context.Context, id int) error {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	if _, ok := repo.s.products[id]; !ok {
+		return models.NotFound("product")
+	}
+	for _, t := range repo.s.transactions {
+		for _, d := range t.Details {
+			if d.ProductID == id {
+				return models.Conflictf("product has transactions and cannot be deleted")
+			}
+		}
+	}
+	delete(repo.s.products, id)
+	return nil
+}
+
+// product mengembalikan salinan produk beserta nama kategorinya. Pemanggil memegang s.mu.
+func (s *Store) product(id int) (*models.Product, error) {
+	p, ok := s.products[id]
+	if !ok {
+		return nil, models.NotFound("product")
+	}
+	p = cloneProduct(&p)
+	if p.CategoryID != nil {
+		p.CategoryName = s.categories[*p.CategoryID].Name
+	}
+	return &p, nil
+}
+
+// checkProduct menegakkan batasan yang di Postgres dijaga skema: PLU unik dan kategori ada.
+func (s *Store) checkProduct(product *models.Product) error {
+	if product.CategoryID != nil {
+		if _, ok := s.categories[*product.CategoryID]; !ok {
+			return models.NotFound("category")
+		}
+	}
+	if product.PLU == "" {
+		return nil
+	}
+	for _, p := range s.products {
+		if p.ID != product.ID && p.PLU == product.PLU {
+			return models.Conflictf("plu already used by another product")
+		}
+	}
+	return nil
+}
+
+// cloneProduct menyalin produk tanpa field turunan, supaya pemanggil tidak bisa
+// mengubah data tersimpan lewat pointer atau slice yang sama.
+func cloneProduct(p *models.Product) models.Product {
+	c := *p
+	c.CategoryName, c.Variants, c.Units, c.ChangedBy = "", nil, nil, nil
+	if p.CategoryID != nil {
+		id := *p.CategoryID
+		c.CategoryID = &id
+	}
+	c.OptionAxes = slices.Clone(p.OptionAxes)
+	return c
+}
+
+func (repo *CategoryRepository) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	var items []models.Category
+	for _, c := range repo.s.categories {
+		items = append(items, c)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+func (repo *CategoryRepository) GetCategoryByID(ctx context.Context, id int) (*models.Category, error) {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	c, ok := repo.s.categories[id]
+	if !ok {
+		return nil, models.NotFound("category")
+	}
+	return &c, nil
+}
+
+func (repo *CategoryRepository) CreateCategory(ctx context.Context, c *models.Category) error {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	c.ID = repo.s.nextID("categories")
+	repo.s.categories[c.ID] = *c
+	return nil
+}
+
+func (repo *CategoryRepository) UpdateCategory(ctx context.Context, c *models.Category) error {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	if _, ok := repo.s.categories[c.ID]; !ok {
+		return models.NotFound("category")
+	}
+	repo.s.categories[c.ID] = *c
+	return nil
+}
+
+// DeleteCategory melepas produk dari kategori yang dihapus (ON DELETE SET NULL).
+func (repo *CategoryRepository) DeleteCategory(ctx context.Context, id int) error {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	if _, ok := repo.s.categories[id]; !ok {
+		return models.NotFound("category")
+	}
+	delete(repo.s.categories, id)
+	for pid, p := range repo.s.products {
+		if p.CategoryID != nil && *p.CategoryID == id {
+			p.CategoryID = nil
+			repo.s.products[pid] = p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories"
+	"time"
+)
+
+// checkoutTx adalah repositories.CheckoutTx di atas Store. Tulisan ditampung di peta
+// terpisah dan baru diterapkan ke Store oleh commit, seperti transaksi database yang
+// di-rollback jika checkout gagal. Pemanggil memegang s.mu dari begin sampai commit.
+//
+// Backend ini tidak menyimpan varian, satuan alternatif, modifier, daftar harga,
+// pelanggan/loyalti, maupun kasbon: pencarian yang opsional (modifier, daftar harga,
+// aturan loyalti) mengembalikan hasil kosong, sisanya models.ErrUnsupported.
+type checkoutTx struct {
+	s            *Store
+	products     map[int]models.Product
+	transactions map[int]models.Transaction
+}
+
+var _ repositories.CheckoutTx = (*checkoutTx)(nil)
+
+// checkSupported menolak fitur yang datanya tidak disimpan backend ini sebelum checkout
+// dimulai, supaya pesan errornya menyebut fiturnya alih-alih langkah checkout yang gagal.
+func checkSupported(req *models.CheckoutRequest) error {
+	switch {
+	case req.CustomerID != nil || req.RedeemPoints > 0:
+		return fmt.Errorf("customers and loyalty points: %w", models.ErrUnsupported)
+	case req.PaymentMethod == models.PaymentPayLater:
+		return fmt.Errorf("pay_later: %w", models.ErrUnsupported)
+	case req.PriceListID != nil:
+		return fmt.Errorf("price lists: %w", models.ErrUnsupported)
+	}
+	for _, item := range req.Items {
+		switch {
+		case item.VariantID != nil:
+			return fmt.Errorf("variants: %w", models.ErrUnsupported)
+		case len(item.ModifierIDs) > 0:
+			return fmt.Errorf("modifiers: %w", models.ErrUnsupported)
+		}
+	}
+	return nil
+}
+
+func (s *Store) begin() *checkoutTx {
+	return &checkoutTx{s: s, products: map[int]models.Product{}, transactions: map[int]models.Transaction{}}
+}
+
+func (c *checkoutTx) commit() {
+	for id, p := range c.products {
+		c.s.products[id] = p
+	}
+	for id, t := range c.transactions {
+		c.s.transactions[id] = t
+	}
+}
+
+func (c *checkoutTx) LockProducts(ctx context.Context, ids []int) error {
+	return nil
+}
+
+func (c *checkoutTx) Product(ctx context.Context, id int) (*models.Product, error) {
+	p, ok := c.products[id]
+	if !ok {
+		if p, ok = c.s.products[id]; !ok {
+			return nil, models.NotFound("product")
+		}
+	}
+	return &p, nil
+}
+
+func (c *checkoutTx) Variant(ctx context.Context, productID, variantID int) (*models.ProductVariant, error) {
+	return nil, fmt.Errorf("variants: %w", models.ErrUnsupported)
+}
+
+func (c *checkoutTx) SellUnit(ctx context.Context, productID int, name string) (*models.ProductUnit, error) {
+	return nil, fmt.Errorf("unit %q: %w", name, models.ErrUnsupported)
+}
+
+func (c *checkoutTx) ModifierGroups(ctx context.Context, productID int) ([]models.ModifierGroup, error) {
+	return nil, nil
+}
+
+func (c *checkoutTx) ListPrice(ctx context.Context, priceListID, productID int, variantID *int, baseQuantity float64) (money.Amount, bool, error) {
+	return 0, false, nil
+}
+
+func (c *checkoutTx) LoyaltyRules(ctx context.Context) (*models.LoyaltyRules, error) {
+	return &models.LoyaltyRules{}, nil
+}
+
+func (c *checkoutTx) OutstandingCredit(ctx context.Context, customerID int) (money.Amount, error) {
+	return 0, fmt.Errorf("pay_later: %w", models.ErrUnsupported)
+}
+
+func (c *checkoutTx) LockCustomer(ctx context.Context, customerID int) (*models.Customer, error) {
+	return nil, fmt.Errorf("customers: %w", models.ErrUnsupported)
+}
+
+func (c *checkoutTx) LoyaltyLedger(ctx context.Context, customerID int) ([]models.LoyaltyEntry, error) {
+	return nil, fmt.Errorf("loyalty points: %w", models.ErrUnsupported)
+}
+
+func (c *checkoutTx) InsertLedger(ctx context.Context, e *models.LoyaltyEntry) error {
+	return fmt.Errorf("loyalty points: %w", models.ErrUnsupported)
+}
+
+// AdjustStock melewati produk yang sudah dihapus: refund tidak bisa mengembalikan stoknya,
+// dan Postgres tidak mengizinkan produk yang pernah terjual dihapus.
+func (c *checkoutTx) AdjustStock(ctx context.Context, productID int, variantID *int, delta float64) error {
+	if variantID != nil {
+		return fmt.Errorf("variants: %w", models.ErrUnsupported)
+	}
+	p, err := c.Product(ctx, productID)
+	if err != nil {
+		return nil
+	}
+	p.Stock = models.RoundQuantity(p.Stock + delta)
+	c.products[productID] = *p
+	return nil
+}
+
+func (c *checkoutTx) InsertTransaction(ctx context.Context, t *models.Transaction) error {
+	t.ID = c.s.nextID("transactions")
+	t.CreatedAt = time.Now()
+	c.transactions[t.ID] = *t
+	return nil
+}
+
+func (c *checkoutTx) InsertDetail(ctx context.Context, d *models.TransactionDetail) error {
+	d.ID = c.s.nextID("transaction_details")
+	t := c.transactions[d.TransactionID]
+	t.Details = append(t.Details, *d)
+	c.transactions[d.TransactionID] = t
+	return nil
+}
+
+func (c *checkoutTx) InsertReceivable(ctx context.Context, r *models.Receivable) error {
+	return fmt.Errorf("pay_later: %w", models.ErrUnsupported)
+}
+
+func (c *checkoutTx) LockTransaction(ctx context.Context, id int) (*models.Transaction, error) {
+	t, ok := c.s.transactions[id]
+	if !ok {
+		return nil, models.NotFound("transaction")
+	}
+	return cloneTransaction(t), nil
+}
+
+func (c *checkoutTx) MarkRefunded(ctx context.Context, id int, refundedBy *int) error {
+	t := *cloneTransaction(c.s.transactions[id])
+	now := time.Now()
+	t.Status, t.RefundedAt, t.RefundedBy = models.TransactionRefunded, &now, refundedBy
+	c.transactions[id] = t
+	return nil
+}
+
+func (c *checkoutTx) VoidReceivables(ctx context.Context, transactionID int) error {
+	return nil
+}
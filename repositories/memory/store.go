@@ -0,0 +1,44 @@
+// Package memory adalah backend penyimpanan di memori proses untuk katalog, transaksi,
+// dan laporan (lihat interface di repositories/store.go). Dipakai untuk demo dan untuk
+// menguji service tanpa Postgres; data hilang saat proses berhenti.
+//
+// Checkout dan refund memakai repositories.Checkout dan repositories.Refund, jadi
+// harganya dihitung sama persis dengan backend lain. Fitur yang datanya tidak disimpan di
+// backend ini (varian, satuan alternatif, modifier, daftar harga, pelanggan/loyalti,
+// kasbon) ditolak dengan models.ErrUnsupported, bukan diabaikan diam-diam.
+package memory
+
+import (
+	"kasir-api/models"
+	"sync"
+)
+
+// Store menyimpan semua data dalam satu mutex. Setiap operasi tulis memegang kunci dari
+// validasi sampai selesai, jadi checkout dan refund atomik seperti transaksi database.
+type Store struct {
+	mu           sync.Mutex
+	products     map[int]models.Product
+	categories   map[int]models.Category
+	transactions map[int]models.Transaction
+	lastID       map[string]int
+}
+
+func New() *Store {
+	return &Store{
+		products:     map[int]models.Product{},
+		categories:   map[int]models.Category{},
+		transactions: map[int]models.Transaction{},
+		lastID:       map[string]int{},
+	}
+}
+
+// nextID meniru kolom identity: nomor urut per tabel. Pemanggil memegang s.mu.
+func (s *Store) nextID(table string) int {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+func (s *Store) Products() *ProductRepository         { return &ProductRepository{s: s} }
+func (s *Store) Categories() *CategoryRepository      { return &CategoryRepository{s: s} }
+func (s *Store) Transactions() *TransactionRepository { return &TransactionRepository{s: s} }
+func (s *Store) Reports() *ReportRepository           { return &ReportRepository{s: s} }
@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"kasir-api/repositories/memory"
+	"kasir-api/repositories/storetest"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Stores {
+		s := memory.New()
+		return storetest.Stores{Products: s.Products(), Categories: s.Categories(), Transactions: s.Transactions(), Reports: s.Reports()}
+	})
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories"
+	"slices"
+	"time"
+)
+
+type TransactionRepository struct {
+	s *Store
+}
+
+type ReportRepository struct {
+	s *Store
+}
+
+var (
+	_ repositories.TransactionStore = (*TransactionRepository)(nil)
+	_ repositories.ReportStore      = (*ReportRepository)(nil)
+)
+
+// CreateTransaction menjalankan repositories.Checkout di bawah kunci store. Perubahan
+// ditampung di checkoutTx dan baru diterapkan jika checkout berhasil, jadi checkout yang
+// gagal tidak mengubah apa pun. Fitur yang datanya tidak disimpan di sini ditolak lebih
+// dulu oleh checkSupported.
+func (repo *TransactionRepository) CreateTransaction(ctx context.Context, req *models.CheckoutRequest) (*models.Transaction, error) {
+	if err := checkSupported(req); err != nil {
+		return nil, err
+	}
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+	// request yang dibatalkan selagi menunggu kunci tidak boleh tetap mengubah stok
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tx := repo.s.begin()
+	t, err := repositories.Checkout(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+	tx.commit()
+	return cloneTransaction(*t), nil
+}
+
+// Refund menjalankan repositories.Refund di bawah kunci store.
+func (repo *TransactionRepository) Refund(ctx context.Context, id int, refundedBy *int) error {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx := repo.s.begin()
+	if err := repositories.Refund(ctx, tx, id, refundedBy); err != nil {
+		return err
+	}
+	tx.commit()
+	return nil
+}
+
+func (repo *TransactionRepository) GetByID(ctx context.Context, id int) (*models.Transaction, error) {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	t, ok := repo.s.transactions[id]
+	if !ok {
+		return nil, models.NotFound("transaction")
+	}
+	return cloneTransaction(t), nil
+}
+
+// GetByCustomerID selalu kosong: backend ini tidak menyimpan transaksi pelanggan.
+func (repo *TransactionRepository) GetByCustomerID(ctx context.Context, customerID, limit int) ([]models.Transaction, error) {
+	return make([]models.Transaction, 0), nil
+}
+
+func cloneTransaction(t models.Transaction) *models.Transaction {
+	t.Details = slices.Clone(t.Details)
+	return &t
+}
+
+// GetTodayReport merangkum transaksi completed hari ini (zona waktu lokal proses).
+// Backend ini tidak menyimpan varian maupun modifier, jadi byVariant tidak berpengaruh.
+func (repo *ReportRepository) GetTodayReport(ctx context.Context, byVariant bool) (*models.TodayReport, error) {
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	year, month, day := time.Now().Date()
+	report := models.TodayReport{
+		TotalRevenue:         money.New(0),
+		BestsellingModifiers: make([]models.BestsellingModifier, 0),
+	}
+	var sold []repositories.SoldLine
+	for _, t := range repo.s.transactions {
+		if y, m, d := t.CreatedAt.Date(); y != year || m != month || d != day || t.Status != models.TransactionCompleted {
+			continue
+		}
+		var err error
+		if report.TotalRevenue, err = report.TotalRevenue.Add(t.TotalAmount); err != nil {
+			return nil, err
+		}
+		report.TotalTransactions++
+		for _, d := range t.Details {
+			sold = append(sold, repositories.SoldLine{
+				ProductID: d.ProductID, Name: d.ProductName, BaseUnit: repo.s.products[d.ProductID].Unit,
+				Unit: d.Unit, Quantity: d.Quantity, BaseQuantity: d.BaseQuantity,
+			})
+		}
+	}
+	report.BestsellingProducts = repositories.BestsellingProducts(sold, 1)
+	return &report, nil
+}
@@ -0,0 +1,278 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/money"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ModifierRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewModifierRepository(pool *pgxpool.Pool, tenantID int) *ModifierRepository {
+	return &ModifierRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+// querier dipenuhi oleh *pgxpool.Pool maupun pgx.Tx, sehingga query baca yang sama
+// bisa dipakai di luar maupun di dalam transaksi checkout.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+const modifierGroupColumns = `g.id, g.name, g.product_id, g.category_id, g.required, g.min_select, g.max_select`
+
+// loadModifierGroups membaca grup milik tenant beserta opsinya. where berisi kondisi
+// tambahan (diawali "AND ...") dengan alias g; placeholder-nya mulai dari $2 karena $1 adalah tenant.
+func loadModifierGroups(ctx context.Context, q querier, tenantID int, where string, args ...any) ([]models.ModifierGroup, error) {
+	rows, err := q.Query(ctx, `SELECT `+modifierGroupColumns+` FROM modifier_groups g WHERE g.tenant_id = $1 `+where+` ORDER BY g.id`,
+		append([]any{tenantID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]models.ModifierGroup, 0)
+	index := map[int]int{}
+	for rows.Next() {
+		var g models.ModifierGroup
+		if err := rows.Scan(&g.ID, &g.Name, &g.ProductID, &g.CategoryID, &g.Required, &g.MinSelect, &g.MaxSelect); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		g.Options = make([]models.Modifier, 0)
+		index[g.ID] = len(groups)
+		groups = append(groups, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	ids := make([]int, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+	rows, err = q.Query(ctx, `
+		SELECT id, group_id, name, price_delta
+		FROM modifiers
+		WHERE group_id = ANY($1) AND tenant_id = $2
+		ORDER BY id`, ids, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m models.Modifier
+		if err := rows.Scan(&m.ID, &m.GroupID, &m.Name, &m.PriceDelta); err != nil {
+			return nil, err
+		}
+		g := &groups[index[m.GroupID]]
+		g.Options = append(g.Options, m)
+	}
+	return groups, rows.Err()
+}
+
+// GetAll mengembalikan semua grup modifier. Jika productID != 0, hanya grup yang berlaku
+// untuk produk tersebut (langsung atau lewat kategorinya).
+func (repo *ModifierRepository) GetAll(ctx context.Context, productID int) ([]models.ModifierGroup, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	if productID == 0 {
+		return loadModifierGroups(ctx, repo.pool, repo.tenantID, "")
+	}
+	return loadModifierGroups(ctx, repo.pool, repo.tenantID, applicableGroupsWhere, productID)
+}
+
+const applicableGroupsWhere = `
+	AND (g.product_id = $2
+	     OR g.category_id = (SELECT category_id FROM products WHERE id = $2 AND tenant_id = $1))`
+
+func (repo *ModifierRepository) GetByID(ctx context.Context, id int) (*models.ModifierGroup, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	groups, err := loadModifierGroups(ctx, repo.pool, repo.tenantID, "AND g.id = $2", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, models.NotFound("modifier group")
+	}
+	return &groups[0], nil
+}
+
+func (repo *ModifierRepository) Create(ctx context.Context, g *models.ModifierGroup) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO modifier_groups (tenant_id, name, product_id, category_id, required, min_select, max_select)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+	`, repo.tenantID, g.Name, g.ProductID, g.CategoryID, g.Required, g.MinSelect, g.MaxSelect).Scan(&g.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range g.Options {
+		g.Options[i].GroupID = g.ID
+		err = tx.QueryRow(ctx, `
+			INSERT INTO modifiers (tenant_id, group_id, name, price_delta)
+			VALUES ($1, $2, $3, $4) RETURNING id
+		`, repo.tenantID, g.ID, g.Options[i].Name, g.Options[i].PriceDelta).Scan(&g.Options[i].ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Update menyimpan grup dan menyelaraskan opsinya: opsi dengan id diperbarui,
+// opsi tanpa id ditambahkan, dan opsi lama yang tidak dikirim dihapus.
+func (repo *ModifierRepository) Update(ctx context.Context, g *models.ModifierGroup) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	ct, err := tx.Exec(ctx, `
+		UPDATE modifier_groups
+		SET name = $1, product_id = $2, category_id = $3, required = $4, min_select = $5, max_select = $6
+		WHERE id = $7 AND tenant_id = $8
+	`, g.Name, g.ProductID, g.CategoryID, g.Required, g.MinSelect, g.MaxSelect, g.ID, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("modifier group")
+	}
+
+	keep := make([]int, 0, len(g.Options))
+	for i := range g.Options {
+		m := &g.Options[i]
+		m.GroupID = g.ID
+		if m.ID == 0 {
+			continue
+		}
+		ct, err := tx.Exec(ctx, `
+			UPDATE modifiers SET name = $1, price_delta = $2
+			WHERE id = $3 AND group_id = $4 AND tenant_id = $5
+		`, m.Name, m.PriceDelta, m.ID, g.ID, repo.tenantID)
+		if err != nil {
+			return err
+		}
+		if ct.RowsAffected() == 0 {
+			return models.NotFound(fmt.Sprintf("modifier %d in this group", m.ID))
+		}
+		keep = append(keep, m.ID)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM modifiers WHERE group_id = $1 AND tenant_id = $2 AND NOT (id = ANY($3))`,
+		g.ID, repo.tenantID, keep); err != nil {
+		return err
+	}
+
+	for i := range g.Options {
+		m := &g.Options[i]
+		if m.ID != 0 {
+			continue
+		}
+		err = tx.QueryRow(ctx, `
+			INSERT INTO modifiers (tenant_id, group_id, name, price_delta)
+			VALUES ($1, $2, $3, $4) RETURNING id
+		`, repo.tenantID, g.ID, m.Name, m.PriceDelta).Scan(&m.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (repo *ModifierRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `DELETE FROM modifier_groups WHERE id = $1 AND tenant_id = $2`, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("modifier group")
+	}
+	return nil
+}
+
+// selectModifiers mencocokkan modifier_ids pilihan kasir dengan grup yang berlaku untuk
+// produk, memeriksa aturan wajib/min/max tiap grup, dan mengembalikan salinan modifier
+// terpilih beserta total selisih harganya per unit.
+func selectModifiers(groups []models.ModifierGroup, ids []int) ([]models.TransactionDetailModifier, money.Amount, error) {
+	type choice struct {
+		group    *models.ModifierGroup
+		modifier models.Modifier
+	}
+	available := map[int]choice{}
+	for gi := range groups {
+		for _, m := range groups[gi].Options {
+			available[m.ID] = choice{group: &groups[gi], modifier: m}
+		}
+	}
+
+	selected := make([]models.TransactionDetailModifier, 0, len(ids))
+	counts := map[int]int{}
+	seen := map[int]bool{}
+	var delta money.Amount
+	for _, id := range ids {
+		c, ok := available[id]
+		if !ok {
+			return nil, 0, models.Validationf("modifier %d is not available for this product", id)
+		}
+		if seen[id] {
+			return nil, 0, models.Validationf("modifier %d selected more than once", id)
+		}
+		seen[id] = true
+		counts[c.group.ID]++
+		var err error
+		if delta, err = delta.Add(c.modifier.PriceDelta); err != nil {
+			return nil, 0, err
+		}
+		selected = append(selected, models.TransactionDetailModifier{
+			ModifierID: c.modifier.ID,
+			Name:       c.modifier.Name,
+			PriceDelta: c.modifier.PriceDelta,
+		})
+	}
+
+	for _, g := range groups {
+		n := counts[g.ID]
+		if g.Required && n == 0 {
+			return nil, 0, models.Validationf("modifier group %q is required", g.Name)
+		}
+		if n > 0 && n < g.MinSelect {
+			return nil, 0, models.Validationf("modifier group %q needs at least %d selections", g.Name, g.MinSelect)
+		}
+		if g.MaxSelect > 0 && n > g.MaxSelect {
+			return nil, 0, models.Validationf("modifier group %q allows at most %d selections", g.Name, g.MaxSelect)
+		}
+	}
+
+	return selected, delta, nil
+}
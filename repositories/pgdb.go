@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pgDB membungkus pool agar error Postgres dari setiap query (dan transaksi yang dibuka
+// darinya) sudah diterjemahkan ke error domain sebelum keluar dari repository, seperti
+// isConstraint di backend SQLite. Lapisan HTTP cukup mengenal models.
+//
+// Jika ctx membawa transaksi dari atomic, semua query ikut transaksi itu dan BeginTx
+// membuka savepoint di dalamnya, sehingga beberapa repository bisa ditulis sekaligus.
+type pgDB struct {
+	*pgxpool.Pool
+}
+
+type txKey struct{}
+
+// ambient mengembalikan transaksi atomic yang dibawa ctx, jika ada.
+func ambient(ctx context.Context) (pgTx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgTx)
+	return tx, ok
+}
+
+// atomic menjalankan fn dalam satu transaksi; repository Postgres yang dipanggil dengan
+// ctx milik fn menulis ke transaksi yang sama. Error dari fn membatalkan semuanya.
+func (db pgDB) atomic(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (db pgDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx, ok := ambient(ctx); ok {
+		return tx.Exec(ctx, sql, args...)
+	}
+	tag, err := db.Pool.Exec(ctx, sql, args...)
+	return tag, pgError(err)
+}
+
+func (db pgDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx, ok := ambient(ctx); ok {
+		return tx.Query(ctx, sql, args...)
+	}
+	rows, err := db.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return pgRows{rows}, nil
+}
+
+func (db pgDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx, ok := ambient(ctx); ok {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return pgRow{db.Pool.QueryRow(ctx, sql, args...)}
+}
+
+// BeginTx di dalam atomic membuka savepoint; opts tidak berlaku karena isolasinya
+// mengikuti transaksi luar.
+func (db pgDB) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	if outer, ok := ambient(ctx); ok {
+		tx, err := outer.Tx.Begin(ctx)
+		if err != nil {
+			return nil, pgError(err)
+		}
+		return pgTx{tx}, nil
+	}
+	tx, err := db.Pool.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return pgTx{tx}, nil
+}
+
+type pgTx struct {
+	pgx.Tx
+}
+
+func (tx pgTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := tx.Tx.Exec(ctx, sql, args...)
+	return tag, pgError(err)
+}
+
+func (tx pgTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := tx.Tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return pgRows{rows}, nil
+}
+
+func (tx pgTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return pgRow{tx.Tx.QueryRow(ctx, sql, args...)}
+}
+
+// Commit ikut diterjemahkan: constraint DEFERRABLE baru diperiksa saat commit.
+func (tx pgTx) Commit(ctx context.Context) error {
+	return pgError(tx.Tx.Commit(ctx))
+}
+
+type pgRow struct {
+	pgx.Row
+}
+
+func (r pgRow) Scan(dest ...any) error {
+	return pgError(r.Row.Scan(dest...))
+}
+
+type pgRows struct {
+	pgx.Rows
+}
+
+func (r pgRows) Scan(dest ...any) error {
+	return pgError(r.Rows.Scan(dest...))
+}
+
+func (r pgRows) Err() error {
+	return pgError(r.Rows.Err())
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"errors"
+	"kasir-api/models"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// constraintError adalah error database yang sudah diberi jenis domain. Error aslinya
+// (PgError atau pgx.ErrNoRows) tetap terbungkus, sehingga repository bisa memilih pesan
+// yang lebih spesifik berdasarkan nama constraint (lihat productError).
+type constraintError struct {
+	kind    error
+	message string
+	pg      *pgconn.PgError
+}
+
+func (e *constraintError) Error() string { return e.message }
+
+func (e *constraintError) Unwrap() []error {
+	if e.pg == nil {
+		return []error{e.kind, pgx.ErrNoRows}
+	}
+	return []error{e.kind, e.pg}
+}
+
+// errNoRows tetap cocok dengan pgx.ErrNoRows untuk pemanggil yang memeriksanya, dan
+// menjadi models.ErrNotFound bila lolos tanpa diberi pesan yang lebih spesifik.
+var errNoRows = &constraintError{models.ErrNotFound, "resource not found", nil}
+
+// pgError menerjemahkan pelanggaran batasan skema yang lolos dari validasi service.
+// Pesan Postgres (nama tabel, constraint) tidak diteruskan ke klien. Error lain
+// dikembalikan apa adanya.
+func pgError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errNoRows
+	}
+	var pg *pgconn.PgError
+	if !errors.As(err, &pg) {
+		return err
+	}
+	switch pg.Code {
+	case "23505": // unique_violation
+		return &constraintError{models.ErrConflict, "a record with the same unique value already exists", pg}
+	case "23503": // foreign_key_violation
+		return &constraintError{models.ErrConflict, "the record is referenced by other data or refers to a record that does not exist", pg}
+	case "23514", "23502": // check_violation, not_null_violation
+		return &constraintError{models.ErrValidation, "a value is missing or out of the allowed range", pg}
+	case "22001", "22003", "22P02": // string_data_right_truncation, numeric_value_out_of_range, invalid_text_representation
+		return &constraintError{models.ErrValidation, "a value has an invalid format or is too large", pg}
+	}
+	return err
+}
+
+// isConstraint melaporkan apakah err adalah pelanggaran constraint dengan kode SQLSTATE
+// code (mis. "23505"); jika column diisi, hanya constraint yang namanya menyebut kolom itu
+// (nama bawaan Postgres, mis. products_tenant_id_plu_key).
+func isConstraint(err error, code, column string) bool {
+	var pg *pgconn.PgError
+	if !errors.As(err, &pg) || pg.Code != code {
+		return false
+	}
+	return column == "" || strings.Contains(pg.ConstraintName, "_"+column+"_")
+}
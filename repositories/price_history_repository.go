@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PriceHistoryRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewPriceHistoryRepository(pool *pgxpool.Pool, tenantID int) *PriceHistoryRepository {
+	return &PriceHistoryRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+const scheduledPriceColumns = `id, product_id, price, effective_at, status, created_by, applied_at, created_at`
+
+func scanScheduledPrice(row pgx.Row, sp *models.ScheduledPrice) error {
+	return row.Scan(&sp.ID, &sp.ProductID, &sp.Price, &sp.EffectiveAt, &sp.Status, &sp.CreatedBy, &sp.AppliedAt, &sp.CreatedAt)
+}
+
+// GetHistory mengembalikan riwayat harga produk, terbaru lebih dulu.
+func (repo *PriceHistoryRepository) GetHistory(ctx context.Context, productID int) ([]models.PriceChange, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.pool.Query(ctx, `
+		SELECT id, product_id, old_price, new_price, source, scheduled_price_id, changed_by, changed_at
+		FROM price_history
+		WHERE product_id = $1 AND tenant_id = $2
+		ORDER BY changed_at DESC, id DESC
+	`, productID, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]models.PriceChange, 0)
+	for rows.Next() {
+		var c models.PriceChange
+		if err := rows.Scan(&c.ID, &c.ProductID, &c.OldPrice, &c.NewPrice, &c.Source, &c.ScheduledPriceID, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
+
+// GetScheduled mengembalikan perubahan harga terjadwal produk, yang paling dekat lebih dulu.
+func (repo *PriceHistoryRepository) GetScheduled(ctx context.Context, productID int) ([]models.ScheduledPrice, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.pool.Query(ctx, `
+		SELECT `+scheduledPriceColumns+` FROM scheduled_prices WHERE product_id = $1 AND tenant_id = $2
+		ORDER BY effective_at, id
+	`, productID, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scheduled := make([]models.ScheduledPrice, 0)
+	for rows.Next() {
+		var sp models.ScheduledPrice
+		if err := scanScheduledPrice(rows, &sp); err != nil {
+			return nil, err
+		}
+		scheduled = append(scheduled, sp)
+	}
+	return scheduled, rows.Err()
+}
+
+func (repo *PriceHistoryRepository) GetScheduledByID(ctx context.Context, id int) (*models.ScheduledPrice, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var sp models.ScheduledPrice
+	err := scanScheduledPrice(repo.pool.QueryRow(ctx, `
+		SELECT `+scheduledPriceColumns+` FROM scheduled_prices WHERE id = $1 AND tenant_id = $2
+	`, id, repo.tenantID), &sp)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("scheduled price")
+		}
+		return nil, err
+	}
+	return &sp, nil
+}
+
+func (repo *PriceHistoryRepository) Schedule(ctx context.Context, sp *models.ScheduledPrice) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	return scanScheduledPrice(repo.pool.QueryRow(ctx, `
+		INSERT INTO scheduled_prices (tenant_id, product_id, price, effective_at, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+scheduledPriceColumns+`
+	`, repo.tenantID, sp.ProductID, sp.Price, sp.EffectiveAt, models.ScheduledPricePending, sp.CreatedBy), sp)
+}
+
+// Cancel membatalkan perubahan harga yang belum diterapkan.
+func (repo *PriceHistoryRepository) Cancel(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `
+		UPDATE scheduled_prices SET status = $1 WHERE id = $2 AND status = $3 AND tenant_id = $4
+	`, models.ScheduledPriceCancelled, id, models.ScheduledPricePending, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("pending scheduled price")
+	}
+	return nil
+}
+
+// ApplyDue menerapkan semua perubahan harga terjadwal yang sudah jatuh tempo, urut
+// effective_at, dalam satu transaksi. SKIP LOCKED membuat beberapa instance server
+// aman menjalankan scheduler bersamaan tanpa menerapkan jadwal yang sama dua kali.
+// Satu-satunya query lintas tenant: scheduler berjalan sekali untuk semua toko, dan
+// setiap jadwal diterapkan dengan tenant_id miliknya sendiri.
+func (repo *PriceHistoryRepository) ApplyDue(ctx context.Context, now time.Time) ([]models.ScheduledPrice, error) {
+	ctx, cancel := WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT tenant_id, `+scheduledPriceColumns+`
+		FROM scheduled_prices
+		WHERE status = $1 AND effective_at <= $2
+		ORDER BY effective_at, id
+		FOR UPDATE SKIP LOCKED
+	`, models.ScheduledPricePending, now)
+	if err != nil {
+		return nil, err
+	}
+	due := make([]models.ScheduledPrice, 0)
+	tenants := make([]int, 0)
+	for rows.Next() {
+		var sp models.ScheduledPrice
+		var tenantID int
+		if err := scanScheduledPrice(prefixedRow{rows, &tenantID}, &sp); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, sp)
+		tenants = append(tenants, tenantID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range due {
+		sp, tenantID := &due[i], tenants[i]
+		var oldPrice money.Amount
+		err := tx.QueryRow(ctx, `
+			SELECT price FROM products WHERE id = $1 AND tenant_id = $2 FOR UPDATE
+		`, sp.ProductID, tenantID).Scan(&oldPrice)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec(ctx, `UPDATE products SET price = $1 WHERE id = $2 AND tenant_id = $3`, sp.Price, sp.ProductID, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordPriceChange(ctx, tx, tenantID, sp.ProductID, &oldPrice, sp.Price, models.PriceSourceScheduled, &sp.ID, sp.CreatedBy); err != nil {
+			return nil, err
+		}
+		err = tx.QueryRow(ctx, `
+			UPDATE scheduled_prices SET status = $1, applied_at = now() WHERE id = $2 AND tenant_id = $3
+			RETURNING status, applied_at
+		`, models.ScheduledPriceApplied, sp.ID, tenantID).Scan(&sp.Status, &sp.AppliedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return due, tx.Commit(ctx)
+}
+
+func recordPriceChange(ctx context.Context, tx pgx.Tx, tenantID, productID int, oldPrice *money.Amount, newPrice money.Amount, source string, scheduledID, changedBy *int) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO price_history (tenant_id, product_id, old_price, new_price, source, scheduled_price_id, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, tenantID, productID, oldPrice, newPrice, source, scheduledID, changedBy)
+	return err
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PriceListRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewPriceListRepository(pool *pgxpool.Pool, tenantID int) *PriceListRepository {
+	return &PriceListRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+func (repo *PriceListRepository) GetAll(ctx context.Context) ([]models.PriceList, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.pool.Query(ctx, `SELECT id, code, name FROM price_lists WHERE tenant_id = $1 ORDER BY id`, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := make([]models.PriceList, 0)
+	for rows.Next() {
+		var l models.PriceList
+		if err := rows.Scan(&l.ID, &l.Code, &l.Name); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	return lists, rows.Err()
+}
+
+func (repo *PriceListRepository) GetByID(ctx context.Context, id int) (*models.PriceList, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var l models.PriceList
+	err := repo.pool.QueryRow(ctx, `SELECT id, code, name FROM price_lists WHERE id = $1 AND tenant_id = $2`, id, repo.tenantID).Scan(&l.ID, &l.Code, &l.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("price list")
+		}
+		return nil, err
+	}
+
+	rows, err := repo.pool.Query(ctx, `
+		SELECT id, price_list_id, product_id, variant_id, min_quantity, price
+		FROM price_list_items
+		WHERE price_list_id = $1 AND tenant_id = $2
+		ORDER BY product_id, variant_id NULLS FIRST, min_quantity
+	`, id, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	l.Items = make([]models.PriceListItem, 0)
+	for rows.Next() {
+		var item models.PriceListItem
+		if err := rows.Scan(&item.ID, &item.PriceListID, &item.ProductID, &item.VariantID, &item.MinQuantity, &item.Price); err != nil {
+			return nil, err
+		}
+		l.Items = append(l.Items, item)
+	}
+	return &l, rows.Err()
+}
+
+func (repo *PriceListRepository) GetByCode(ctx context.Context, code string) (*models.PriceList, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var l models.PriceList
+	err := repo.pool.QueryRow(ctx, `SELECT id, code, name FROM price_lists WHERE code = $1 AND tenant_id = $2`, code, repo.tenantID).Scan(&l.ID, &l.Code, &l.Name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("price list")
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (repo *PriceListRepository) Create(ctx context.Context, l *models.PriceList) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	return repo.pool.QueryRow(ctx, `INSERT INTO price_lists (tenant_id, code, name) VALUES ($1, $2, $3) RETURNING id`,
+		repo.tenantID, l.Code, l.Name).Scan(&l.ID)
+}
+
+func (repo *PriceListRepository) Update(ctx context.Context, l *models.PriceList) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `UPDATE price_lists SET code = $1, name = $2 WHERE id = $3 AND tenant_id = $4`,
+		l.Code, l.Name, l.ID, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("price list")
+	}
+	return nil
+}
+
+func (repo *PriceListRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `DELETE FROM price_lists WHERE id = $1 AND tenant_id = $2`, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("price list")
+	}
+	return nil
+}
+
+// ReplaceItems mengganti seluruh harga di daftar harga dalam satu transaksi.
+func (repo *PriceListRepository) ReplaceItems(ctx context.Context, priceListID int, items []models.PriceListItem) error {
+	ctx, cancel := WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM price_list_items WHERE price_list_id = $1 AND tenant_id = $2`,
+		priceListID, repo.tenantID); err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].PriceListID = priceListID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO price_list_items (tenant_id, price_list_id, product_id, variant_id, min_quantity, price)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+		`, repo.tenantID, priceListID, items[i].ProductID, items[i].VariantID, items[i].MinQuantity, items[i].Price).Scan(&items[i].ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -5,7 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"kasir-api/models"
-	"time"
+	"kasir-api/money"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -13,22 +13,23 @@ import (
 )
 
 type ProductRepository struct {
-	pool *pgxpool.Pool
+	pool     pgDB
+	tenantID int
 }
 
-func NewProductRepository(pool *pgxpool.Pool) *ProductRepository {
-	return &ProductRepository{pool: pool}
+func NewProductRepository(pool *pgxpool.Pool, tenantID int) *ProductRepository {
+	return &ProductRepository{pool: pgDB{pool}, tenantID: tenantID}
 }
 
-func (repo *ProductRepository) GetAll(nameFilter string) ([]models.Product, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (repo *ProductRepository) GetAll(ctx context.Context, nameFilter string) ([]models.Product, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
-	var query = `SELECT id, name, price, stock FROM products p`
+	var query = `SELECT id, name, price, stock, unit, decimal_qty FROM products p WHERE p.tenant_id = $1`
 
-	args := []interface{}{}
+	args := []interface{}{repo.tenantID}
 	if nameFilter != "" {
-		query += " WHERE p.name ILIKE $1"
+		query += " AND p.name ILIKE $2"
 		args = append(args, "%"+nameFilter+"%")
 	}
 
@@ -41,7 +42,7 @@ func (repo *ProductRepository) GetAll(nameFilter string) ([]models.Product, erro
 	products := make([]models.Product, 0)
 	for rows.Next() {
 		var p models.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.Unit, &p.DecimalQty); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
@@ -49,25 +50,40 @@ func (repo *ProductRepository) GetAll(nameFilter string) ([]models.Product, erro
 	return products, rows.Err()
 }
 
-func (repo *ProductRepository) Create(product *models.Product) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (repo *ProductRepository) Create(ctx context.Context, product *models.Product) error {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	const query = `
-		INSERT INTO products (name, price, stock, category_id) 
-		VALUES ($1, $2, $3, $4) RETURNING id`
-	return repo.pool.QueryRow(ctx, query, product.Name, product.Price, product.Stock, product.CategoryID).Scan(&product.ID)
+		INSERT INTO products (tenant_id, name, price, stock, unit, decimal_qty, plu, category_id, option_axes) 
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9) RETURNING id`
+	err = tx.QueryRow(ctx, query, repo.tenantID, product.Name, product.Price, product.Stock, product.Unit, product.DecimalQty, product.PLU,
+		product.CategoryID, optionAxes(product.OptionAxes)).Scan(&product.ID)
+	if err != nil {
+		return productError(err)
+	}
+	if err := recordPriceChange(ctx, tx, repo.tenantID, product.ID, nil, product.Price, models.PriceSourceCreate, nil, product.ChangedBy); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (repo *ProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
 	const query = `
-		SELECT p.id, p.name, p.price, p.stock, p.category_id, COALESCE(c.name, '') AS category_name
+		SELECT p.id, p.name, p.price, p.stock, p.unit, p.decimal_qty, COALESCE(p.plu, ''),
+		       p.category_id, COALESCE(c.name, '') AS category_name, p.option_axes
         FROM products p
         LEFT JOIN categories c ON c.id = p.category_id
-        WHERE p.id = $1
+        WHERE p.id = $1 AND p.tenant_id = $2
 		`
 	var (
 		p       models.Product
@@ -75,10 +91,10 @@ func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
 		catName string
 	)
 
-	err := repo.pool.QueryRow(ctx, query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &catID, &catName)
+	err := repo.pool.QueryRow(ctx, query, id, repo.tenantID).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.Unit, &p.DecimalQty, &p.PLU, &catID, &catName, &p.OptionAxes)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("product not found")
+			return nil, models.NotFound("product")
 		}
 		return nil, err
 	}
@@ -94,8 +110,24 @@ func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
 	return &p, nil
 }
 
-func (repo *ProductRepository) Update(product *models.Product) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// GetByPLU mencari produk timbangan berdasarkan kode PLU di barcode timbangan.
+func (repo *ProductRepository) GetByPLU(ctx context.Context, plu string) (*models.Product, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var id int
+	err := repo.pool.QueryRow(ctx, `SELECT id FROM products WHERE plu = $1 AND tenant_id = $2`, plu, repo.tenantID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("product")
+		}
+		return nil, err
+	}
+	return repo.GetByID(ctx, id)
+}
+
+func (repo *ProductRepository) Update(ctx context.Context, product *models.Product) error {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
 	// category_id nullable
@@ -106,30 +138,75 @@ func (repo *ProductRepository) Update(product *models.Product) error {
 		cat = pgtype.Int4{Valid: false} // akan ditulis sebagai NULL
 	}
 
-	const query = `UPDATE products 
-				   SET name = $1, price = $2, stock = $3, category_id = $4 
-				   WHERE id = $5`
-	ct, err := repo.pool.Exec(ctx, query, product.Name, product.Price, product.Stock, cat, product.ID)
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
 	}
-	if ct.RowsAffected() == 0 {
-		return errors.New("product not found")
+	defer tx.Rollback(ctx)
+
+	// harga lama dikunci agar riwayat harga tidak tertukar dengan update bersamaan
+	var oldPrice money.Amount
+	err = tx.QueryRow(ctx, `SELECT price FROM products WHERE id = $1 AND tenant_id = $2 FOR UPDATE`,
+		product.ID, repo.tenantID).Scan(&oldPrice)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.NotFound("product")
+		}
+		return err
 	}
-	return nil
+
+	const query = `UPDATE products 
+				   SET name = $1, price = $2, stock = $3, unit = $4, decimal_qty = $5, plu = NULLIF($6, ''),
+				       category_id = $7, option_axes = $8 
+				   WHERE id = $9 AND tenant_id = $10`
+	_, err = tx.Exec(ctx, query, product.Name, product.Price, product.Stock, product.Unit, product.DecimalQty, product.PLU,
+		cat, optionAxes(product.OptionAxes), product.ID, repo.tenantID)
+	if err != nil {
+		return productError(err)
+	}
+	if product.Price != oldPrice {
+		if err := recordPriceChange(ctx, tx, repo.tenantID, product.ID, &oldPrice, product.Price, models.PriceSourceManual, nil, product.ChangedBy); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
 }
 
-func (repo *ProductRepository) Delete(id int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// Delete menolak produk yang sudah pernah terjual (foreign key transaction_details).
+func (repo *ProductRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
 	defer cancel()
 
-	const query = `DELETE FROM products WHERE id = $1`
-	ct, err := repo.pool.Exec(ctx, query, id)
+	const query = `DELETE FROM products WHERE id = $1 AND tenant_id = $2`
+	ct, err := repo.pool.Exec(ctx, query, id, repo.tenantID)
 	if err != nil {
+		if isConstraint(err, "23503", "") {
+			return models.Conflictf("product has transactions and cannot be deleted")
+		}
 		return err
 	}
 	if ct.RowsAffected() == 0 {
-		return errors.New("product not found")
+		return models.NotFound("product")
 	}
 	return nil
 }
+
+// productError memberi pesan yang sama dengan backend SQLite untuk PLU ganda dan
+// kategori yang tidak ada.
+func productError(err error) error {
+	switch {
+	case isConstraint(err, "23505", "plu"):
+		return models.Conflictf("plu already used by another product")
+	case isConstraint(err, "23503", "category_id"):
+		return models.NotFound("category")
+	}
+	return err
+}
+
+// optionAxes memastikan kolom option_axes (NOT NULL) diisi array kosong, bukan NULL
+func optionAxes(axes []string) []string {
+	if axes == nil {
+		return []string{}
+	}
+	return axes
+}
@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReceivableRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewReceivableRepository(pool *pgxpool.Pool, tenantID int) *ReceivableRepository {
+	return &ReceivableRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+const receivableColumns = `id, customer_id, transaction_id, amount, paid_amount, status, created_at, settled_at`
+
+func scanReceivable(row pgx.Row, r *models.Receivable) error {
+	if err := row.Scan(&r.ID, &r.CustomerID, &r.TransactionID, &r.Amount, &r.PaidAmount, &r.Status, &r.CreatedAt, &r.SettledAt); err != nil {
+		return err
+	}
+	r.Outstanding = r.Amount - r.PaidAmount
+	return nil
+}
+
+// GetCredit mengembalikan batas kasbon, sisa piutang, dan daftar piutang yang belum lunas
+// (terlama lebih dulu, sesuai urutan pelunasan).
+func (repo *ReceivableRepository) GetCredit(ctx context.Context, customerID int) (*models.CustomerCredit, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	credit := models.CustomerCredit{CustomerID: customerID, Receivables: make([]models.Receivable, 0)}
+	err := repo.pool.QueryRow(ctx, `SELECT credit_limit FROM customers WHERE id = $1 AND tenant_id = $2`,
+		customerID, repo.tenantID).Scan(&credit.CreditLimit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("customer")
+		}
+		return nil, err
+	}
+
+	rows, err := repo.pool.Query(ctx, `
+		SELECT `+receivableColumns+`
+		FROM receivables
+		WHERE customer_id = $1 AND status = $2 AND tenant_id = $3
+		ORDER BY created_at, id
+	`, customerID, models.ReceivableOpen, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r models.Receivable
+		if err := scanReceivable(rows, &r); err != nil {
+			return nil, err
+		}
+		if credit.Outstanding, err = credit.Outstanding.Add(r.Outstanding); err != nil {
+			return nil, err
+		}
+		credit.Receivables = append(credit.Receivables, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	credit.AvailableCredit = max(credit.CreditLimit-credit.Outstanding, 0)
+	return &credit, nil
+}
+
+// Repay mencatat pembayaran kasbon dan mengalokasikannya ke piutang terlama lebih dulu.
+// Pembayaran melebihi sisa piutang ditolak.
+func (repo *ReceivableRepository) Repay(ctx context.Context, p *models.ReceivablePayment) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	ltx := checkoutTx{tx, repo.tenantID}
+	if _, err := ltx.LockCustomer(ctx, p.CustomerID); err != nil {
+		return err
+	}
+	outstanding, err := ltx.OutstandingCredit(ctx, p.CustomerID)
+	if err != nil {
+		return err
+	}
+	if p.Amount > outstanding {
+		return models.Conflictf("payment %d exceeds outstanding balance %d", p.Amount, outstanding)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO receivable_payments (tenant_id, customer_id, amount, note)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, repo.tenantID, p.CustomerID, p.Amount, p.Note).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, amount - paid_amount
+		FROM receivables
+		WHERE customer_id = $1 AND status = $2 AND tenant_id = $3
+		ORDER BY created_at, id
+	`, p.CustomerID, models.ReceivableOpen, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	p.Allocations = make([]models.ReceivableAllocation, 0)
+	remaining := p.Amount
+	for rows.Next() && remaining > 0 {
+		var a models.ReceivableAllocation
+		var open money.Amount
+		if err := rows.Scan(&a.ReceivableID, &open); err != nil {
+			rows.Close()
+			return err
+		}
+		a.Amount = min(open, remaining)
+		remaining -= a.Amount
+		p.Allocations = append(p.Allocations, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, a := range p.Allocations {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO receivable_allocations (tenant_id, payment_id, receivable_id, amount) VALUES ($1, $2, $3, $4)
+		`, repo.tenantID, p.ID, a.ReceivableID, a.Amount)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE receivables
+			SET paid_amount = paid_amount + $1,
+			    status = CASE WHEN paid_amount + $1 >= amount THEN $2 ELSE status END,
+			    settled_at = CASE WHEN paid_amount + $1 >= amount THEN now() ELSE settled_at END
+			WHERE id = $3 AND tenant_id = $4
+		`, a.Amount, models.ReceivablePaid, a.ReceivableID, repo.tenantID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetAging mengelompokkan sisa piutang per pelanggan menurut umurnya (0–30, 31–60, >60 hari).
+func (repo *ReceivableRepository) GetAging(ctx context.Context) (*models.AgingReport, error) {
+	ctx, cancel := WithReportTimeout(ctx)
+	defer cancel()
+
+	report := models.AgingReport{AsOf: time.Now(), Customers: make([]models.AgingRow, 0)}
+	rows, err := repo.pool.Query(ctx, `
+		SELECT c.id, c.name,
+		       COALESCE(SUM(r.amount - r.paid_amount) FILTER (WHERE r.created_at > $2::timestamptz - interval '31 days'), 0),
+		       COALESCE(SUM(r.amount - r.paid_amount) FILTER (WHERE r.created_at <= $2::timestamptz - interval '31 days'
+		                                                       AND r.created_at > $2::timestamptz - interval '61 days'), 0),
+		       COALESCE(SUM(r.amount - r.paid_amount) FILTER (WHERE r.created_at <= $2::timestamptz - interval '61 days'), 0)
+		FROM receivables r
+		JOIN customers c ON c.id = r.customer_id
+		WHERE r.status = $1 AND r.tenant_id = $3
+		GROUP BY c.id, c.name
+		ORDER BY c.name, c.id
+	`, models.ReceivableOpen, report.AsOf, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row models.AgingRow
+		if err := rows.Scan(&row.CustomerID, &row.CustomerName, &row.Days0To30, &row.Days31To60, &row.Days61Plus); err != nil {
+			return nil, err
+		}
+		if row.Total, err = money.Sum(row.Days0To30, row.Days31To60, row.Days61Plus); err != nil {
+			return nil, err
+		}
+		for _, bucket := range []struct{ total, v *money.Amount }{
+			{&report.Totals.Days0To30, &row.Days0To30},
+			{&report.Totals.Days31To60, &row.Days31To60},
+			{&report.Totals.Days61Plus, &row.Days61Plus},
+			{&report.Totals.Total, &row.Total},
+		} {
+			if *bucket.total, err = bucket.total.Add(*bucket.v); err != nil {
+				return nil, err
+			}
+		}
+		report.Customers = append(report.Customers, row)
+	}
+	return &report, rows.Err()
+}
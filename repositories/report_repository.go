@@ -3,31 +3,32 @@ package repositories
 import (
 	"context"
 	"kasir-api/models"
-	"time"
+	"kasir-api/money"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type ReportRepository struct {
-	pool *pgxpool.Pool
+	pool     pgDB
+	tenantID int
 }
 
-func NewReportRepository(pool *pgxpool.Pool) *ReportRepository {
-	return &ReportRepository{pool: pool}
+func NewReportRepository(pool *pgxpool.Pool, tenantID int) *ReportRepository {
+	return &ReportRepository{pool: pgDB{pool}, tenantID: tenantID}
 }
 
-func (r *ReportRepository) GetTodayReport() (*models.TodayReport, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// GetTodayReport merangkum transaksi hari ini. Jika byVariant true, produk terlaris
+// dipecah per varian; jika false, penjualan varian digabung ke produk induknya.
+func (r *ReportRepository) GetTodayReport(ctx context.Context, byVariant bool) (*models.TodayReport, error) {
+	ctx, cancel := WithReportTimeout(ctx)
 	defer cancel()
 
-	var totalRevenue int
+	var totalRevenue money.Amount
 	if err := r.pool.QueryRow(ctx, `
 		SELECT COALESCE(SUM(total_amount), 0)
 		FROM transactions t
-		JOIN transaction_details d ON d.transaction_id = t.id
-		WHERE t.created_at::date = CURRENT_DATE
-	`).Scan(&totalRevenue); err != nil {
+		WHERE t.tenant_id = $1 AND t.created_at::date = CURRENT_DATE AND t.status = 'completed'
+	`, r.tenantID).Scan(&totalRevenue); err != nil {
 		return nil, err
 	}
 
@@ -35,34 +36,71 @@ func (r *ReportRepository) GetTodayReport() (*models.TodayReport, error) {
 	if err := r.pool.QueryRow(ctx, `
         SELECT COUNT(*)
         FROM transactions t
-        WHERE t.created_at::date = CURRENT_DATE
-    `).Scan(&totalTransaction); err != nil {
+        WHERE t.tenant_id = $1 AND t.created_at::date = CURRENT_DATE AND t.status = 'completed'
+    `, r.tenantID).Scan(&totalTransaction); err != nil {
 		return nil, err
 	}
 
-	var name string
-	var qty int
-	err := r.pool.QueryRow(ctx, `
-        SELECT p.name, SUM(d.quantity) AS qty_sold
+	// dikelompokkan per produk (dan varian) dan satuan jual; jumlah satuan dasar
+	// dijumlahkan per produk oleh BestsellingProducts
+	variantColumns := `NULL::integer, ''`
+	if byVariant {
+		variantColumns = `v.id, COALESCE(v.name, '')`
+	}
+	rows, err := r.pool.Query(ctx, `
+        SELECT p.id, p.name, p.unit, `+variantColumns+`, d.unit, SUM(d.quantity), SUM(d.base_quantity)
         FROM transactions t
         JOIN transaction_details d ON d.transaction_id = t.id
         JOIN products p ON p.id = d.product_id
-        WHERE t.created_at::date = CURRENT_DATE
-        GROUP BY p.name
-        ORDER BY qty_sold DESC
-        LIMIT 1
-    `).Scan(&name, &qty)
-	if err == pgx.ErrNoRows {
-		name, qty = "", 0
-	} else if err != nil {
+        LEFT JOIN product_variants v ON v.id = d.variant_id
+        WHERE t.tenant_id = $1 AND t.created_at::date = CURRENT_DATE AND t.status = 'completed'
+        GROUP BY p.id, 4, 5, d.unit
+    `, r.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sold []SoldLine
+	for rows.Next() {
+		var l SoldLine
+		if err := rows.Scan(&l.ProductID, &l.Name, &l.BaseUnit, &l.VariantID, &l.VariantName, &l.Unit, &l.Quantity, &l.BaseQuantity); err != nil {
+			return nil, err
+		}
+		sold = append(sold, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// nama modifier adalah salinan saat transaksi; yang terbaru dipakai jika pernah diubah
+	rows, err = r.pool.Query(ctx, `
+        SELECT m.modifier_id, (array_agg(m.name ORDER BY m.id DESC))[1], d.unit, SUM(d.quantity), COUNT(*)
+        FROM transactions t
+        JOIN transaction_details d ON d.transaction_id = t.id
+        JOIN transaction_detail_modifiers m ON m.transaction_detail_id = d.id
+        WHERE t.tenant_id = $1 AND t.created_at::date = CURRENT_DATE AND t.status = 'completed'
+        GROUP BY m.modifier_id, d.unit
+    `, r.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var modifiers []SoldModifier
+	for rows.Next() {
+		var m SoldModifier
+		if err := rows.Scan(&m.ModifierID, &m.Name, &m.Unit, &m.Quantity, &m.Lines); err != nil {
+			return nil, err
+		}
+		modifiers = append(modifiers, m)
+	}
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
 	return &models.TodayReport{
-		TotalRevenue:      totalRevenue,
-		TotalTransactions: totalTransaction,
-		BestsellingProducts: []models.BestsellingProduct{
-			{Name: name, QtySold: qty},
-		},
+		TotalRevenue:         money.New(totalRevenue),
+		TotalTransactions:    totalTransaction,
+		BestsellingProducts:  BestsellingProducts(sold, 1),
+		BestsellingModifiers: BestsellingModifiers(modifiers, 5),
 	}, nil
 }
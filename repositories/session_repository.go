@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SessionRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewSessionRepository(pool *pgxpool.Pool, tenantID int) *SessionRepository {
+	return &SessionRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+func (repo *SessionRepository) Create(ctx context.Context, s *models.Session) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	return repo.pool.QueryRow(ctx, `
+		INSERT INTO sessions (tenant_id, user_id, terminal_id, refresh_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, repo.tenantID, s.UserID, s.TerminalID, s.RefreshHash, s.ExpiresAt).Scan(&s.ID, &s.CreatedAt)
+}
+
+// Rotate menukar refresh token lama dengan yang baru. Token lama hanya bisa dipakai sekali;
+// sesi yang dicabut atau kedaluwarsa ditolak.
+func (repo *SessionRepository) Rotate(ctx context.Context, oldHash, newHash string) (*models.Session, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var s models.Session
+	err := repo.pool.QueryRow(ctx, `
+		UPDATE sessions SET refresh_hash = $2
+		WHERE refresh_hash = $1 AND tenant_id = $3 AND revoked_at IS NULL AND expires_at > now()
+		RETURNING id, user_id, terminal_id, refresh_hash, expires_at, revoked_at, created_at
+	`, oldHash, newHash, repo.tenantID).Scan(&s.ID, &s.UserID, &s.TerminalID, &s.RefreshHash, &s.ExpiresAt, &s.RevokedAt, &s.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("session")
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetActiveUser mengembalikan pemilik sesi beserta terminal sesi (nil untuk login password)
+// jika sesi belum dicabut/kedaluwarsa, user-nya masih aktif, dan terminalnya masih aktif.
+// Peran dibaca dari sini (bukan dari token) supaya perubahan peran langsung berlaku.
+func (repo *SessionRepository) GetActiveUser(ctx context.Context, id int) (*models.User, *int, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var u models.User
+	var terminalID *int
+	row := repo.pool.QueryRow(ctx, `
+		SELECT s.terminal_id, `+userColumns+`
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		LEFT JOIN terminals t ON t.id = s.terminal_id
+		WHERE s.id = $1 AND s.tenant_id = $2 AND s.revoked_at IS NULL AND s.expires_at > now() AND u.active
+		  AND (s.terminal_id IS NULL OR t.active)
+	`, id, repo.tenantID)
+	err := scanUser(prefixedRow{row, &terminalID}, &u)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, models.NotFound("session")
+		}
+		return nil, nil, err
+	}
+	return &u, terminalID, nil
+}
+
+// prefixedRow memindai kolom tambahan di depan sebelum kolom yang dibaca scanner lain.
+type prefixedRow struct {
+	row  pgx.Row
+	dest any
+}
+
+func (r prefixedRow) Scan(dest ...any) error {
+	return r.row.Scan(append([]any{r.dest}, dest...)...)
+}
+
+func (repo *SessionRepository) Revoke(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := repo.pool.Exec(ctx, `
+		UPDATE sessions SET revoked_at = now() WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+	`, id, repo.tenantID)
+	return err
+}
+
+// RevokeTerminalSessions mencabut semua sesi yang masih aktif di satu terminal
+// (dipakai saat kasir berganti di terminal yang sama atau terminal dinonaktifkan).
+func (repo *SessionRepository) RevokeTerminalSessions(ctx context.Context, terminalID int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := repo.pool.Exec(ctx, `
+		UPDATE sessions SET revoked_at = now() WHERE terminal_id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+	`, terminalID, repo.tenantID)
+	return err
+}
+
+func revokeUserSessions(ctx context.Context, tx pgx.Tx, tenantID, userID int) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+	`, userID, tenantID)
+	return err
+}
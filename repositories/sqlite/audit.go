@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// AuditRepository hanya menambah dan membaca audit_logs. Tabelnya juga dijaga trigger
+// yang menolak UPDATE/DELETE, jadi log tetap append-only walau diakses di luar API.
+type AuditRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+func NewAuditRepository(db *sql.DB, tenantID int) *AuditRepository {
+	return &AuditRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+const auditColumns = `id, actor_user_id, actor_api_key_id, actor_name, approved_by, action, entity, entity_id,
+	before, after, diff, source_ip, request_id, created_at`
+
+// scanAudit membaca before/after/diff lewat []byte: json.RawMessage tidak bisa menerima
+// TEXT maupun NULL langsung dari driver SQLite.
+func scanAudit(row scanner, a *models.AuditLog) error {
+	var before, after, diff []byte
+	if err := row.Scan(&a.ID, &a.ActorUserID, &a.ActorAPIKeyID, &a.ActorName, &a.ApprovedBy, &a.Action, &a.Entity, &a.EntityID,
+		&before, &after, &diff, &a.SourceIP, &a.RequestID, &a.CreatedAt); err != nil {
+		return err
+	}
+	a.Before, a.After, a.Diff = before, after, diff
+	return nil
+}
+
+// rawJSON menyimpan json.RawMessage kosong sebagai NULL, seperti JSONB di Postgres.
+func rawJSON(m []byte) any {
+	if len(m) == 0 {
+		return nil
+	}
+	return string(m)
+}
+
+// Record menjalankan change lalu menulis audit log yang dikembalikannya dalam satu
+// transaksi database. Perubahan yang dilakukan change lewat repository SQLite mana pun
+// (dengan ctx yang diterimanya) ikut dibatalkan jika change atau penulisan audit gagal.
+func (repo *AuditRepository) Record(ctx context.Context, change func(ctx context.Context) (*models.AuditLog, error)) error {
+	return repo.db.atomic(ctx, func(ctx context.Context) error {
+		a, err := change(ctx)
+		if err != nil {
+			return err
+		}
+		return repo.Create(ctx, a)
+	})
+}
+
+func (repo *AuditRepository) Create(ctx context.Context, a *models.AuditLog) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	a.CreatedAt = now()
+	return repo.db.QueryRowContext(ctx, `
+		INSERT INTO audit_logs (tenant_id, actor_user_id, actor_api_key_id, actor_name, approved_by, action, entity, entity_id,
+		                        before, after, diff, source_ip, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		repo.tenantID, a.ActorUserID, a.ActorAPIKeyID, a.ActorName, a.ApprovedBy, a.Action, a.Entity, a.EntityID,
+		rawJSON(a.Before), rawJSON(a.After), rawJSON(a.Diff), a.SourceIP, a.RequestID, a.CreatedAt).Scan(&a.ID)
+}
+
+// GetAll mengembalikan log terbaru lebih dulu sesuai filter.
+func (repo *AuditRepository) GetAll(ctx context.Context, f models.AuditFilter) ([]models.AuditLog, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + auditColumns + ` FROM audit_logs WHERE tenant_id = ?`
+	args := []any{repo.tenantID}
+	where := func(cond string, v any) {
+		args = append(args, v)
+		query += " AND " + cond
+	}
+	if f.Entity != "" {
+		where("entity = ?", f.Entity)
+	}
+	if f.EntityID != nil {
+		where("entity_id = ?", *f.EntityID)
+	}
+	if f.Action != "" {
+		where("action = ?", f.Action)
+	}
+	if f.ActorUserID != nil {
+		where("actor_user_id = ?", *f.ActorUserID)
+	}
+	if f.RequestID != "" {
+		where("request_id = ?", f.RequestID)
+	}
+	if f.From != nil {
+		where("created_at >= ?", f.From.UTC())
+	}
+	if f.To != nil {
+		where("created_at < ?", f.To.UTC())
+	}
+	args = append(args, f.Limit)
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := make([]models.AuditLog, 0)
+	for rows.Next() {
+		var a models.AuditLog
+		if err := scanAudit(rows, &a); err != nil {
+			return nil, err
+		}
+		logs = append(logs, a)
+	}
+	return logs, rows.Err()
+}
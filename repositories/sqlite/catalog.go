@@ -0,0 +1,310 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories"
+)
+
+type ProductRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+type CategoryRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+var (
+	_ repositories.ProductStore  = (*ProductRepository)(nil)
+	_ repositories.CategoryStore = (*CategoryRepository)(nil)
+)
+
+func NewProductRepository(db *sql.DB, tenantID int) *ProductRepository {
+	return &ProductRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func NewCategoryRepository(db *sql.DB, tenantID int) *CategoryRepository {
+	return &CategoryRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func (repo *ProductRepository) GetAll(ctx context.Context, nameFilter string) ([]models.Product, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, name, price, stock, unit, decimal_qty FROM products WHERE tenant_id = ?`
+	args := []any{repo.tenantID}
+	if nameFilter != "" {
+		// LIKE di SQLite tidak peka huruf besar/kecil untuk ASCII, seperti ILIKE
+		query += ` AND name LIKE ?`
+		args = append(args, "%"+nameFilter+"%")
+	}
+	query += ` ORDER BY id`
+
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		var p models.Product
+		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.Unit, &p.DecimalQty); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+func (repo *ProductRepository) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return getProduct(ctx, repo.db, repo.tenantID, id)
+}
+
+func getProduct(ctx context.Context, q queryer, tenantID, id int) (*models.Product, error) {
+	const query = `
+		SELECT p.id, p.name, p.price, p.stock, p.unit, p.decimal_qty, COALESCE(p.plu, ''),
+		       p.category_id, COALESCE(c.name, ''), p.option_axes
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE p.id = ? AND p.tenant_id = ?`
+	var (
+		p     models.Product
+		catID sql.NullInt64
+		axes  string
+	)
+	err := q.QueryRowContext(ctx, query, id, tenantID).Scan(&p.ID, &p.Name, &p.Price, &p.Stock, &p.Unit, &p.DecimalQty, &p.PLU,
+		&catID, &p.CategoryName, &axes)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("product")
+		}
+		return nil, err
+	}
+	if catID.Valid {
+		v := int(catID.Int64)
+		p.CategoryID = &v
+	}
+	if err := json.Unmarshal([]byte(axes), &p.OptionAxes); err != nil {
+		return nil, err
+	}
+	if len(p.OptionAxes) == 0 {
+		p.OptionAxes = nil
+	}
+	return &p, nil
+}
+
+// GetByPLU mencari produk timbangan berdasarkan kode PLU di barcode timbangan.
+func (repo *ProductRepository) GetByPLU(ctx context.Context, plu string) (*models.Product, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var id int
+	err := repo.db.QueryRowContext(ctx, `SELECT id FROM products WHERE plu = ? AND tenant_id = ?`, plu, repo.tenantID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("product")
+		}
+		return nil, err
+	}
+	return getProduct(ctx, repo.db, repo.tenantID, id)
+}
+
+func (repo *ProductRepository) Create(ctx context.Context, product *models.Product) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	axes, err := optionAxes(product.OptionAxes)
+	if err != nil {
+		return err
+	}
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const query = `
+		INSERT INTO products (tenant_id, name, price, stock, unit, decimal_qty, plu, category_id, option_axes)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?) RETURNING id`
+	err = tx.QueryRowContext(ctx, query, repo.tenantID, product.Name, product.Price, product.Stock, product.Unit, product.DecimalQty,
+		product.PLU, product.CategoryID, axes).Scan(&product.ID)
+	if err != nil {
+		return productError(err)
+	}
+	if err := recordPriceChange(ctx, tx, repo.tenantID, product.ID, nil, product.Price, models.PriceSourceCreate, nil, product.ChangedBy); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (repo *ProductRepository) Update(ctx context.Context, product *models.Product) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	axes, err := optionAxes(product.OptionAxes)
+	if err != nil {
+		return err
+	}
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// BEGIN IMMEDIATE sudah mengunci database, jadi harga lama tidak bisa berubah sebelum commit
+	var oldPrice money.Amount
+	err = tx.QueryRowContext(ctx, `SELECT price FROM products WHERE id = ? AND tenant_id = ?`, product.ID, repo.tenantID).Scan(&oldPrice)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NotFound("product")
+		}
+		return err
+	}
+
+	const query = `
+		UPDATE products
+		SET name = ?, price = ?, stock = ?, unit = ?, decimal_qty = ?, plu = NULLIF(?, ''), category_id = ?, option_axes = ?
+		WHERE id = ? AND tenant_id = ?`
+	_, err = tx.ExecContext(ctx, query, product.Name, product.Price, product.Stock, product.Unit, product.DecimalQty,
+		product.PLU, product.CategoryID, axes, product.ID, repo.tenantID)
+	if err != nil {
+		return productError(err)
+	}
+	if product.Price != oldPrice {
+		if err := recordPriceChange(ctx, tx, repo.tenantID, product.ID, &oldPrice, product.Price, models.PriceSourceManual, nil, product.ChangedBy); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete menolak produk yang sudah pernah terjual (foreign key transaction_details).
+func (repo *ProductRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `DELETE FROM products WHERE id = ? AND tenant_id = ?`, id, repo.tenantID)
+	if err != nil {
+		if isConstraint(err, "FOREIGN KEY", "") {
+			return models.Conflictf("product has transactions and cannot be deleted")
+		}
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return models.NotFound("product")
+	}
+	return nil
+}
+
+// optionAxes menyimpan sumbu varian sebagai array JSON; nil ditulis "[]" seperti di Postgres.
+func optionAxes(axes []string) (string, error) {
+	if axes == nil {
+		axes = []string{}
+	}
+	b, err := json.Marshal(axes)
+	return string(b), err
+}
+
+// productError memberi pesan yang sama dengan backend Postgres dan memory untuk PLU
+// ganda dan kategori yang tidak ada (atau milik tenant lain). Satu-satunya foreign key
+// yang bisa gagal saat menulis produk adalah category_id.
+func productError(err error) error {
+	switch {
+	case isConstraint(err, "UNIQUE", "plu"):
+		return models.Conflictf("plu already used by another product")
+	case isConstraint(err, "FOREIGN KEY", ""):
+		return models.NotFound("category")
+	}
+	return err
+}
+
+func (repo *CategoryRepository) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `SELECT id, name, description FROM categories WHERE tenant_id = ? ORDER BY id`, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.Category
+	for rows.Next() {
+		var c models.Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description); err != nil {
+			return nil, err
+		}
+		items = append(items, c)
+	}
+	return items, rows.Err()
+}
+
+func (repo *CategoryRepository) GetCategoryByID(ctx context.Context, id int) (*models.Category, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var c models.Category
+	err := repo.db.QueryRowContext(ctx, `SELECT id, name, description FROM categories WHERE id = ? AND tenant_id = ?`, id, repo.tenantID).
+		Scan(&c.ID, &c.Name, &c.Description)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("category")
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (repo *CategoryRepository) CreateCategory(ctx context.Context, c *models.Category) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return repo.db.QueryRowContext(ctx, `INSERT INTO categories (tenant_id, name, description) VALUES (?, ?, ?) RETURNING id`,
+		repo.tenantID, c.Name, c.Description).Scan(&c.ID)
+}
+
+func (repo *CategoryRepository) UpdateCategory(ctx context.Context, c *models.Category) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `UPDATE categories SET name = ?, description = ? WHERE id = ? AND tenant_id = ?`,
+		c.Name, c.Description, c.ID, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return models.NotFound("category")
+	}
+	return nil
+}
+
+// DeleteCategory melepas produk dari kategori yang dihapus (ON DELETE SET NULL).
+func (repo *CategoryRepository) DeleteCategory(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `DELETE FROM categories WHERE id = ? AND tenant_id = ?`, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return models.NotFound("category")
+	}
+	return nil
+}
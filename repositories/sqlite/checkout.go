@@ -0,0 +1,240 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories"
+	"time"
+)
+
+// checkoutTx adalah repositories.CheckoutTx di atas transaksi SQLite. Transaksi dimulai
+// dengan BEGIN IMMEDIATE (lihat Open), jadi seluruh database sudah terkunci untuk penulis
+// lain; LockProducts, LockCustomer, dan LockTransaction cukup membaca.
+type checkoutTx struct {
+	tx       *sqlTx
+	tenantID int
+}
+
+var _ repositories.CheckoutTx = checkoutTx{}
+
+func (c checkoutTx) LockProducts(ctx context.Context, ids []int) error {
+	return nil
+}
+
+func (c checkoutTx) Product(ctx context.Context, id int) (*models.Product, error) {
+	return getProduct(ctx, c.tx, c.tenantID, id)
+}
+
+func (c checkoutTx) Variant(ctx context.Context, productID, variantID int) (*models.ProductVariant, error) {
+	v := models.ProductVariant{ID: variantID, ProductID: productID}
+	err := c.tx.QueryRowContext(ctx, `SELECT name, price, stock FROM product_variants WHERE id = ? AND product_id = ? AND tenant_id = ?`,
+		variantID, productID, c.tenantID).Scan(&v.Name, &v.Price, &v.Stock)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("variant")
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (c checkoutTx) SellUnit(ctx context.Context, productID int, name string) (*models.ProductUnit, error) {
+	var u models.ProductUnit
+	err := scanUnit(c.tx.QueryRowContext(ctx, `
+		SELECT `+unitColumns+`
+		FROM product_units
+		WHERE product_id = ? AND name = ? AND sellable AND tenant_id = ?`, productID, name, c.tenantID), &u)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("unit")
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (c checkoutTx) ModifierGroups(ctx context.Context, productID int) ([]models.ModifierGroup, error) {
+	return loadModifierGroups(ctx, c.tx, c.tenantID, applicableGroupsWhere, productID, productID, c.tenantID)
+}
+
+func (c checkoutTx) ListPrice(ctx context.Context, priceListID, productID int, variantID *int, baseQuantity float64) (money.Amount, bool, error) {
+	var price money.Amount
+	err := c.tx.QueryRowContext(ctx, `
+		SELECT price
+		FROM price_list_items
+		WHERE price_list_id = ? AND product_id = ? AND tenant_id = ?
+		  AND (variant_id = ? OR variant_id IS NULL)
+		  AND min_quantity <= ?
+		ORDER BY variant_id IS NULL, min_quantity DESC
+		LIMIT 1`, priceListID, productID, c.tenantID, variantID, baseQuantity).Scan(&price)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return price, true, nil
+}
+
+func (c checkoutTx) LoyaltyRules(ctx context.Context) (*models.LoyaltyRules, error) {
+	return loadLoyaltyRules(ctx, c.tx, c.tenantID)
+}
+
+func (c checkoutTx) OutstandingCredit(ctx context.Context, customerID int) (money.Amount, error) {
+	var outstanding money.Amount
+	err := c.tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount - paid_amount), 0) FROM receivables WHERE customer_id = ? AND status = ? AND tenant_id = ?`,
+		customerID, models.ReceivableOpen, c.tenantID).Scan(&outstanding)
+	return outstanding, err
+}
+
+func (c checkoutTx) LockCustomer(ctx context.Context, customerID int) (*models.Customer, error) {
+	var customer models.Customer
+	err := scanCustomer(c.tx.QueryRowContext(ctx, `SELECT `+customerColumns+` FROM customers WHERE id = ? AND tenant_id = ?`,
+		customerID, c.tenantID), &customer)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("customer")
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (c checkoutTx) LoyaltyLedger(ctx context.Context, customerID int) ([]models.LoyaltyEntry, error) {
+	rows, err := c.tx.QueryContext(ctx, `
+		SELECT id, customer_id, transaction_id, kind, points, expires_at, created_at
+		FROM loyalty_ledger
+		WHERE customer_id = ? AND tenant_id = ?
+		ORDER BY id`, customerID, c.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ledger := make([]models.LoyaltyEntry, 0)
+	for rows.Next() {
+		var e models.LoyaltyEntry
+		if err := rows.Scan(&e.ID, &e.CustomerID, &e.TransactionID, &e.Kind, &e.Points, &e.ExpiresAt, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		ledger = append(ledger, e)
+	}
+	return ledger, rows.Err()
+}
+
+func (c checkoutTx) InsertLedger(ctx context.Context, e *models.LoyaltyEntry) error {
+	e.CreatedAt = now()
+	return c.tx.QueryRowContext(ctx, `
+		INSERT INTO loyalty_ledger (tenant_id, customer_id, transaction_id, kind, points, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		c.tenantID, e.CustomerID, e.TransactionID, e.Kind, e.Points, utc(e.ExpiresAt), e.CreatedAt).Scan(&e.ID)
+}
+
+// AdjustStock membulatkan stok ke 3 desimal seperti kolom NUMERIC(14, 3) di Postgres;
+// trigger stok non-negatif (lihat migrate.go) tetap menjadi jaring pengaman.
+func (c checkoutTx) AdjustStock(ctx context.Context, productID int, variantID *int, delta float64) error {
+	var err error
+	if variantID != nil {
+		_, err = c.tx.ExecContext(ctx, `UPDATE product_variants SET stock = ROUND(stock + ?, 3) WHERE id = ? AND tenant_id = ?`,
+			delta, *variantID, c.tenantID)
+	} else {
+		_, err = c.tx.ExecContext(ctx, `UPDATE products SET stock = ROUND(stock + ?, 3) WHERE id = ? AND tenant_id = ?`,
+			delta, productID, c.tenantID)
+	}
+	return err
+}
+
+func (c checkoutTx) InsertTransaction(ctx context.Context, t *models.Transaction) error {
+	t.CreatedAt = now()
+	return c.tx.QueryRowContext(ctx, `
+		INSERT INTO transactions (tenant_id, status, subtotal, discount_percent, discount, loyalty_discount, tax, total_amount,
+		                          rounding_adjustment, currency, payment_method, customer_id, points_earned,
+		                          points_redeemed, cashier_id, terminal_id, approved_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		c.tenantID, t.Status, t.Subtotal, t.DiscountPercent, t.Discount, t.LoyaltyDiscount, t.Tax, t.TotalAmount.Amount,
+		t.RoundingAdjustment, t.TotalAmount.Currency, t.PaymentMethod, t.CustomerID, t.PointsEarned,
+		t.PointsRedeemed, t.CashierID, t.TerminalID, t.ApprovedBy, t.CreatedAt).Scan(&t.ID)
+}
+
+func (c checkoutTx) InsertDetail(ctx context.Context, d *models.TransactionDetail) error {
+	err := c.tx.QueryRowContext(ctx, `
+		INSERT INTO transaction_details (tenant_id, transaction_id, product_id, variant_id, quantity, unit, base_quantity,
+		                                 price_list_id, unit_price, price_override, subtotal)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		c.tenantID, d.TransactionID, d.ProductID, d.VariantID, d.Quantity, d.Unit, d.BaseQuantity,
+		d.PriceListID, d.UnitPrice, d.PriceOverride, d.Subtotal).Scan(&d.ID)
+	if err != nil {
+		return err
+	}
+	for _, m := range d.Modifiers {
+		_, err := c.tx.ExecContext(ctx, `
+			INSERT INTO transaction_detail_modifiers (tenant_id, transaction_detail_id, modifier_id, name, price_delta)
+			VALUES (?, ?, ?, ?, ?)`, c.tenantID, d.ID, m.ModifierID, m.Name, m.PriceDelta)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c checkoutTx) InsertReceivable(ctx context.Context, r *models.Receivable) error {
+	r.CreatedAt = now()
+	return c.tx.QueryRowContext(ctx, `
+		INSERT INTO receivables (tenant_id, customer_id, transaction_id, amount, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?) RETURNING id`,
+		c.tenantID, r.CustomerID, r.TransactionID, r.Amount, r.Status, r.CreatedAt).Scan(&r.ID)
+}
+
+func (c checkoutTx) LockTransaction(ctx context.Context, id int) (*models.Transaction, error) {
+	t := models.Transaction{ID: id}
+	err := c.tx.QueryRowContext(ctx, `
+		SELECT status, customer_id, points_earned, points_redeemed FROM transactions WHERE id = ? AND tenant_id = ?`, id, c.tenantID).
+		Scan(&t.Status, &t.CustomerID, &t.PointsEarned, &t.PointsRedeemed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("transaction")
+		}
+		return nil, err
+	}
+
+	rows, err := c.tx.QueryContext(ctx, `
+		SELECT product_id, variant_id, base_quantity FROM transaction_details WHERE transaction_id = ? AND tenant_id = ? ORDER BY id`,
+		id, c.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		d := models.TransactionDetail{TransactionID: id}
+		if err := rows.Scan(&d.ProductID, &d.VariantID, &d.BaseQuantity); err != nil {
+			return nil, err
+		}
+		t.Details = append(t.Details, d)
+	}
+	return &t, rows.Err()
+}
+
+func (c checkoutTx) MarkRefunded(ctx context.Context, id int, refundedBy *int) error {
+	_, err := c.tx.ExecContext(ctx, `UPDATE transactions SET status = ?, refunded_at = ?, refunded_by = ? WHERE id = ? AND tenant_id = ?`,
+		models.TransactionRefunded, now(), refundedBy, id, c.tenantID)
+	return err
+}
+
+func (c checkoutTx) VoidReceivables(ctx context.Context, transactionID int) error {
+	_, err := c.tx.ExecContext(ctx, `
+		UPDATE receivables SET status = ?, settled_at = ? WHERE transaction_id = ? AND status = ? AND tenant_id = ?`,
+		models.ReceivableVoid, now(), transactionID, models.ReceivableOpen, c.tenantID)
+	return err
+}
+
+// utc menyimpan waktu dalam UTC, seperti created_at, supaya perbandingan teks di SQLite benar.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
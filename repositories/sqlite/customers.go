@@ -0,0 +1,439 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories"
+	"slices"
+	"time"
+)
+
+type CustomerRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+type LoyaltyRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+type ReceivableRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+func NewCustomerRepository(db *sql.DB, tenantID int) *CustomerRepository {
+	return &CustomerRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func NewLoyaltyRepository(db *sql.DB, tenantID int) *LoyaltyRepository {
+	return &LoyaltyRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func NewReceivableRepository(db *sql.DB, tenantID int) *ReceivableRepository {
+	return &ReceivableRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+const customerColumns = `id, name, COALESCE(phone, ''), COALESCE(email, ''), notes, price_list_id, credit_limit, created_at`
+
+func scanCustomer(row scanner, c *models.Customer) error {
+	return row.Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Notes, &c.PriceListID, &c.CreditLimit, &c.CreatedAt)
+}
+
+// GetAll mengembalikan pelanggan, difilter berdasarkan nama/HP/email jika search diisi.
+func (repo *CustomerRepository) GetAll(ctx context.Context, search string) ([]models.Customer, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + customerColumns + ` FROM customers WHERE tenant_id = ?1`
+	args := []any{repo.tenantID}
+	if search != "" {
+		query += ` AND (name LIKE ?2 OR phone LIKE ?2 OR email LIKE ?2)`
+		args = append(args, "%"+search+"%")
+	}
+	query += ` ORDER BY name, id`
+
+	rows, err := repo.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := make([]models.Customer, 0)
+	for rows.Next() {
+		var c models.Customer
+		if err := scanCustomer(rows, &c); err != nil {
+			return nil, err
+		}
+		customers = append(customers, c)
+	}
+	return customers, rows.Err()
+}
+
+func (repo *CustomerRepository) GetByID(ctx context.Context, id int) (*models.Customer, error) {
+	return repo.getOne(ctx, `id = ?`, id)
+}
+
+func (repo *CustomerRepository) GetByPhone(ctx context.Context, phone string) (*models.Customer, error) {
+	return repo.getOne(ctx, `phone = ?`, phone)
+}
+
+func (repo *CustomerRepository) getOne(ctx context.Context, where string, arg any) (*models.Customer, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var c models.Customer
+	err := scanCustomer(repo.db.QueryRowContext(ctx, `SELECT `+customerColumns+` FROM customers WHERE `+where+` AND tenant_id = ?`,
+		arg, repo.tenantID), &c)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("customer")
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (repo *CustomerRepository) Create(ctx context.Context, c *models.Customer) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	c.CreatedAt = now()
+	return repo.db.QueryRowContext(ctx, `
+		INSERT INTO customers (tenant_id, name, phone, email, notes, price_list_id, credit_limit, created_at)
+		VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?) RETURNING id`,
+		repo.tenantID, c.Name, c.Phone, c.Email, c.Notes, c.PriceListID, c.CreditLimit, c.CreatedAt).Scan(&c.ID)
+}
+
+func (repo *CustomerRepository) Update(ctx context.Context, c *models.Customer) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `
+		UPDATE customers
+		SET name = ?, phone = NULLIF(?, ''), email = NULLIF(?, ''), notes = ?, price_list_id = ?, credit_limit = ?
+		WHERE id = ? AND tenant_id = ?`,
+		c.Name, c.Phone, c.Email, c.Notes, c.PriceListID, c.CreditLimit, c.ID, repo.tenantID)
+	return affected(res, err, "customer")
+}
+
+func (repo *CustomerRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `DELETE FROM customers WHERE id = ? AND tenant_id = ?`, id, repo.tenantID)
+	return affected(res, err, "customer")
+}
+
+// GetSummary: MIN/MAX di SQLite mengembalikan teks, bukan DATETIME, jadi waktu belanja
+// pertama dan terakhir dibaca sebagai kolom biasa.
+func (repo *CustomerRepository) GetSummary(ctx context.Context, id int) (*models.CustomerSummary, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	summary := models.CustomerSummary{CustomerID: id}
+	const where = `WHERE customer_id = ? AND tenant_id = ? AND status = 'completed'`
+	err := repo.db.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(total_amount), 0) FROM transactions `+where,
+		id, repo.tenantID).Scan(&summary.TotalTransactions, &summary.LifetimeValue.Amount)
+	if err != nil || summary.TotalTransactions == 0 {
+		return &summary, err
+	}
+	for _, purchase := range []struct {
+		at    **time.Time
+		order string
+	}{{&summary.FirstPurchaseAt, "ASC"}, {&summary.LastPurchaseAt, "DESC"}} {
+		err := repo.db.QueryRowContext(ctx, `SELECT created_at FROM transactions `+where+` ORDER BY created_at `+purchase.order+` LIMIT 1`,
+			id, repo.tenantID).Scan(purchase.at)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &summary, nil
+}
+
+func (repo *LoyaltyRepository) GetRules(ctx context.Context) (*models.LoyaltyRules, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return loadLoyaltyRules(ctx, repo.db, repo.tenantID)
+}
+
+func (repo *LoyaltyRepository) UpdateRules(ctx context.Context, rules *models.LoyaltyRules) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO loyalty_rules (tenant_id, spend_per_point, point_value, expiry_days)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET spend_per_point = excluded.spend_per_point,
+		    point_value = excluded.point_value,
+		    expiry_days = excluded.expiry_days`,
+		repo.tenantID, rules.SpendPerPoint, rules.PointValue, rules.ExpiryDays)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM loyalty_category_multipliers WHERE tenant_id = ?`, repo.tenantID); err != nil {
+		return err
+	}
+	for _, m := range rules.CategoryMultipliers {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO loyalty_category_multipliers (tenant_id, category_id, multiplier) VALUES (?, ?, ?)`,
+			repo.tenantID, m.CategoryID, m.Multiplier)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetBalance menjalankan kedaluwarsa poin yang sudah jatuh tempo lalu mengembalikan
+// saldo poin pelanggan beserta buku poinnya (terbaru lebih dulu), lewat
+// repositories.ExpirePoints yang sama dengan backend Postgres.
+func (repo *LoyaltyRepository) GetBalance(ctx context.Context, customerID int) (*models.LoyaltyBalance, error) {
+	ctx, cancel := repositories.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ltx := checkoutTx{tx, repo.tenantID}
+	if _, err := ltx.LockCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	points, entries, err := repositories.ExpirePoints(ctx, ltx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(entries)
+
+	rules, err := loadLoyaltyRules(ctx, tx, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	value, err := rules.PointValue.Mul(int64(points))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &models.LoyaltyBalance{CustomerID: customerID, Points: points, Value: value, Entries: entries}, nil
+}
+
+func loadLoyaltyRules(ctx context.Context, q queryer, tenantID int) (*models.LoyaltyRules, error) {
+	rules := models.LoyaltyRules{CategoryMultipliers: make([]models.CategoryMultiplier, 0)}
+	err := q.QueryRowContext(ctx, `SELECT spend_per_point, point_value, expiry_days FROM loyalty_rules WHERE tenant_id = ?`, tenantID).
+		Scan(&rules.SpendPerPoint, &rules.PointValue, &rules.ExpiryDays)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT category_id, multiplier FROM loyalty_category_multipliers WHERE tenant_id = ? ORDER BY category_id`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m models.CategoryMultiplier
+		if err := rows.Scan(&m.CategoryID, &m.Multiplier); err != nil {
+			return nil, err
+		}
+		rules.CategoryMultipliers = append(rules.CategoryMultipliers, m)
+	}
+	return &rules, rows.Err()
+}
+
+const receivableColumns = `id, customer_id, transaction_id, amount, paid_amount, status, created_at, settled_at`
+
+func scanReceivable(row scanner, r *models.Receivable) error {
+	if err := row.Scan(&r.ID, &r.CustomerID, &r.TransactionID, &r.Amount, &r.PaidAmount, &r.Status, &r.CreatedAt, &r.SettledAt); err != nil {
+		return err
+	}
+	r.Outstanding = r.Amount - r.PaidAmount
+	return nil
+}
+
+// GetCredit mengembalikan batas kasbon, sisa piutang, dan daftar piutang yang belum lunas
+// (terlama lebih dulu, sesuai urutan pelunasan).
+func (repo *ReceivableRepository) GetCredit(ctx context.Context, customerID int) (*models.CustomerCredit, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	credit := models.CustomerCredit{CustomerID: customerID, Receivables: make([]models.Receivable, 0)}
+	err := repo.db.QueryRowContext(ctx, `SELECT credit_limit FROM customers WHERE id = ? AND tenant_id = ?`,
+		customerID, repo.tenantID).Scan(&credit.CreditLimit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("customer")
+		}
+		return nil, err
+	}
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT `+receivableColumns+`
+		FROM receivables
+		WHERE customer_id = ? AND status = ? AND tenant_id = ?
+		ORDER BY created_at, id`, customerID, models.ReceivableOpen, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r models.Receivable
+		if err := scanReceivable(rows, &r); err != nil {
+			return nil, err
+		}
+		if credit.Outstanding, err = credit.Outstanding.Add(r.Outstanding); err != nil {
+			return nil, err
+		}
+		credit.Receivables = append(credit.Receivables, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	credit.AvailableCredit = max(credit.CreditLimit-credit.Outstanding, 0)
+	return &credit, nil
+}
+
+// Repay mencatat pembayaran kasbon dan mengalokasikannya ke piutang terlama lebih dulu.
+// Pembayaran melebihi sisa piutang ditolak.
+func (repo *ReceivableRepository) Repay(ctx context.Context, p *models.ReceivablePayment) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ltx := checkoutTx{tx, repo.tenantID}
+	if _, err := ltx.LockCustomer(ctx, p.CustomerID); err != nil {
+		return err
+	}
+	outstanding, err := ltx.OutstandingCredit(ctx, p.CustomerID)
+	if err != nil {
+		return err
+	}
+	if p.Amount > outstanding {
+		return models.Conflictf("payment %d exceeds outstanding balance %d", p.Amount, outstanding)
+	}
+
+	p.CreatedAt = now()
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO receivable_payments (tenant_id, customer_id, amount, note, created_at)
+		VALUES (?, ?, ?, ?, ?) RETURNING id`,
+		repo.tenantID, p.CustomerID, p.Amount, p.Note, p.CreatedAt).Scan(&p.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, amount - paid_amount
+		FROM receivables
+		WHERE customer_id = ? AND status = ? AND tenant_id = ?
+		ORDER BY created_at, id`, p.CustomerID, models.ReceivableOpen, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	p.Allocations = make([]models.ReceivableAllocation, 0)
+	remaining := p.Amount
+	for rows.Next() && remaining > 0 {
+		var a models.ReceivableAllocation
+		var open money.Amount
+		if err := rows.Scan(&a.ReceivableID, &open); err != nil {
+			rows.Close()
+			return err
+		}
+		a.Amount = min(open, remaining)
+		remaining -= a.Amount
+		p.Allocations = append(p.Allocations, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, a := range p.Allocations {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO receivable_allocations (tenant_id, payment_id, receivable_id, amount) VALUES (?, ?, ?, ?)`,
+			repo.tenantID, p.ID, a.ReceivableID, a.Amount)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE receivables
+			SET paid_amount = paid_amount + ?1,
+			    status = CASE WHEN paid_amount + ?1 >= amount THEN ?2 ELSE status END,
+			    settled_at = CASE WHEN paid_amount + ?1 >= amount THEN ?3 ELSE settled_at END
+			WHERE id = ?4 AND tenant_id = ?5`,
+			a.Amount, models.ReceivablePaid, now(), a.ReceivableID, repo.tenantID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAging mengelompokkan sisa piutang per pelanggan menurut umurnya (0–30, 31–60, >60 hari).
+func (repo *ReceivableRepository) GetAging(ctx context.Context) (*models.AgingReport, error) {
+	ctx, cancel := repositories.WithReportTimeout(ctx)
+	defer cancel()
+
+	report := models.AgingReport{AsOf: time.Now(), Customers: make([]models.AgingRow, 0)}
+	days31, days61 := report.AsOf.UTC().AddDate(0, 0, -31), report.AsOf.UTC().AddDate(0, 0, -61)
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT c.id, c.name,
+		       COALESCE(SUM(CASE WHEN r.created_at > ?2 THEN r.amount - r.paid_amount END), 0),
+		       COALESCE(SUM(CASE WHEN r.created_at <= ?2 AND r.created_at > ?3 THEN r.amount - r.paid_amount END), 0),
+		       COALESCE(SUM(CASE WHEN r.created_at <= ?3 THEN r.amount - r.paid_amount END), 0)
+		FROM receivables r
+		JOIN customers c ON c.id = r.customer_id
+		WHERE r.status = ?1 AND r.tenant_id = ?4
+		GROUP BY c.id, c.name
+		ORDER BY c.name, c.id`, models.ReceivableOpen, days31, days61, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row models.AgingRow
+		if err := rows.Scan(&row.CustomerID, &row.CustomerName, &row.Days0To30, &row.Days31To60, &row.Days61Plus); err != nil {
+			return nil, err
+		}
+		if row.Total, err = money.Sum(row.Days0To30, row.Days31To60, row.Days61Plus); err != nil {
+			return nil, err
+		}
+		for _, bucket := range []struct{ total, v *money.Amount }{
+			{&report.Totals.Days0To30, &row.Days0To30},
+			{&report.Totals.Days31To60, &row.Days31To60},
+			{&report.Totals.Days61Plus, &row.Days61Plus},
+			{&report.Totals.Total, &row.Total},
+		} {
+			if *bucket.total, err = bucket.total.Add(*bucket.v); err != nil {
+				return nil, err
+			}
+		}
+		report.Customers = append(report.Customers, row)
+	}
+	return &report, rows.Err()
+}
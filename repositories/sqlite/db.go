@@ -0,0 +1,63 @@
+// Package sqlite adalah backend penyimpanan SQLite untuk instalasi tanpa Postgres (mis.
+// toko offline satu kasir). Isinya semua repository yang dimiliki backend Postgres (lihat
+// interface di repositories/store.go dan NewStores) dalam satu file database, termasuk
+// user, audit log, dan banyak tenant. Skemanya berasal dari migrasi Postgres yang sama
+// (lihat migrate.go).
+//
+// Checkout dan refund memakai repositories.Checkout dan repositories.Refund yang sama
+// dengan backend Postgres (varian, satuan, modifier, daftar harga, loyalti, kasbon);
+// checkoutTx hanya membaca dan menulis barisnya.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// IsDSN melaporkan apakah DB_CONN menunjuk ke file SQLite (sqlite://path atau sqlite:path).
+func IsDSN(conn string) bool {
+	return strings.HasPrefix(conn, "sqlite:")
+}
+
+// Open membuka file SQLite dari DB_CONN. Foreign key dinyalakan, WAL dipakai supaya
+// laporan bisa dibaca selagi checkout menulis, dan setiap transaksi dimulai dengan
+// BEGIN IMMEDIATE: penulis mengunci database sejak awal, jadi stok yang dibaca saat
+// checkout tidak bisa berubah sebelum transaksi selesai.
+func Open(conn string) (*sql.DB, error) {
+	path, ok := strings.CutPrefix(conn, "sqlite://")
+	if !ok {
+		path, _ = strings.CutPrefix(conn, "sqlite:")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("sqlite: DB_CONN %q has no file path", conn)
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_txlock", "immediate")
+	params.Set("_time_format", "sqlite")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	slog.Info("sqlite database opened", "path", path)
+	return db, nil
+}
@@ -0,0 +1,145 @@
+package sqlite_test
+
+import (
+	"database/sql"
+	"kasir-api/models"
+	"kasir-api/repositories/sqlite"
+	"kasir-api/repositories/storetest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// openTestDB membuka file SQLite baru di direktori sementara dengan semua migrasi diterapkan.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open("sqlite://" + filepath.Join(t.TempDir(), "kasir.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := sqlite.MigrateUp(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Stores {
+		stores := sqlite.NewStores(openTestDB(t), models.DefaultTenantID)
+		return storetest.Stores{
+			Products:     stores.Products,
+			Categories:   stores.Categories,
+			Transactions: stores.Transactions,
+			Reports:      stores.Reports,
+		}
+	})
+}
+
+// Semua migrasi harus bisa dibatalkan dan diterapkan ulang.
+func TestMigrateDownUp(t *testing.T) {
+	db := openTestDB(t)
+	states, err := sqlite.MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if reverted, err := sqlite.MigrateDown(db, len(states)); err != nil || len(reverted) != len(states) {
+		t.Fatalf("down: reverted %d of %d, %v", len(reverted), len(states), err)
+	}
+	if applied, err := sqlite.MigrateUp(db); err != nil || len(applied) != len(states) {
+		t.Fatalf("up: applied %d of %d, %v", len(applied), len(states), err)
+	}
+}
+
+// Dua proses yang bermigrasi bersamaan: keduanya harus menunggu kunci tulis sebelum
+// membaca schema_migrations, jadi tidak ada migrasi yang dijalankan dua kali. Proses
+// ketiga memegang kunci tulis dulu supaya kedua runner pasti mulai bersamaan.
+func TestMigrateUpConcurrent(t *testing.T) {
+	path := "sqlite://" + filepath.Join(t.TempDir(), "kasir.db")
+	dbs := make([]*sql.DB, 3)
+	for i := range dbs {
+		db, err := sqlite.Open(path)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		dbs[i] = db
+	}
+
+	_, err := dbs[2].Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at DATETIME NOT NULL)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder, err := dbs[2].Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Rollback()
+
+	applied := make([]int, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i, db := range dbs[:2] {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			migrations, err := sqlite.MigrateUp(db)
+			applied[i], errs[i] = len(migrations), err
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := holder.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("runner %d: %v", i, err)
+		}
+	}
+	states, err := sqlite.MigrationStatus(dbs[0])
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if applied[0]+applied[1] != len(states) {
+		t.Errorf("applied %d + %d migrations, want %d in total", applied[0], applied[1], len(states))
+	}
+}
+
+// Database dari skema lain dengan nomor versi yang sama tidak boleh dianggap sudah termigrasi.
+func TestMigrateRejectsIncompatibleSchema(t *testing.T) {
+	db, err := sqlite.Open("sqlite://" + filepath.Join(t.TempDir(), "old.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`
+		CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at DATETIME NOT NULL);
+		INSERT INTO schema_migrations VALUES (1, 'catalog_transactions', CURRENT_TIMESTAMP);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlite.MigrateUp(db); err == nil {
+		t.Fatal("MigrateUp accepted a database with a different migration 0001")
+	}
+}
+
+// Stok negatif ditolak di level skema, juga untuk tulis yang tidak lewat repository.
+func TestStockMustNotBeNegative(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := db.Exec(`INSERT INTO products (tenant_id, name, price, stock) VALUES (1, 'Teh', 5000, -1)`); err == nil {
+		t.Error("insert with negative stock succeeded")
+	}
+	if _, err := db.Exec(`INSERT INTO products (tenant_id, name, price, stock) VALUES (1, 'Teh', 5000, 2)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`UPDATE products SET stock = stock - 3`); err == nil {
+		t.Error("update to negative stock succeeded")
+	}
+	var stock float64
+	if err := db.QueryRow(`SELECT stock FROM products`).Scan(&stock); err != nil || stock != 2 {
+		t.Errorf("stock = %g, %v; want 2", stock, err)
+	}
+}
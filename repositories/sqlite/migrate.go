@@ -0,0 +1,161 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/database"
+	"regexp"
+)
+
+// sqliteDialect menjalankan migrasi di database/migrations (satu sumber skema dengan
+// Postgres) setelah diterjemahkan ke SQLite.
+type sqliteDialect struct {
+	db *sql.DB
+}
+
+// sqliteRewrites menerjemahkan DDL Postgres yang dipakai di file migrasi. Foreign key
+// komposit (tenant_id, x) tetap komposit. SQLite tidak punya ON DELETE SET NULL (x) dan
+// akan mengosongkan tenant_id juga, jadi foreign key itu dipecah: satu menunjuk id untuk
+// SET NULL, satu lagi tetap komposit tanpa aksi supaya baris tidak bisa menunjuk data
+// tenant lain. Pemeriksaan foreign key dilakukan di akhir statement, setelah x dikosongkan.
+var sqliteRewrites = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?:INTEGER|BIGINT) GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY`), `INTEGER PRIMARY KEY`},
+	{regexp.MustCompile(`\bTIMESTAMPTZ\b`), `DATETIME`},
+	{regexp.MustCompile(`\bnow\(\)`), `CURRENT_TIMESTAMP`},
+	{regexp.MustCompile(`\bJSONB\b`), `TEXT`},
+	{regexp.MustCompile(`TEXT\[\](\s+)NOT NULL DEFAULT '\{\}'`), `TEXT  ${1}NOT NULL DEFAULT '[]'`},
+	{regexp.MustCompile(`FOREIGN KEY \(tenant_id, (\w+)\) REFERENCES (\w+) \(tenant_id, id\) ON DELETE SET NULL \(\w+\)`),
+		`FOREIGN KEY ($1) REFERENCES $2 (id) ON DELETE SET NULL,
+    FOREIGN KEY (tenant_id, $1) REFERENCES $2 (tenant_id, id)`},
+}
+
+func (sqliteDialect) Name() string           { return "sqlite" }
+func (sqliteDialect) Placeholder(int) string { return "?" }
+
+func (sqliteDialect) Translate(query string) string {
+	for _, rw := range sqliteRewrites {
+		query = rw.re.ReplaceAllString(query, rw.repl)
+	}
+	return query
+}
+
+// Lock menjalankan fn di dalam satu transaksi BEGIN IMMEDIATE (lihat Open), termasuk
+// pembacaan schema_migrations-nya: kunci tulis sudah dipegang sebelum Runner melihat
+// migrasi mana yang sudah diterapkan, jadi proses lain menunggu lalu membaca hasil akhirnya.
+// Setiap migrasi berjalan dalam savepoint sendiri; migrasi yang sudah berhasil tetap
+// di-commit walaupun migrasi sesudahnya gagal.
+func (d sqliteDialect) Lock(ctx context.Context, fn func(conn database.MigrationConn) error) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(sqliteConn{tx, true}); err != nil {
+		if cerr := tx.Commit(); cerr != nil {
+			return errors.Join(err, cerr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d sqliteDialect) Conn(ctx context.Context, fn func(conn database.MigrationConn) error) error {
+	return fn(sqliteConn{d.db, false})
+}
+
+// execer dipenuhi *sql.DB maupun *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type sqliteConn struct {
+	db execer
+	// inTx: koneksi milik Lock, sehingga InTx memakai savepoint
+	inTx bool
+}
+
+func (c sqliteConn) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := c.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (c sqliteConn) Query(ctx context.Context, query string, row func(scan func(dest ...any) error) error) error {
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := row(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// InTx: DDL SQLite ikut transaksi, jadi migrasi yang gagal tidak meninggalkan skema setengah jadi.
+func (c sqliteConn) InTx(ctx context.Context, fn func(exec func(query string, args ...any) error) error) error {
+	exec := func(query string, args ...any) error {
+		_, err := c.db.ExecContext(ctx, query, args...)
+		return err
+	}
+	if !c.inTx {
+		db, ok := c.db.(*sql.DB)
+		if !ok {
+			return errors.New("sqlite: InTx needs a database handle")
+		}
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := fn(func(query string, args ...any) error {
+			_, err := tx.ExecContext(ctx, query, args...)
+			return err
+		}); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if err := exec(`SAVEPOINT migration`); err != nil {
+		return err
+	}
+	if err := fn(exec); err != nil {
+		if rerr := exec(`ROLLBACK TO migration; RELEASE migration`); rerr != nil {
+			return errors.Join(err, rerr)
+		}
+		return err
+	}
+	return exec(`RELEASE migration`)
+}
+
+func runner(db *sql.DB) *database.Runner {
+	return database.NewRunner(sqliteDialect{db: db})
+}
+
+// MigrateUp menjalankan semua migrasi yang belum diterapkan.
+func MigrateUp(db *sql.DB) ([]database.Migration, error) {
+	return runner(db).Up()
+}
+
+// MigrateDown membatalkan steps migrasi terakhir yang sudah diterapkan, terbaru lebih dulu.
+func MigrateDown(db *sql.DB, steps int) ([]database.Migration, error) {
+	return runner(db).Down(steps)
+}
+
+// MigrationStatus mengembalikan semua migrasi yang dikenal binary ini.
+func MigrationStatus(db *sql.DB) ([]database.MigrationState, error) {
+	return runner(db).Status()
+}
+
+// PendingMigrations mengembalikan migrasi yang belum diterapkan (untuk /readyz).
+func PendingMigrations(ctx context.Context, db *sql.DB) ([]database.Migration, error) {
+	return runner(db).Pending(ctx)
+}
@@ -0,0 +1,197 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type ModifierRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+func NewModifierRepository(db *sql.DB, tenantID int) *ModifierRepository {
+	return &ModifierRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+// loadModifierGroups membaca grup milik tenant beserta pilihannya. where berisi kondisi
+// tambahan (diawali "AND ...") dengan alias g.
+func loadModifierGroups(ctx context.Context, q queryer, tenantID int, where string, args ...any) ([]models.ModifierGroup, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT g.id, g.name, g.product_id, g.category_id, g.required, g.min_select, g.max_select
+		FROM modifier_groups g WHERE g.tenant_id = ? `+where+` ORDER BY g.id`, append([]any{tenantID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]models.ModifierGroup, 0)
+	index := map[int]int{}
+	for rows.Next() {
+		var g models.ModifierGroup
+		if err := rows.Scan(&g.ID, &g.Name, &g.ProductID, &g.CategoryID, &g.Required, &g.MinSelect, &g.MaxSelect); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		g.Options = make([]models.Modifier, 0)
+		index[g.ID] = len(groups)
+		groups = append(groups, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	ids := make([]any, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+	rows, err = q.QueryContext(ctx, `
+		SELECT id, group_id, name, price_delta FROM modifiers WHERE group_id IN (`+placeholders(len(ids))+`) ORDER BY id`, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m models.Modifier
+		if err := rows.Scan(&m.ID, &m.GroupID, &m.Name, &m.PriceDelta); err != nil {
+			return nil, err
+		}
+		g := &groups[index[m.GroupID]]
+		g.Options = append(g.Options, m)
+	}
+	return groups, rows.Err()
+}
+
+// applicableGroupsWhere memilih grup yang berlaku untuk satu produk, langsung atau lewat
+// kategorinya. Argumennya: product id, product id, tenant id.
+const applicableGroupsWhere = `
+	AND (g.product_id = ?
+	     OR g.category_id = (SELECT category_id FROM products WHERE id = ? AND tenant_id = ?))`
+
+// GetAll mengembalikan semua grup modifier. Jika productID != 0, hanya grup yang berlaku
+// untuk produk tersebut (langsung atau lewat kategorinya).
+func (repo *ModifierRepository) GetAll(ctx context.Context, productID int) ([]models.ModifierGroup, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	if productID == 0 {
+		return loadModifierGroups(ctx, repo.db, repo.tenantID, "")
+	}
+	return loadModifierGroups(ctx, repo.db, repo.tenantID, applicableGroupsWhere, productID, productID, repo.tenantID)
+}
+
+func (repo *ModifierRepository) GetByID(ctx context.Context, id int) (*models.ModifierGroup, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	groups, err := loadModifierGroups(ctx, repo.db, repo.tenantID, "AND g.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, models.NotFound("modifier group")
+	}
+	return &groups[0], nil
+}
+
+func (repo *ModifierRepository) Create(ctx context.Context, g *models.ModifierGroup) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO modifier_groups (tenant_id, name, product_id, category_id, required, min_select, max_select)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		repo.tenantID, g.Name, g.ProductID, g.CategoryID, g.Required, g.MinSelect, g.MaxSelect).Scan(&g.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range g.Options {
+		g.Options[i].GroupID = g.ID
+		if err := insertModifier(ctx, tx, repo.tenantID, &g.Options[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Update menyimpan grup dan menyelaraskan opsinya: opsi dengan id diperbarui,
+// opsi tanpa id ditambahkan, dan opsi lama yang tidak dikirim dihapus.
+func (repo *ModifierRepository) Update(ctx context.Context, g *models.ModifierGroup) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE modifier_groups
+		SET name = ?, product_id = ?, category_id = ?, required = ?, min_select = ?, max_select = ?
+		WHERE id = ? AND tenant_id = ?`,
+		g.Name, g.ProductID, g.CategoryID, g.Required, g.MinSelect, g.MaxSelect, g.ID, repo.tenantID)
+	if err := affected(res, err, "modifier group"); err != nil {
+		return err
+	}
+
+	keep := []any{g.ID, repo.tenantID}
+	for i := range g.Options {
+		m := &g.Options[i]
+		m.GroupID = g.ID
+		if m.ID == 0 {
+			continue
+		}
+		res, err := tx.ExecContext(ctx, `
+			UPDATE modifiers SET name = ?, price_delta = ?
+			WHERE id = ? AND group_id = ? AND tenant_id = ?`, m.Name, m.PriceDelta, m.ID, g.ID, repo.tenantID)
+		if err := affected(res, err, fmt.Sprintf("modifier %d in this group", m.ID)); err != nil {
+			return err
+		}
+		keep = append(keep, m.ID)
+	}
+
+	query := `DELETE FROM modifiers WHERE group_id = ? AND tenant_id = ?`
+	if len(keep) > 2 {
+		query += ` AND id NOT IN (` + placeholders(len(keep)-2) + `)`
+	}
+	if _, err := tx.ExecContext(ctx, query, keep...); err != nil {
+		return err
+	}
+
+	for i := range g.Options {
+		if g.Options[i].ID != 0 {
+			continue
+		}
+		if err := insertModifier(ctx, tx, repo.tenantID, &g.Options[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertModifier(ctx context.Context, tx *sqlTx, tenantID int, m *models.Modifier) error {
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO modifiers (tenant_id, group_id, name, price_delta) VALUES (?, ?, ?, ?) RETURNING id`,
+		tenantID, m.GroupID, m.Name, m.PriceDelta).Scan(&m.ID)
+}
+
+func (repo *ModifierRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `DELETE FROM modifier_groups WHERE id = ? AND tenant_id = ?`, id, repo.tenantID)
+	return affected(res, err, "modifier group")
+}
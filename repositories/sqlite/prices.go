@@ -0,0 +1,329 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories"
+	"time"
+)
+
+type PriceListRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+type PriceHistoryRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+func NewPriceListRepository(db *sql.DB, tenantID int) *PriceListRepository {
+	return &PriceListRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func NewPriceHistoryRepository(db *sql.DB, tenantID int) *PriceHistoryRepository {
+	return &PriceHistoryRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func (repo *PriceListRepository) GetAll(ctx context.Context) ([]models.PriceList, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `SELECT id, code, name FROM price_lists WHERE tenant_id = ? ORDER BY id`, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := make([]models.PriceList, 0)
+	for rows.Next() {
+		var l models.PriceList
+		if err := rows.Scan(&l.ID, &l.Code, &l.Name); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	return lists, rows.Err()
+}
+
+func (repo *PriceListRepository) GetByID(ctx context.Context, id int) (*models.PriceList, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	l, err := repo.getOne(ctx, `id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// variant_id NULL (harga untuk semua varian) terurut lebih dulu, seperti NULLS FIRST di Postgres
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT id, price_list_id, product_id, variant_id, min_quantity, price
+		FROM price_list_items
+		WHERE price_list_id = ? AND tenant_id = ?
+		ORDER BY product_id, variant_id, min_quantity`, id, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	l.Items = make([]models.PriceListItem, 0)
+	for rows.Next() {
+		var item models.PriceListItem
+		if err := rows.Scan(&item.ID, &item.PriceListID, &item.ProductID, &item.VariantID, &item.MinQuantity, &item.Price); err != nil {
+			return nil, err
+		}
+		l.Items = append(l.Items, item)
+	}
+	return l, rows.Err()
+}
+
+func (repo *PriceListRepository) GetByCode(ctx context.Context, code string) (*models.PriceList, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return repo.getOne(ctx, `code = ?`, code)
+}
+
+func (repo *PriceListRepository) getOne(ctx context.Context, where string, arg any) (*models.PriceList, error) {
+	var l models.PriceList
+	err := repo.db.QueryRowContext(ctx, `SELECT id, code, name FROM price_lists WHERE `+where+` AND tenant_id = ?`,
+		arg, repo.tenantID).Scan(&l.ID, &l.Code, &l.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("price list")
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (repo *PriceListRepository) Create(ctx context.Context, l *models.PriceList) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return repo.db.QueryRowContext(ctx, `INSERT INTO price_lists (tenant_id, code, name) VALUES (?, ?, ?) RETURNING id`,
+		repo.tenantID, l.Code, l.Name).Scan(&l.ID)
+}
+
+func (repo *PriceListRepository) Update(ctx context.Context, l *models.PriceList) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `UPDATE price_lists SET code = ?, name = ? WHERE id = ? AND tenant_id = ?`,
+		l.Code, l.Name, l.ID, repo.tenantID)
+	return affected(res, err, "price list")
+}
+
+func (repo *PriceListRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `DELETE FROM price_lists WHERE id = ? AND tenant_id = ?`, id, repo.tenantID)
+	return affected(res, err, "price list")
+}
+
+// ReplaceItems mengganti seluruh harga di daftar harga dalam satu transaksi.
+func (repo *PriceListRepository) ReplaceItems(ctx context.Context, priceListID int, items []models.PriceListItem) error {
+	ctx, cancel := repositories.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM price_list_items WHERE price_list_id = ? AND tenant_id = ?`,
+		priceListID, repo.tenantID); err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].PriceListID = priceListID
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO price_list_items (tenant_id, price_list_id, product_id, variant_id, min_quantity, price)
+			VALUES (?, ?, ?, ?, ?, ?) RETURNING id`,
+			repo.tenantID, priceListID, items[i].ProductID, items[i].VariantID, items[i].MinQuantity, items[i].Price).Scan(&items[i].ID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const scheduledPriceColumns = `id, product_id, price, effective_at, status, created_by, applied_at, created_at`
+
+func scanScheduledPrice(row scanner, sp *models.ScheduledPrice) error {
+	return row.Scan(&sp.ID, &sp.ProductID, &sp.Price, &sp.EffectiveAt, &sp.Status, &sp.CreatedBy, &sp.AppliedAt, &sp.CreatedAt)
+}
+
+// GetHistory mengembalikan riwayat harga produk, terbaru lebih dulu.
+func (repo *PriceHistoryRepository) GetHistory(ctx context.Context, productID int) ([]models.PriceChange, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT id, product_id, old_price, new_price, source, scheduled_price_id, changed_by, changed_at
+		FROM price_history
+		WHERE product_id = ? AND tenant_id = ?
+		ORDER BY changed_at DESC, id DESC`, productID, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]models.PriceChange, 0)
+	for rows.Next() {
+		var c models.PriceChange
+		if err := rows.Scan(&c.ID, &c.ProductID, &c.OldPrice, &c.NewPrice, &c.Source, &c.ScheduledPriceID, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, c)
+	}
+	return history, rows.Err()
+}
+
+// GetScheduled mengembalikan perubahan harga terjadwal produk, yang paling dekat lebih dulu.
+func (repo *PriceHistoryRepository) GetScheduled(ctx context.Context, productID int) ([]models.ScheduledPrice, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT `+scheduledPriceColumns+` FROM scheduled_prices WHERE product_id = ? AND tenant_id = ?
+		ORDER BY effective_at, id`, productID, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scheduled := make([]models.ScheduledPrice, 0)
+	for rows.Next() {
+		var sp models.ScheduledPrice
+		if err := scanScheduledPrice(rows, &sp); err != nil {
+			return nil, err
+		}
+		scheduled = append(scheduled, sp)
+	}
+	return scheduled, rows.Err()
+}
+
+func (repo *PriceHistoryRepository) GetScheduledByID(ctx context.Context, id int) (*models.ScheduledPrice, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var sp models.ScheduledPrice
+	err := scanScheduledPrice(repo.db.QueryRowContext(ctx, `
+		SELECT `+scheduledPriceColumns+` FROM scheduled_prices WHERE id = ? AND tenant_id = ?`, id, repo.tenantID), &sp)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("scheduled price")
+		}
+		return nil, err
+	}
+	return &sp, nil
+}
+
+func (repo *PriceHistoryRepository) Schedule(ctx context.Context, sp *models.ScheduledPrice) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	sp.EffectiveAt, sp.Status, sp.AppliedAt, sp.CreatedAt = sp.EffectiveAt.UTC(), models.ScheduledPricePending, nil, now()
+	return repo.db.QueryRowContext(ctx, `
+		INSERT INTO scheduled_prices (tenant_id, product_id, price, effective_at, status, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		repo.tenantID, sp.ProductID, sp.Price, sp.EffectiveAt, sp.Status, sp.CreatedBy, sp.CreatedAt).Scan(&sp.ID)
+}
+
+// Cancel membatalkan perubahan harga yang belum diterapkan.
+func (repo *PriceHistoryRepository) Cancel(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `
+		UPDATE scheduled_prices SET status = ? WHERE id = ? AND status = ? AND tenant_id = ?`,
+		models.ScheduledPriceCancelled, id, models.ScheduledPricePending, repo.tenantID)
+	return affected(res, err, "pending scheduled price")
+}
+
+// ApplyDue menerapkan semua perubahan harga terjadwal yang sudah jatuh tempo, urut
+// effective_at, dalam satu transaksi. BEGIN IMMEDIATE (lihat Open) membuat proses lain
+// yang menjalankan scheduler menunggu, lalu tidak menemukan jadwal yang sama lagi.
+// Seperti di Postgres, ini satu-satunya query lintas tenant.
+func (repo *PriceHistoryRepository) ApplyDue(ctx context.Context, at time.Time) ([]models.ScheduledPrice, error) {
+	ctx, cancel := repositories.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT tenant_id, `+scheduledPriceColumns+`
+		FROM scheduled_prices
+		WHERE status = ? AND effective_at <= ?
+		ORDER BY effective_at, id`, models.ScheduledPricePending, at.UTC())
+	if err != nil {
+		return nil, err
+	}
+	due := make([]models.ScheduledPrice, 0)
+	tenants := make([]int, 0)
+	for rows.Next() {
+		var sp models.ScheduledPrice
+		var tenantID int
+		if err := scanScheduledPrice(prefixedRow{rows, &tenantID}, &sp); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, sp)
+		tenants = append(tenants, tenantID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range due {
+		sp, tenantID := &due[i], tenants[i]
+		var oldPrice money.Amount
+		err := tx.QueryRowContext(ctx, `SELECT price FROM products WHERE id = ? AND tenant_id = ?`, sp.ProductID, tenantID).Scan(&oldPrice)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE products SET price = ? WHERE id = ? AND tenant_id = ?`, sp.Price, sp.ProductID, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordPriceChange(ctx, tx, tenantID, sp.ProductID, &oldPrice, sp.Price, models.PriceSourceScheduled, &sp.ID, sp.CreatedBy); err != nil {
+			return nil, err
+		}
+		appliedAt := now()
+		_, err = tx.ExecContext(ctx, `UPDATE scheduled_prices SET status = ?, applied_at = ? WHERE id = ? AND tenant_id = ?`,
+			models.ScheduledPriceApplied, appliedAt, sp.ID, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		sp.Status, sp.AppliedAt = models.ScheduledPriceApplied, &appliedAt
+	}
+	return due, tx.Commit()
+}
+
+func recordPriceChange(ctx context.Context, tx *sqlTx, tenantID, productID int, oldPrice *money.Amount, newPrice money.Amount, source string, scheduledID, changedBy *int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO price_history (tenant_id, product_id, old_price, new_price, source, scheduled_price_id, changed_by, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, tenantID, productID, oldPrice, newPrice, source, scheduledID, changedBy, now())
+	return err
+}
+
+// prefixedRow memindai kolom tambahan di depan sebelum kolom yang dibaca scanner lain.
+type prefixedRow struct {
+	row  scanner
+	dest any
+}
+
+func (r prefixedRow) Scan(dest ...any) error {
+	return r.row.Scan(append([]any{r.dest}, dest...)...)
+}
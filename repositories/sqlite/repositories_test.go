@@ -0,0 +1,268 @@
+package sqlite_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories/sqlite"
+	"kasir-api/services"
+	"testing"
+	"time"
+)
+
+// Tenant baru beserta admin, sesi (rotasi refresh token), terminal, dan API key di SQLite.
+func TestAuthRepositories(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	shop := models.Tenant{Slug: "tokoabc", Name: "Toko ABC", Active: true}
+	admin := models.User{Username: "owner", Name: "Owner", Role: "admin", PasswordHash: "x", Active: true}
+	if err := sqlite.NewTenantRepository(db).Create(ctx, &shop, &admin); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := sqlite.NewTenantRepository(db).GetBySlug(ctx, "tokoabc"); err != nil || got.ID != shop.ID {
+		t.Fatalf("GetBySlug = %+v, %v", got, err)
+	}
+	stores := sqlite.NewStores(db, shop.ID)
+	if n, err := stores.Users.Count(ctx); err != nil || n != 1 {
+		t.Fatalf("users in new tenant = %d, %v; want the admin only", n, err)
+	}
+
+	till := models.Terminal{Name: "Kasir 1", Active: true, KeyHash: "terminal-hash", UserIDs: []int{admin.ID}}
+	if err := stores.Terminals.Create(ctx, &till); err != nil {
+		t.Fatal(err)
+	}
+	seen, err := stores.Terminals.GetByKeyHash(ctx, "terminal-hash")
+	if err != nil || seen.LastSeenAt == nil || len(seen.UserIDs) != 1 || seen.UserIDs[0] != admin.ID {
+		t.Fatalf("GetByKeyHash = %+v, %v; want last_seen_at and user_ids [%d]", seen, err, admin.ID)
+	}
+
+	session := models.Session{UserID: admin.ID, TerminalID: &till.ID, RefreshHash: "r1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := stores.Sessions.Create(ctx, &session); err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := stores.Sessions.Rotate(ctx, "r1", "r2")
+	if err != nil || rotated.ID != session.ID || rotated.RefreshHash != "r2" {
+		t.Fatalf("Rotate = %+v, %v", rotated, err)
+	}
+	if _, err := stores.Sessions.Rotate(ctx, "r1", "r3"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("reusing a rotated refresh token: err = %v, want not found", err)
+	}
+	if u, terminalID, err := stores.Sessions.GetActiveUser(ctx, session.ID); err != nil || u.ID != admin.ID || *terminalID != till.ID {
+		t.Fatalf("GetActiveUser = %+v, %v, %v", u, terminalID, err)
+	}
+	till.Active = false
+	if err := stores.Terminals.Update(ctx, &till); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := stores.Sessions.GetActiveUser(ctx, session.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("session on a deactivated terminal: err = %v, want not found", err)
+	}
+
+	key := models.APIKey{Name: "sync", Prefix: "kk_1", Scopes: []string{"products:read"}, RateLimit: 60, KeyHash: "key-hash"}
+	if err := stores.APIKeys.Create(ctx, &key); err != nil {
+		t.Fatal(err)
+	}
+	got, err := stores.APIKeys.GetActiveByHash(ctx, "key-hash")
+	if err != nil || len(got.Scopes) != 1 || got.Scopes[0] != "products:read" {
+		t.Fatalf("GetActiveByHash = %+v, %v", got, err)
+	}
+	if err := stores.APIKeys.Revoke(ctx, key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stores.APIKeys.GetActiveByHash(ctx, "key-hash"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("revoked key: err = %v, want not found", err)
+	}
+}
+
+// Record menulis perubahan dan audit log-nya dalam satu transaksi: jika change gagal,
+// produk yang sudah dibuat di dalamnya ikut dibatalkan.
+func TestAuditRecordIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	stores := sqlite.NewStores(openTestDB(t), models.DefaultTenantID)
+
+	failed := errors.New("audit change failed")
+	err := stores.Audit.Record(ctx, func(ctx context.Context) (*models.AuditLog, error) {
+		if err := stores.Products.Create(ctx, &models.Product{Name: "Teh", Price: 5000, Unit: "pcs"}); err != nil {
+			return nil, err
+		}
+		return nil, failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Record = %v, want %v", err, failed)
+	}
+	if products, err := stores.Products.GetAll(ctx, ""); err != nil || len(products) != 0 {
+		t.Fatalf("products after failed Record = %d, %v; want none", len(products), err)
+	}
+
+	from := time.Now().Add(-time.Minute)
+	tea := models.Product{Name: "Teh", Price: 5000, Unit: "pcs"}
+	err = stores.Audit.Record(ctx, func(ctx context.Context) (*models.AuditLog, error) {
+		if err := stores.Products.Create(ctx, &tea); err != nil {
+			return nil, err
+		}
+		after, _ := json.Marshal(tea)
+		return &models.AuditLog{ActorName: "owner", Action: "create", Entity: "product", EntityID: &tea.ID, After: after}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs, err := stores.Audit.GetAll(ctx, models.AuditFilter{Entity: "product", From: &from, Limit: 10})
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("audit logs = %d, %v; want 1", len(logs), err)
+	}
+	if logs[0].Before != nil || !json.Valid(logs[0].After) || *logs[0].EntityID != tea.ID {
+		t.Errorf("audit log = %+v", logs[0])
+	}
+}
+
+// Kasbon: checkout pay_later menjadi piutang dan poin, pembayaran dialokasikan, dan
+// ringkasan pelanggan membaca waktu belanja pertama/terakhir.
+func TestCustomerCreditAndSummary(t *testing.T) {
+	ctx := context.Background()
+	stores := sqlite.NewStores(openTestDB(t), models.DefaultTenantID)
+
+	budi := models.Customer{Name: "Budi", Phone: "0812", CreditLimit: 100000}
+	if err := stores.Customers.Create(ctx, &budi); err != nil {
+		t.Fatal(err)
+	}
+	tea := models.Product{Name: "Teh", Price: 5000, Stock: 10, Unit: "pcs"}
+	if err := stores.Products.Create(ctx, &tea); err != nil {
+		t.Fatal(err)
+	}
+	if err := stores.Loyalty.UpdateRules(ctx, &models.LoyaltyRules{SpendPerPoint: 1000, PointValue: 100, ExpiryDays: 30}); err != nil {
+		t.Fatal(err)
+	}
+	svc := services.NewTransactionService(stores.Transactions, stores.Products, stores.Units, stores.Customers, stores.PriceLists, 10)
+	_, err := svc.Checkout(ctx, &models.CheckoutRequest{
+		Items:         []models.CheckoutItem{{ProductID: tea.ID, Quantity: 4}},
+		CustomerPhone: "0812",
+		PaymentMethod: models.PaymentPayLater,
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := stores.Customers.GetSummary(ctx, budi.ID)
+	if err != nil || summary.TotalTransactions != 1 || summary.LifetimeValue.Amount != 20000 || summary.FirstPurchaseAt == nil {
+		t.Fatalf("summary = %+v, %v", summary, err)
+	}
+
+	balance, err := stores.Loyalty.GetBalance(ctx, budi.ID)
+	if err != nil || balance.Points != 20 || balance.Value != 2000 || len(balance.Entries) != 1 {
+		t.Fatalf("loyalty balance = %+v, %v; want 20 points", balance, err)
+	}
+
+	payment := models.ReceivablePayment{CustomerID: budi.ID, Amount: 15000}
+	if err := stores.Receivables.Repay(ctx, &payment); err != nil {
+		t.Fatal(err)
+	}
+	credit, err := stores.Receivables.GetCredit(ctx, budi.ID)
+	if err != nil || credit.Outstanding != 5000 || credit.AvailableCredit != 95000 {
+		t.Fatalf("credit = %+v, %v; want 5000 outstanding", credit, err)
+	}
+	if err := stores.Receivables.Repay(ctx, &models.ReceivablePayment{CustomerID: budi.ID, Amount: 6000}); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("overpayment: err = %v, want conflict", err)
+	}
+	aging, err := stores.Receivables.GetAging(ctx)
+	if err != nil || len(aging.Customers) != 1 || aging.Totals.Days0To30 != 5000 {
+		t.Fatalf("aging = %+v, %v", aging, err)
+	}
+}
+
+// Padanan TestTenantIsolation milik Postgres yang selalu jalan: repository yang terikat ke
+// satu tenant tidak boleh melihat atau mengubah data tenant lain, walaupun ID-nya diketahui.
+func TestTenantIsolation(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	shop := models.Tenant{Slug: "tokob", Name: "Toko B", Active: true}
+	admin := models.User{Username: "owner", Name: "Owner", Role: "admin", PasswordHash: "x", Active: true}
+	if err := sqlite.NewTenantRepository(db).Create(ctx, &shop, &admin); err != nil {
+		t.Fatal(err)
+	}
+	a, b := sqlite.NewStores(db, models.DefaultTenantID), sqlite.NewStores(db, shop.ID)
+
+	categoryA := models.Category{Name: "Minuman"}
+	if err := a.Categories.CreateCategory(ctx, &categoryA); err != nil {
+		t.Fatal(err)
+	}
+	productA := models.Product{Name: "Teh", Price: 5000, Stock: 10, Unit: "pcs", PLU: "100"}
+	if err := a.Products.Create(ctx, &productA); err != nil {
+		t.Fatal(err)
+	}
+	customerA := models.Customer{Name: "Budi", Phone: "0812"}
+	if err := a.Customers.Create(ctx, &customerA); err != nil {
+		t.Fatal(err)
+	}
+	txA, err := a.Transactions.CreateTransaction(ctx, &models.CheckoutRequest{
+		Items: []models.CheckoutItem{{ProductID: productA.ID, Quantity: 1}}, PaymentMethod: models.PaymentCash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if all, err := b.Products.GetAll(ctx, ""); err != nil || len(all) != 0 {
+		t.Errorf("tenant b sees products %+v, %v", all, err)
+	}
+	if _, err := b.Products.GetByID(ctx, productA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetByID across tenants: err = %v, want ErrNotFound", err)
+	}
+	if _, err := b.Products.GetByPLU(ctx, "100"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetByPLU across tenants: err = %v, want ErrNotFound", err)
+	}
+	stolen := productA
+	stolen.Price = 1
+	if err := b.Products.Update(ctx, &stolen); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Update across tenants: err = %v, want ErrNotFound", err)
+	}
+	if err := b.Products.Delete(ctx, productA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Delete across tenants: err = %v, want ErrNotFound", err)
+	}
+	if _, err := b.Categories.GetCategoryByID(ctx, categoryA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetCategoryByID across tenants: err = %v, want ErrNotFound", err)
+	}
+	// foreign key komposit menolak produk tenant b yang menunjuk kategori tenant a
+	crossCategory := models.Product{Name: "Kopi", Price: 1, Unit: "pcs", CategoryID: &categoryA.ID}
+	if err := b.Products.Create(ctx, &crossCategory); err == nil {
+		t.Error("tenant b created a product in tenant a's category")
+	}
+
+	if all, err := b.Customers.GetAll(ctx, ""); err != nil || len(all) != 0 {
+		t.Errorf("tenant b sees customers %+v, %v", all, err)
+	}
+	if _, err := b.Customers.GetByID(ctx, customerA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("customer GetByID across tenants: err = %v, want ErrNotFound", err)
+	}
+	renamed := customerA
+	renamed.Name = "Mallory"
+	if err := b.Customers.Update(ctx, &renamed); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("customer Update across tenants: err = %v, want ErrNotFound", err)
+	}
+	if err := b.Customers.Delete(ctx, customerA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("customer Delete across tenants: err = %v, want ErrNotFound", err)
+	}
+
+	if _, err := b.Transactions.GetByID(ctx, txA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("transaction GetByID across tenants: err = %v, want ErrNotFound", err)
+	}
+	if err := b.Transactions.Refund(ctx, txA.ID, nil); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Refund across tenants: err = %v, want ErrNotFound", err)
+	}
+	_, err = b.Transactions.CreateTransaction(ctx, &models.CheckoutRequest{
+		Items: []models.CheckoutItem{{ProductID: productA.ID, Quantity: 1}}, PaymentMethod: models.PaymentCash})
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("checkout of tenant a's product by tenant b: err = %v, want ErrNotFound", err)
+	}
+	if report, err := b.Reports.GetTodayReport(ctx, false); err != nil || report.TotalTransactions != 0 {
+		t.Errorf("tenant b report = %+v, %v; want no transactions", report, err)
+	}
+
+	p, err := a.Products.GetByID(ctx, productA.ID)
+	if err != nil || p.Price != 5000 || p.Stock != 9 {
+		t.Errorf("tenant a product = %+v, %v; want untouched (price 5000, stock 9)", p, err)
+	}
+	if c, err := a.Customers.GetByID(ctx, customerA.ID); err != nil || c.Name != "Budi" {
+		t.Errorf("tenant a customer = %+v, %v; want untouched", c, err)
+	}
+}
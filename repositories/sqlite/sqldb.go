@@ -0,0 +1,227 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+	"strings"
+	"time"
+)
+
+// sqlDB membungkus *sql.DB seperti pgDB di backend Postgres: error SQLite dari setiap
+// query sudah diterjemahkan ke error domain sebelum keluar dari repository.
+//
+// Jika ctx membawa transaksi dari atomic, semua query ikut transaksi itu dan BeginTx
+// membuka savepoint di dalamnya. Ini wajib di SQLite: transaksi kedua dari koneksi lain
+// akan menunggu kunci tulis yang dipegang transaksi pertama sampai busy_timeout habis.
+type sqlDB struct {
+	*sql.DB
+}
+
+type txKey struct{}
+
+// ambient mengembalikan transaksi atomic yang dibawa ctx, jika ada.
+func ambient(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// atomic menjalankan fn dalam satu transaksi; repository SQLite yang dipanggil dengan
+// ctx milik fn menulis ke transaksi yang sama. Error dari fn membatalkan semuanya.
+func (db sqlDB) atomic(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx.tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// conn dipenuhi *sql.DB maupun *sql.Tx.
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (db sqlDB) conn(ctx context.Context) conn {
+	if tx, ok := ambient(ctx); ok {
+		return tx
+	}
+	return db.DB
+}
+
+func (db sqlDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return execContext(ctx, db.conn(ctx), query, args...)
+}
+
+func (db sqlDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return queryContext(ctx, db.conn(ctx), query, args...)
+}
+
+func (db sqlDB) QueryRowContext(ctx context.Context, query string, args ...any) row {
+	return row{db.conn(ctx).QueryRowContext(ctx, query, args...)}
+}
+
+// BeginTx memulai transaksi BEGIN IMMEDIATE (lihat Open); di dalam atomic, savepoint.
+func (db sqlDB) BeginTx(ctx context.Context) (*sqlTx, error) {
+	if outer, ok := ambient(ctx); ok {
+		if _, err := outer.ExecContext(ctx, `SAVEPOINT nested`); err != nil {
+			return nil, sqlError(err)
+		}
+		return &sqlTx{tx: outer, savepoint: true}, nil
+	}
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, sqlError(err)
+	}
+	return &sqlTx{tx: tx}, nil
+}
+
+type sqlTx struct {
+	tx        *sql.Tx
+	savepoint bool
+	done      bool
+}
+
+func (t *sqlTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return execContext(ctx, t.tx, query, args...)
+}
+
+func (t *sqlTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return queryContext(ctx, t.tx, query, args...)
+}
+
+func (t *sqlTx) QueryRowContext(ctx context.Context, query string, args ...any) row {
+	return row{t.tx.QueryRowContext(ctx, query, args...)}
+}
+
+// Commit ikut diterjemahkan: foreign key SQLite diperiksa per statement, tetapi kunci
+// tulis yang gagal didapat baru terlihat di sini.
+func (t *sqlTx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if t.savepoint {
+		_, err := t.tx.Exec(`RELEASE nested`)
+		return sqlError(err)
+	}
+	return sqlError(t.tx.Commit())
+}
+
+// Rollback setelah Commit tidak melakukan apa-apa, sehingga aman dipakai dengan defer.
+func (t *sqlTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	if t.savepoint {
+		_, err := t.tx.Exec(`ROLLBACK TO nested; RELEASE nested`)
+		return err
+	}
+	return t.tx.Rollback()
+}
+
+func execContext(ctx context.Context, c conn, query string, args ...any) (sql.Result, error) {
+	res, err := c.ExecContext(ctx, query, args...)
+	return res, sqlError(err)
+}
+
+func queryContext(ctx context.Context, c conn, query string, args ...any) (*sql.Rows, error) {
+	rows, err := c.QueryContext(ctx, query, args...)
+	return rows, sqlError(err)
+}
+
+type row struct {
+	*sql.Row
+}
+
+func (r row) Scan(dest ...any) error {
+	return sqlError(r.Row.Scan(dest...))
+}
+
+// queryer dipenuhi sqlDB maupun *sqlTx, sehingga query baca yang sama bisa dipakai di
+// luar maupun di dalam transaksi checkout.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) row
+}
+
+// constraintError adalah error SQLite yang sudah diberi jenis domain; error aslinya tetap
+// terbungkus agar repository bisa memilih pesan yang lebih spesifik (lihat isConstraint).
+type constraintError struct {
+	kind    error
+	message string
+	cause   error
+}
+
+func (e *constraintError) Error() string { return e.message }
+
+func (e *constraintError) Unwrap() []error { return []error{e.kind, e.cause} }
+
+// errNoRows tetap cocok dengan sql.ErrNoRows untuk pemanggil yang memeriksanya, dan
+// menjadi models.ErrNotFound bila lolos tanpa diberi pesan yang lebih spesifik.
+var errNoRows = &constraintError{models.ErrNotFound, "resource not found", sql.ErrNoRows}
+
+// sqlError menerjemahkan pelanggaran batasan skema yang lolos dari validasi service,
+// dengan pesan yang sama dengan backend Postgres. Pesan SQLite (nama tabel, kolom) tidak
+// diteruskan ke klien. Error lain dikembalikan apa adanya.
+func sqlError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return errNoRows
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed"):
+		return &constraintError{models.ErrConflict, "a record with the same unique value already exists", err}
+	case strings.Contains(msg, "FOREIGN KEY constraint failed"):
+		return &constraintError{models.ErrConflict, "the record is referenced by other data or refers to a record that does not exist", err}
+	case strings.Contains(msg, "CHECK constraint failed"), strings.Contains(msg, "NOT NULL constraint failed"):
+		return &constraintError{models.ErrValidation, "a value is missing or out of the allowed range", err}
+	}
+	return err
+}
+
+// isConstraint mencocokkan pesan error SQLite (mis. "UNIQUE constraint failed:
+// products.tenant_id, products.plu"); jika column diisi, hanya constraint yang menyebut
+// kolom itu. Pelanggaran FOREIGN KEY tidak menyebut kolom, jadi column harus kosong.
+func isConstraint(err error, kind, column string) bool {
+	var ce *constraintError
+	if !errors.As(err, &ce) {
+		return false
+	}
+	msg := ce.cause.Error()
+	return strings.Contains(msg, kind+" constraint failed") && (column == "" || strings.Contains(msg, "."+column))
+}
+
+// now adalah waktu tulis dalam UTC; semua kolom waktu disimpan dalam UTC supaya
+// perbandingan teks di SQLite sama dengan urutan waktunya.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// affected meneruskan err dari Exec, atau models.NotFound(what) jika statement tidak
+// mengenai satu baris pun (id tidak ada atau milik tenant lain).
+func affected(res sql.Result, err error, what string) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.NotFound(what)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"database/sql"
+	"kasir-api/repositories"
+)
+
+var (
+	_ repositories.TenantStore       = (*TenantRepository)(nil)
+	_ repositories.UserStore         = (*UserRepository)(nil)
+	_ repositories.SessionStore      = (*SessionRepository)(nil)
+	_ repositories.TerminalStore     = (*TerminalRepository)(nil)
+	_ repositories.APIKeyStore       = (*APIKeyRepository)(nil)
+	_ repositories.AuditStore        = (*AuditRepository)(nil)
+	_ repositories.VariantStore      = (*VariantRepository)(nil)
+	_ repositories.UnitStore         = (*UnitRepository)(nil)
+	_ repositories.ModifierStore     = (*ModifierRepository)(nil)
+	_ repositories.CustomerStore     = (*CustomerRepository)(nil)
+	_ repositories.LoyaltyStore      = (*LoyaltyRepository)(nil)
+	_ repositories.ReceivableStore   = (*ReceivableRepository)(nil)
+	_ repositories.PriceListStore    = (*PriceListRepository)(nil)
+	_ repositories.PriceHistoryStore = (*PriceHistoryRepository)(nil)
+)
+
+// NewStores merakit semua repository SQLite untuk satu tenant, padanan
+// repositories.NewStores.
+func NewStores(db *sql.DB, tenantID int) repositories.Stores {
+	return repositories.Stores{
+		Users:        NewUserRepository(db, tenantID),
+		Sessions:     NewSessionRepository(db, tenantID),
+		Terminals:    NewTerminalRepository(db, tenantID),
+		APIKeys:      NewAPIKeyRepository(db, tenantID),
+		Audit:        NewAuditRepository(db, tenantID),
+		Products:     NewProductRepository(db, tenantID),
+		Categories:   NewCategoryRepository(db, tenantID),
+		Variants:     NewVariantRepository(db, tenantID),
+		Units:        NewUnitRepository(db, tenantID),
+		Modifiers:    NewModifierRepository(db, tenantID),
+		Customers:    NewCustomerRepository(db, tenantID),
+		Loyalty:      NewLoyaltyRepository(db, tenantID),
+		Receivables:  NewReceivableRepository(db, tenantID),
+		PriceLists:   NewPriceListRepository(db, tenantID),
+		PriceHistory: NewPriceHistoryRepository(db, tenantID),
+		Transactions: NewTransactionRepository(db, tenantID),
+		Reports:      NewReportRepository(db, tenantID),
+	}
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// TenantRepository adalah satu-satunya repository yang tidak terikat ke satu tenant:
+// dipakai untuk mengelola toko dan menentukan tenant dari kunci pada request.
+type TenantRepository struct {
+	db sqlDB
+}
+
+func NewTenantRepository(db *sql.DB) *TenantRepository {
+	return &TenantRepository{db: sqlDB{db}}
+}
+
+const tenantColumns = `id, slug, name, active, created_at`
+
+func scanTenant(row scanner, t *models.Tenant) error {
+	return row.Scan(&t.ID, &t.Slug, &t.Name, &t.Active, &t.CreatedAt)
+}
+
+func (repo *TenantRepository) GetAll(ctx context.Context) ([]models.Tenant, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `SELECT `+tenantColumns+` FROM tenants ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := make([]models.Tenant, 0)
+	for rows.Next() {
+		var t models.Tenant
+		if err := scanTenant(rows, &t); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, rows.Err()
+}
+
+func (repo *TenantRepository) GetByID(ctx context.Context, id int) (*models.Tenant, error) {
+	return repo.getOne(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE id = ?`, id)
+}
+
+func (repo *TenantRepository) GetBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	return repo.getOne(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE slug = ?`, slug)
+}
+
+// GetByAPIKeyHash mencari pemilik kunci API; keabsahan kunci tetap diperiksa oleh
+// APIKeyRepository milik tenant tersebut.
+func (repo *TenantRepository) GetByAPIKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error) {
+	return repo.getOne(ctx, `
+		SELECT `+tenantColumns+` FROM tenants WHERE id = (SELECT tenant_id FROM api_keys WHERE key_hash = ?)`, keyHash)
+}
+
+// GetByTerminalKeyHash mencari pemilik kunci terminal (login PIN).
+func (repo *TenantRepository) GetByTerminalKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error) {
+	return repo.getOne(ctx, `
+		SELECT `+tenantColumns+` FROM tenants WHERE id = (SELECT tenant_id FROM terminals WHERE key_hash = ?)`, keyHash)
+}
+
+func (repo *TenantRepository) getOne(ctx context.Context, query string, arg any) (*models.Tenant, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var t models.Tenant
+	if err := scanTenant(repo.db.QueryRowContext(ctx, query, arg), &t); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("tenant")
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Create membuat tenant dan user admin pertamanya dalam satu transaksi, supaya tidak
+// ada toko yang tidak bisa dipakai login.
+func (repo *TenantRepository) Create(ctx context.Context, t *models.Tenant, admin *models.User) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	t.CreatedAt = now()
+	err = tx.QueryRowContext(ctx, `INSERT INTO tenants (slug, name, active, created_at) VALUES (?, ?, ?, ?) RETURNING id`,
+		t.Slug, t.Name, t.Active, t.CreatedAt).Scan(&t.ID)
+	if err != nil {
+		return err
+	}
+	admin.CreatedAt = t.CreatedAt
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users (tenant_id, username, name, role, password_hash, active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		t.ID, admin.Username, admin.Name, admin.Role, admin.PasswordHash, admin.Active, admin.CreatedAt).Scan(&admin.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,315 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"time"
+)
+
+type TerminalRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+type APIKeyRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+func NewTerminalRepository(db *sql.DB, tenantID int) *TerminalRepository {
+	return &TerminalRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func NewAPIKeyRepository(db *sql.DB, tenantID int) *APIKeyRepository {
+	return &APIKeyRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+// terminalColumns membaca user_ids sebagai array JSON (array_agg di Postgres).
+const terminalColumns = `t.id, t.name, t.active, t.key_hash, t.last_seen_at, t.created_at,
+	COALESCE((SELECT json_group_array(user_id) FROM (SELECT tu.user_id FROM terminal_users tu
+	          WHERE tu.terminal_id = t.id AND tu.tenant_id = t.tenant_id ORDER BY tu.user_id)), '[]')`
+
+func scanTerminal(row scanner, t *models.Terminal) error {
+	var userIDs string
+	if err := row.Scan(&t.ID, &t.Name, &t.Active, &t.KeyHash, &t.LastSeenAt, &t.CreatedAt, &userIDs); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(userIDs), &t.UserIDs)
+}
+
+func (repo *TerminalRepository) GetAll(ctx context.Context) ([]models.Terminal, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT `+terminalColumns+` FROM terminals t WHERE t.tenant_id = ? ORDER BY t.name, t.id`, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terminals := make([]models.Terminal, 0)
+	for rows.Next() {
+		var t models.Terminal
+		if err := scanTerminal(rows, &t); err != nil {
+			return nil, err
+		}
+		terminals = append(terminals, t)
+	}
+	return terminals, rows.Err()
+}
+
+func (repo *TerminalRepository) GetByID(ctx context.Context, id int) (*models.Terminal, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return getTerminal(ctx, repo.db, `t.id = ?`, id, repo.tenantID)
+}
+
+// GetByKeyHash mencari terminal aktif dari hash kunci terminal dan mencatat kapan terakhir dipakai.
+func (repo *TerminalRepository) GetByKeyHash(ctx context.Context, keyHash string) (*models.Terminal, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE terminals SET last_seen_at = ? WHERE key_hash = ? AND tenant_id = ? AND active`,
+		now(), keyHash, repo.tenantID)
+	if err := affected(res, err, "terminal"); err != nil {
+		return nil, err
+	}
+	t, err := getTerminal(ctx, tx, `t.key_hash = ?`, keyHash, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return t, tx.Commit()
+}
+
+func getTerminal(ctx context.Context, q queryer, where string, arg any, tenantID int) (*models.Terminal, error) {
+	var t models.Terminal
+	err := scanTerminal(q.QueryRowContext(ctx, `
+		SELECT `+terminalColumns+` FROM terminals t WHERE `+where+` AND t.tenant_id = ?`, arg, tenantID), &t)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("terminal")
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (repo *TerminalRepository) Create(ctx context.Context, t *models.Terminal) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	t.CreatedAt = now()
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO terminals (tenant_id, name, key_hash, active, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id`,
+		repo.tenantID, t.Name, t.KeyHash, t.Active, t.CreatedAt).Scan(&t.ID)
+	if err != nil {
+		return err
+	}
+	if err := replaceTerminalUsers(ctx, tx, repo.tenantID, t.ID, t.UserIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Update menyimpan nama, status aktif, dan daftar user yang boleh login di terminal.
+// Terminal yang dinonaktifkan langsung kehilangan semua sesinya.
+func (repo *TerminalRepository) Update(ctx context.Context, t *models.Terminal) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE terminals SET name = ?, active = ? WHERE id = ? AND tenant_id = ?`,
+		t.Name, t.Active, t.ID, repo.tenantID)
+	if err := affected(res, err, "terminal"); err != nil {
+		return err
+	}
+	if err := replaceTerminalUsers(ctx, tx, repo.tenantID, t.ID, t.UserIDs); err != nil {
+		return err
+	}
+	if !t.Active {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE sessions SET revoked_at = ? WHERE terminal_id = ? AND tenant_id = ? AND revoked_at IS NULL`,
+			now(), t.ID, repo.tenantID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (repo *TerminalRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `DELETE FROM terminals WHERE id = ? AND tenant_id = ?`, id, repo.tenantID)
+	return affected(res, err, "terminal")
+}
+
+func replaceTerminalUsers(ctx context.Context, tx *sqlTx, tenantID, terminalID int, userIDs []int) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM terminal_users WHERE terminal_id = ? AND tenant_id = ?`, terminalID, tenantID); err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		_, err := tx.ExecContext(ctx, `INSERT INTO terminal_users (tenant_id, terminal_id, user_id) VALUES (?, ?, ?)`,
+			tenantID, terminalID, userID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const apiKeyColumns = `id, name, prefix, scopes, rate_limit, key_hash, created_by, last_used_at, revoked_at, created_at`
+
+// scanAPIKey membaca scopes yang disimpan sebagai array JSON (TEXT[] di Postgres).
+func scanAPIKey(row scanner, k *models.APIKey) error {
+	var scopes string
+	if err := row.Scan(&k.ID, &k.Name, &k.Prefix, &scopes, &k.RateLimit, &k.KeyHash, &k.CreatedBy, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(scopes), &k.Scopes)
+}
+
+func apiKeyScopes(scopes []string) (string, error) {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	b, err := json.Marshal(scopes)
+	return string(b), err
+}
+
+func (repo *APIKeyRepository) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+` FROM api_keys WHERE tenant_id = ? ORDER BY created_at DESC, id DESC`, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		var k models.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (repo *APIKeyRepository) GetByID(ctx context.Context, id int) (*models.APIKey, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var k models.APIKey
+	err := scanAPIKey(repo.db.QueryRowContext(ctx, `
+		SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ? AND tenant_id = ?`, id, repo.tenantID), &k)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("api key")
+		}
+		return nil, err
+	}
+	return &k, nil
+}
+
+// GetActiveByHash mencari kunci yang belum dicabut dan mencatat pemakaiannya. last_used_at
+// paling sering diperbarui sekali per menit agar tidak menulis di setiap request.
+func (repo *APIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var k models.APIKey
+	err := scanAPIKey(repo.db.QueryRowContext(ctx, `
+		SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ? AND tenant_id = ? AND revoked_at IS NULL`,
+		keyHash, repo.tenantID), &k)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("api key")
+		}
+		return nil, err
+	}
+
+	at := now()
+	_, err = repo.db.ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = ?
+		WHERE id = ? AND tenant_id = ? AND (last_used_at IS NULL OR last_used_at < ?)`,
+		at, k.ID, repo.tenantID, at.Add(-time.Minute))
+	return &k, err
+}
+
+func (repo *APIKeyRepository) Create(ctx context.Context, k *models.APIKey) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	scopes, err := apiKeyScopes(k.Scopes)
+	if err != nil {
+		return err
+	}
+	k.CreatedAt = now()
+	return repo.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (tenant_id, name, prefix, scopes, rate_limit, key_hash, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		repo.tenantID, k.Name, k.Prefix, scopes, k.RateLimit, k.KeyHash, k.CreatedBy, k.CreatedAt).Scan(&k.ID)
+}
+
+func (repo *APIKeyRepository) Update(ctx context.Context, k *models.APIKey) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	scopes, err := apiKeyScopes(k.Scopes)
+	if err != nil {
+		return err
+	}
+	res, err := repo.db.ExecContext(ctx, `
+		UPDATE api_keys SET name = ?, scopes = ?, rate_limit = ? WHERE id = ? AND tenant_id = ?`,
+		k.Name, scopes, k.RateLimit, k.ID, repo.tenantID)
+	return affected(res, err, "api key")
+}
+
+// Rotate mengganti kunci; kunci lama langsung tidak berlaku.
+func (repo *APIKeyRepository) Rotate(ctx context.Context, id int, prefix, keyHash string) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `
+		UPDATE api_keys SET prefix = ?, key_hash = ?, last_used_at = NULL
+		WHERE id = ? AND tenant_id = ? AND revoked_at IS NULL`, prefix, keyHash, id, repo.tenantID)
+	return affected(res, err, "active api key")
+}
+
+func (repo *APIKeyRepository) Revoke(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = ? WHERE id = ? AND tenant_id = ? AND revoked_at IS NULL`, now(), id, repo.tenantID)
+	return affected(res, err, "active api key")
+}
@@ -0,0 +1,295 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories"
+	"strings"
+	"time"
+)
+
+type TransactionRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+type ReportRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+var (
+	_ repositories.TransactionStore = (*TransactionRepository)(nil)
+	_ repositories.ReportStore      = (*ReportRepository)(nil)
+)
+
+func NewTransactionRepository(db *sql.DB, tenantID int) *TransactionRepository {
+	return &TransactionRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func NewReportRepository(db *sql.DB, tenantID int) *ReportRepository {
+	return &ReportRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+// CreateTransaction menjalankan repositories.Checkout di dalam satu transaksi
+// (BEGIN IMMEDIATE, lihat Open). Jika satu langkah gagal, semuanya di-rollback.
+func (repo *TransactionRepository) CreateTransaction(ctx context.Context, req *models.CheckoutRequest) (*models.Transaction, error) {
+	ctx, cancel := repositories.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	t, err := repositories.Checkout(ctx, checkoutTx{tx, repo.tenantID}, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Refund menjalankan repositories.Refund di dalam satu transaksi.
+func (repo *TransactionRepository) Refund(ctx context.Context, id int, refundedBy *int) error {
+	ctx, cancel := repositories.WithWriteTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := repositories.Refund(ctx, checkoutTx{tx, repo.tenantID}, id, refundedBy); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const transactionColumns = `id, status, subtotal, discount_percent, discount, loyalty_discount, tax, total_amount,
+	rounding_adjustment, currency, payment_method, customer_id, points_earned, points_redeemed, cashier_id,
+	terminal_id, approved_by, created_at, refunded_at, refunded_by`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTransaction(row scanner, t *models.Transaction) error {
+	return row.Scan(&t.ID, &t.Status, &t.Subtotal, &t.DiscountPercent, &t.Discount, &t.LoyaltyDiscount, &t.Tax, &t.TotalAmount.Amount,
+		&t.RoundingAdjustment, &t.TotalAmount.Currency, &t.PaymentMethod, &t.CustomerID, &t.PointsEarned, &t.PointsRedeemed, &t.CashierID,
+		&t.TerminalID, &t.ApprovedBy, &t.CreatedAt, &t.RefundedAt, &t.RefundedBy)
+}
+
+// GetByID membaca satu transaksi lengkap dengan detail dan modifier-nya (untuk struk).
+func (repo *TransactionRepository) GetByID(ctx context.Context, id int) (*models.Transaction, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var t models.Transaction
+	err := scanTransaction(repo.db.QueryRowContext(ctx, `SELECT `+transactionColumns+` FROM transactions WHERE id = ? AND tenant_id = ?`,
+		id, repo.tenantID), &t)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("transaction")
+		}
+		return nil, err
+	}
+
+	transactions := []models.Transaction{t}
+	if err := repo.loadDetails(ctx, transactions); err != nil {
+		return nil, err
+	}
+	return &transactions[0], nil
+}
+
+// GetByCustomerID mengembalikan riwayat belanja pelanggan, terbaru lebih dulu.
+func (repo *TransactionRepository) GetByCustomerID(ctx context.Context, customerID, limit int) ([]models.Transaction, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE customer_id = ? AND tenant_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, customerID, repo.tenantID, limit)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]models.Transaction, 0)
+	for rows.Next() {
+		var t models.Transaction
+		if err := scanTransaction(rows, &t); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := repo.loadDetails(ctx, transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// loadDetails mengisi Details (beserta modifier) untuk semua transaksi di slice.
+func (repo *TransactionRepository) loadDetails(ctx context.Context, transactions []models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	ids := make([]any, 0, len(transactions))
+	byTransaction := map[int]int{}
+	for i := range transactions {
+		transactions[i].Details = make([]models.TransactionDetail, 0)
+		ids = append(ids, transactions[i].ID)
+		byTransaction[transactions[i].ID] = i
+	}
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT d.id, d.transaction_id, d.product_id, p.name, d.variant_id, COALESCE(v.name, ''),
+		       d.quantity, d.unit, d.base_quantity, d.price_list_id, d.unit_price, d.price_override, d.subtotal
+		FROM transaction_details d
+		JOIN products p ON p.id = d.product_id
+		LEFT JOIN product_variants v ON v.id = d.variant_id
+		WHERE d.tenant_id = ? AND d.transaction_id IN (`+placeholders(len(ids))+`)
+		ORDER BY d.id`, append([]any{repo.tenantID}, ids...)...)
+	if err != nil {
+		return err
+	}
+	type position struct{ transaction, detail int }
+	byDetail := map[int]position{}
+	for rows.Next() {
+		var d models.TransactionDetail
+		if err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.VariantID, &d.VariantName,
+			&d.Quantity, &d.Unit, &d.BaseQuantity, &d.PriceListID, &d.UnitPrice, &d.PriceOverride, &d.Subtotal); err != nil {
+			rows.Close()
+			return err
+		}
+		t := &transactions[byTransaction[d.TransactionID]]
+		byDetail[d.ID] = position{transaction: byTransaction[d.TransactionID], detail: len(t.Details)}
+		t.Details = append(t.Details, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = repo.db.QueryContext(ctx, `
+		SELECT m.transaction_detail_id, m.modifier_id, m.name, m.price_delta
+		FROM transaction_detail_modifiers m
+		JOIN transaction_details d ON d.id = m.transaction_detail_id
+		WHERE d.tenant_id = ? AND d.transaction_id IN (`+placeholders(len(ids))+`)
+		ORDER BY m.id`, append([]any{repo.tenantID}, ids...)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var detailID int
+		var m models.TransactionDetailModifier
+		if err := rows.Scan(&detailID, &m.ModifierID, &m.Name, &m.PriceDelta); err != nil {
+			return err
+		}
+		pos := byDetail[detailID]
+		d := &transactions[pos.transaction].Details[pos.detail]
+		d.Modifiers = append(d.Modifiers, m)
+	}
+	return rows.Err()
+}
+
+// placeholders menghasilkan "?, ?, ..." untuk n argumen IN (SQLite tidak punya ANY($1)).
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// GetTodayReport merangkum transaksi completed hari ini (zona waktu lokal proses).
+// created_at disimpan dalam UTC, jadi "hari ini" diubah dulu menjadi rentang UTC. Jika
+// byVariant true, produk terlaris dipecah per varian.
+func (repo *ReportRepository) GetTodayReport(ctx context.Context, byVariant bool) (*models.TodayReport, error) {
+	ctx, cancel := repositories.WithReportTimeout(ctx)
+	defer cancel()
+
+	year, month, day := time.Now().Date()
+	from := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 1)
+	from, to = from.UTC(), to.UTC()
+
+	report := models.TodayReport{TotalRevenue: money.New(0)}
+	if err := repo.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(total_amount), 0), COUNT(*)
+		FROM transactions
+		WHERE created_at >= ? AND created_at < ? AND status = 'completed' AND tenant_id = ?`, from, to, repo.tenantID).
+		Scan(&report.TotalRevenue.Amount, &report.TotalTransactions); err != nil {
+		return nil, err
+	}
+
+	variantColumns := `NULL, ''`
+	if byVariant {
+		variantColumns = `v.id, COALESCE(v.name, '')`
+	}
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT p.id, p.name, p.unit, `+variantColumns+`, d.unit, SUM(d.quantity), SUM(d.base_quantity)
+		FROM transactions t
+		JOIN transaction_details d ON d.transaction_id = t.id
+		JOIN products p ON p.id = d.product_id
+		LEFT JOIN product_variants v ON v.id = d.variant_id
+		WHERE t.created_at >= ? AND t.created_at < ? AND t.status = 'completed' AND t.tenant_id = ?
+		GROUP BY p.id, 4, 5, d.unit`, from, to, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sold []repositories.SoldLine
+	for rows.Next() {
+		var l repositories.SoldLine
+		if err := rows.Scan(&l.ProductID, &l.Name, &l.BaseUnit, &l.VariantID, &l.VariantName, &l.Unit, &l.Quantity, &l.BaseQuantity); err != nil {
+			return nil, err
+		}
+		sold = append(sold, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// nama modifier adalah salinan saat transaksi; yang terbaru dipakai jika pernah diubah
+	rows, err = repo.db.QueryContext(ctx, `
+		SELECT m.modifier_id,
+		       (SELECT name FROM transaction_detail_modifiers
+		        WHERE modifier_id = m.modifier_id AND tenant_id = t.tenant_id ORDER BY id DESC LIMIT 1),
+		       d.unit, SUM(d.quantity), COUNT(*)
+		FROM transactions t
+		JOIN transaction_details d ON d.transaction_id = t.id
+		JOIN transaction_detail_modifiers m ON m.transaction_detail_id = d.id
+		WHERE t.created_at >= ? AND t.created_at < ? AND t.status = 'completed' AND t.tenant_id = ?
+		GROUP BY m.modifier_id, d.unit`, from, to, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var modifiers []repositories.SoldModifier
+	for rows.Next() {
+		var m repositories.SoldModifier
+		if err := rows.Scan(&m.ModifierID, &m.Name, &m.Unit, &m.Quantity, &m.Lines); err != nil {
+			return nil, err
+		}
+		modifiers = append(modifiers, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.BestsellingProducts = repositories.BestsellingProducts(sold, 1)
+	report.BestsellingModifiers = repositories.BestsellingModifiers(modifiers, 5)
+	return &report, nil
+}
@@ -0,0 +1,140 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories/sqlite"
+	"kasir-api/services"
+	"sync"
+	"testing"
+
+	sqlitedriver "modernc.org/sqlite"
+)
+
+// onCheckout dipanggil fungsi SQL test_hook(x) dari trigger di tengah transaksi checkout.
+var (
+	registerHook sync.Once
+	onCheckout   func(arg driver.Value)
+)
+
+// Context yang dibatalkan di tengah checkout (setelah stok dikurangi dan baris transaksi
+// ditulis) harus membatalkan seluruh transaksi database: stok dan tabel transaksi kembali
+// seperti semula.
+func TestCheckoutCancelledMidwayRollsBack(t *testing.T) {
+	registerHook.Do(func() {
+		sqlitedriver.MustRegisterScalarFunction("test_hook", 1, func(_ *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+			onCheckout(args[0])
+			return nil, nil
+		})
+	})
+	db := openTestDB(t)
+	_, err := db.Exec(`CREATE TRIGGER test_checkout_hook AFTER INSERT ON transactions
+		BEGIN SELECT test_hook((SELECT stock FROM products)); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	products := sqlite.NewProductRepository(db, models.DefaultTenantID)
+	svc := services.NewTransactionService(sqlite.NewTransactionRepository(db, models.DefaultTenantID), products, nil, nil, nil, 10)
+	tea := models.Product{Name: "Teh", Price: 5000, Stock: 10, Unit: "pcs"}
+	if err := products.Create(context.Background(), &tea); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var midway driver.Value
+	onCheckout = func(stock driver.Value) {
+		midway = stock
+		cancel()
+	}
+	_, err = svc.Checkout(ctx, &models.CheckoutRequest{Items: []models.CheckoutItem{{ProductID: tea.ID, Quantity: 3}}}, true)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	// trigger berjalan di dalam transaksi checkout: stok sudah dikurangi saat context dibatalkan
+	if midway != 7.0 && midway != int64(7) {
+		t.Fatalf("stock inside the checkout transaction = %v, want 7", midway)
+	}
+
+	p, err := products.GetByID(context.Background(), tea.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Stock != 10 {
+		t.Errorf("stock = %g after cancelled checkout, want 10", p.Stock)
+	}
+	var transactions, details int
+	if err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM transactions), (SELECT COUNT(*) FROM transaction_details)`).Scan(&transactions, &details); err != nil {
+		t.Fatal(err)
+	}
+	if transactions != 0 || details != 0 {
+		t.Errorf("%d transactions and %d details stored after cancelled checkout, want none", transactions, details)
+	}
+}
+
+// Checkout SQLite memakai aturan yang sama dengan Postgres: harga varian, modifier, dan
+// poin loyalti; refund mengembalikan stok varian dan menarik poinnya.
+func TestCheckoutVariantModifierAndLoyalty(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	_, err := db.Exec(`
+		INSERT INTO products (id, tenant_id, name, price, stock, option_axes) VALUES (1, 1, 'Kopi', 10000, 0, '["size"]');
+		INSERT INTO product_variants (id, tenant_id, product_id, sku, name, options, price, stock) VALUES (7, 1, 1, 'KOPI-L', 'Large', '{"size":"L"}', 12000, 5);
+		INSERT INTO modifier_groups (id, tenant_id, name, product_id) VALUES (1, 1, 'Extra', 1);
+		INSERT INTO modifiers (id, tenant_id, group_id, name, price_delta) VALUES (3, 1, 1, 'Extra shot', 3000);
+		INSERT INTO customers (id, tenant_id, name) VALUES (1, 1, 'Budi');
+		INSERT INTO loyalty_rules (tenant_id, spend_per_point, point_value) VALUES (1, 1000, 100);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := sqlite.NewTransactionRepository(db, models.DefaultTenantID)
+	variant, customer := 7, 1
+	tx, err := repo.CreateTransaction(ctx, &models.CheckoutRequest{
+		Items:         []models.CheckoutItem{{ProductID: 1, VariantID: &variant, Quantity: 2, ModifierIDs: []int{3}}},
+		PaymentMethod: models.PaymentCard,
+		CustomerID:    &customer,
+	})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if tx.TotalAmount.Amount != 30000 || tx.PointsEarned != 30 {
+		t.Errorf("total = %d, points = %d, want 30000 and 30", tx.TotalAmount.Amount, tx.PointsEarned)
+	}
+
+	stored, err := repo.GetByID(ctx, tx.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := stored.Details[0]
+	if d.VariantName != "Large" || d.UnitPrice != 15000 || len(d.Modifiers) != 1 || d.Modifiers[0].Name != "Extra shot" {
+		t.Errorf("detail = %+v", d)
+	}
+	if stored.CustomerID == nil || *stored.CustomerID != customer {
+		t.Errorf("customer = %v, want %d", stored.CustomerID, customer)
+	}
+
+	var stock float64
+	var points int
+	state := func() {
+		t.Helper()
+		if err := db.QueryRow(`SELECT (SELECT stock FROM product_variants WHERE id = 7),
+			(SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE customer_id = 1)`).Scan(&stock, &points); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if state(); stock != 3 || points != 30 {
+		t.Errorf("after checkout: variant stock = %g, points = %d, want 3 and 30", stock, points)
+	}
+
+	if err := repo.Refund(ctx, tx.ID, nil); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if state(); stock != 5 || points != 0 {
+		t.Errorf("after refund: variant stock = %g, points = %d, want 5 and 0", stock, points)
+	}
+}
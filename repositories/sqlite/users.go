@@ -0,0 +1,264 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"time"
+)
+
+type UserRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+type SessionRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+func NewUserRepository(db *sql.DB, tenantID int) *UserRepository {
+	return &UserRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func NewSessionRepository(db *sql.DB, tenantID int) *SessionRepository {
+	return &SessionRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+// userColumns memakai alias u agar bisa dipakai juga di query yang join ke users.
+const userColumns = `u.id, u.username, u.name, u.role, u.password_hash, COALESCE(u.pin_hash, ''),
+	u.failed_pin_attempts, u.pin_locked_until, u.active, u.created_at`
+
+func scanUser(row scanner, u *models.User) error {
+	if err := row.Scan(&u.ID, &u.Username, &u.Name, &u.Role, &u.PasswordHash, &u.PINHash,
+		&u.FailedPINAttempts, &u.PINLockedUntil, &u.Active, &u.CreatedAt); err != nil {
+		return err
+	}
+	u.HasPIN = u.PINHash != ""
+	return nil
+}
+
+func (repo *UserRepository) GetAll(ctx context.Context) ([]models.User, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users u WHERE u.tenant_id = ? ORDER BY u.username`, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		var u models.User
+		if err := scanUser(rows, &u); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (repo *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	return repo.getOne(ctx, `u.id = ?`, id)
+}
+
+func (repo *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return repo.getOne(ctx, `u.username = ?`, username)
+}
+
+func (repo *UserRepository) getOne(ctx context.Context, where string, arg any) (*models.User, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var u models.User
+	err := scanUser(repo.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users u WHERE `+where+` AND u.tenant_id = ?`,
+		arg, repo.tenantID), &u)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("user")
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UserRepository) Count(ctx context.Context) (int, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var n int
+	err := repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE tenant_id = ?`, repo.tenantID).Scan(&n)
+	return n, err
+}
+
+func (repo *UserRepository) Create(ctx context.Context, u *models.User) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	u.CreatedAt = now()
+	return repo.db.QueryRowContext(ctx, `
+		INSERT INTO users (tenant_id, username, name, role, password_hash, pin_hash, active, created_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?) RETURNING id`,
+		repo.tenantID, u.Username, u.Name, u.Role, u.PasswordHash, u.PINHash, u.Active, u.CreatedAt).Scan(&u.ID)
+}
+
+// Update menyimpan data user termasuk password_hash; user yang dinonaktifkan
+// langsung kehilangan semua sesinya.
+func (repo *UserRepository) Update(ctx context.Context, u *models.User) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users SET username = ?, name = ?, role = ?, password_hash = ?, pin_hash = NULLIF(?, ''), active = ?
+		WHERE id = ? AND tenant_id = ?`,
+		u.Username, u.Name, u.Role, u.PasswordHash, u.PINHash, u.Active, u.ID, repo.tenantID)
+	if err := affected(res, err, "user"); err != nil {
+		return err
+	}
+	if !u.Active {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND tenant_id = ? AND revoked_at IS NULL`,
+			now(), u.ID, repo.tenantID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RecordPINFailure menambah hitungan PIN salah; begitu mencapai maxAttempts, login PIN
+// dikunci selama lockFor dan hitungan dimulai lagi dari nol.
+func (repo *UserRepository) RecordPINFailure(ctx context.Context, id, maxAttempts int, lockFor time.Duration) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := repo.db.ExecContext(ctx, `
+		UPDATE users
+		SET failed_pin_attempts = CASE WHEN failed_pin_attempts + 1 >= ?2 THEN 0 ELSE failed_pin_attempts + 1 END,
+		    pin_locked_until = CASE WHEN failed_pin_attempts + 1 >= ?2 THEN ?3 ELSE pin_locked_until END
+		WHERE id = ?1 AND tenant_id = ?4`,
+		id, maxAttempts, now().Add(lockFor), repo.tenantID)
+	return err
+}
+
+func (repo *UserRepository) ResetPINFailures(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := repo.db.ExecContext(ctx, `
+		UPDATE users SET failed_pin_attempts = 0, pin_locked_until = NULL
+		WHERE id = ? AND tenant_id = ? AND (failed_pin_attempts > 0 OR pin_locked_until IS NOT NULL)`,
+		id, repo.tenantID)
+	return err
+}
+
+func (repo *UserRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `DELETE FROM users WHERE id = ? AND tenant_id = ?`, id, repo.tenantID)
+	return affected(res, err, "user")
+}
+
+func (repo *SessionRepository) Create(ctx context.Context, s *models.Session) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	s.ExpiresAt = s.ExpiresAt.UTC()
+	s.CreatedAt = now()
+	return repo.db.QueryRowContext(ctx, `
+		INSERT INTO sessions (tenant_id, user_id, terminal_id, refresh_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?) RETURNING id`,
+		repo.tenantID, s.UserID, s.TerminalID, s.RefreshHash, s.ExpiresAt, s.CreatedAt).Scan(&s.ID)
+}
+
+// Rotate menukar refresh token lama dengan yang baru. Token lama hanya bisa dipakai sekali;
+// sesi yang dicabut atau kedaluwarsa ditolak. Sesi dibaca ulang setelah UPDATE karena
+// kolom waktu dari RETURNING SQLite tidak dikenali sebagai DATETIME.
+func (repo *SessionRepository) Rotate(ctx context.Context, oldHash, newHash string) (*models.Session, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE sessions SET refresh_hash = ?
+		WHERE refresh_hash = ? AND tenant_id = ? AND revoked_at IS NULL AND expires_at > ?
+		RETURNING id`, newHash, oldHash, repo.tenantID, now()).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("session")
+		}
+		return nil, err
+	}
+
+	var s models.Session
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, user_id, terminal_id, refresh_hash, expires_at, revoked_at, created_at FROM sessions WHERE id = ?`, id).
+		Scan(&s.ID, &s.UserID, &s.TerminalID, &s.RefreshHash, &s.ExpiresAt, &s.RevokedAt, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, tx.Commit()
+}
+
+// GetActiveUser mengembalikan pemilik sesi beserta terminal sesi (nil untuk login password)
+// jika sesi belum dicabut/kedaluwarsa, user-nya masih aktif, dan terminalnya masih aktif.
+// Peran dibaca dari sini (bukan dari token) supaya perubahan peran langsung berlaku.
+func (repo *SessionRepository) GetActiveUser(ctx context.Context, id int) (*models.User, *int, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var u models.User
+	var terminalID *int
+	row := repo.db.QueryRowContext(ctx, `
+		SELECT s.terminal_id, `+userColumns+`
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		LEFT JOIN terminals t ON t.id = s.terminal_id
+		WHERE s.id = ? AND s.tenant_id = ? AND s.revoked_at IS NULL AND s.expires_at > ? AND u.active
+		  AND (s.terminal_id IS NULL OR t.active)`, id, repo.tenantID, now())
+	err := scanUser(prefixedRow{row, &terminalID}, &u)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, models.NotFound("session")
+		}
+		return nil, nil, err
+	}
+	return &u, terminalID, nil
+}
+
+func (repo *SessionRepository) Revoke(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := repo.db.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = ? WHERE id = ? AND tenant_id = ? AND revoked_at IS NULL`, now(), id, repo.tenantID)
+	return err
+}
+
+// RevokeTerminalSessions mencabut semua sesi yang masih aktif di satu terminal
+// (dipakai saat kasir berganti di terminal yang sama atau terminal dinonaktifkan).
+func (repo *SessionRepository) RevokeTerminalSessions(ctx context.Context, terminalID int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := repo.db.ExecContext(ctx, `
+		UPDATE sessions SET revoked_at = ? WHERE terminal_id = ? AND tenant_id = ? AND revoked_at IS NULL`,
+		now(), terminalID, repo.tenantID)
+	return err
+}
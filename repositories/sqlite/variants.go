@@ -0,0 +1,226 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type VariantRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+type UnitRepository struct {
+	db       sqlDB
+	tenantID int
+}
+
+func NewVariantRepository(db *sql.DB, tenantID int) *VariantRepository {
+	return &VariantRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+func NewUnitRepository(db *sql.DB, tenantID int) *UnitRepository {
+	return &UnitRepository{db: sqlDB{db}, tenantID: tenantID}
+}
+
+const variantColumns = `id, product_id, sku, name, options, price, stock`
+
+// scanVariant membaca options yang disimpan sebagai objek JSON (JSONB di Postgres).
+func scanVariant(row scanner, v *models.ProductVariant) error {
+	var options string
+	if err := row.Scan(&v.ID, &v.ProductID, &v.SKU, &v.Name, &options, &v.Price, &v.Stock); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(options), &v.Options)
+}
+
+func variantOptions(options map[string]string) (string, error) {
+	if options == nil {
+		options = map[string]string{}
+	}
+	b, err := json.Marshal(options)
+	return string(b), err
+}
+
+func (repo *VariantRepository) GetByProductID(ctx context.Context, productID int) ([]models.ProductVariant, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT `+variantColumns+` FROM product_variants WHERE product_id = ? AND tenant_id = ? ORDER BY id`,
+		productID, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := make([]models.ProductVariant, 0)
+	for rows.Next() {
+		var v models.ProductVariant
+		if err := scanVariant(rows, &v); err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+
+func (repo *VariantRepository) GetByID(ctx context.Context, id int) (*models.ProductVariant, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var v models.ProductVariant
+	err := scanVariant(repo.db.QueryRowContext(ctx, `
+		SELECT `+variantColumns+` FROM product_variants WHERE id = ? AND tenant_id = ?`, id, repo.tenantID), &v)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound("variant")
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (repo *VariantRepository) Create(ctx context.Context, v *models.ProductVariant) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	options, err := variantOptions(v.Options)
+	if err != nil {
+		return err
+	}
+	return repo.db.QueryRowContext(ctx, `
+		INSERT INTO product_variants (tenant_id, product_id, sku, name, options, price, stock)
+		VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		repo.tenantID, v.ProductID, v.SKU, v.Name, options, v.Price, v.Stock).Scan(&v.ID)
+}
+
+func (repo *VariantRepository) Update(ctx context.Context, v *models.ProductVariant) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	options, err := variantOptions(v.Options)
+	if err != nil {
+		return err
+	}
+	res, err := repo.db.ExecContext(ctx, `
+		UPDATE product_variants SET sku = ?, name = ?, options = ?, price = ?, stock = ?
+		WHERE id = ? AND tenant_id = ?`, v.SKU, v.Name, options, v.Price, v.Stock, v.ID, repo.tenantID)
+	return affected(res, err, "variant")
+}
+
+func (repo *VariantRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `DELETE FROM product_variants WHERE id = ? AND tenant_id = ?`, id, repo.tenantID)
+	return affected(res, err, "variant")
+}
+
+const unitColumns = `id, product_id, name, factor, price, COALESCE(barcode, ''), sellable, purchasable`
+
+func scanUnit(row scanner, u *models.ProductUnit) error {
+	return row.Scan(&u.ID, &u.ProductID, &u.Name, &u.Factor, &u.Price, &u.Barcode, &u.Sellable, &u.Purchasable)
+}
+
+func (repo *UnitRepository) GetByProductID(ctx context.Context, productID int) ([]models.ProductUnit, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.db.QueryContext(ctx, `
+		SELECT `+unitColumns+` FROM product_units WHERE product_id = ? AND tenant_id = ? ORDER BY factor, id`,
+		productID, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := make([]models.ProductUnit, 0)
+	for rows.Next() {
+		var u models.ProductUnit
+		if err := scanUnit(rows, &u); err != nil {
+			return nil, err
+		}
+		units = append(units, u)
+	}
+	return units, rows.Err()
+}
+
+func (repo *UnitRepository) GetByID(ctx context.Context, id int) (*models.ProductUnit, error) {
+	return repo.getOne(ctx, "unit", `id = ?`, id)
+}
+
+func (repo *UnitRepository) GetByBarcode(ctx context.Context, barcode string) (*models.ProductUnit, error) {
+	return repo.getOne(ctx, "barcode", `barcode = ?`, barcode)
+}
+
+func (repo *UnitRepository) getOne(ctx context.Context, what, where string, arg any) (*models.ProductUnit, error) {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var u models.ProductUnit
+	err := scanUnit(repo.db.QueryRowContext(ctx, `
+		SELECT `+unitColumns+` FROM product_units WHERE `+where+` AND tenant_id = ?`, arg, repo.tenantID), &u)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.NotFound(what)
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UnitRepository) Create(ctx context.Context, u *models.ProductUnit) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return repo.db.QueryRowContext(ctx, `
+		INSERT INTO product_units (tenant_id, product_id, name, factor, price, barcode, sellable, purchasable)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?) RETURNING id`,
+		repo.tenantID, u.ProductID, u.Name, u.Factor, u.Price, u.Barcode, u.Sellable, u.Purchasable).Scan(&u.ID)
+}
+
+func (repo *UnitRepository) Update(ctx context.Context, u *models.ProductUnit) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `
+		UPDATE product_units
+		SET name = ?, factor = ?, price = ?, barcode = NULLIF(?, ''), sellable = ?, purchasable = ?
+		WHERE id = ? AND tenant_id = ?`,
+		u.Name, u.Factor, u.Price, u.Barcode, u.Sellable, u.Purchasable, u.ID, repo.tenantID)
+	return affected(res, err, "unit")
+}
+
+func (repo *UnitRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	res, err := repo.db.ExecContext(ctx, `DELETE FROM product_units WHERE id = ? AND tenant_id = ?`, id, repo.tenantID)
+	return affected(res, err, "unit")
+}
+
+// ReceiveStock menambah stok produk (atau varian) sebanyak BaseQuantity satuan dasar,
+// dibulatkan ke 3 desimal seperti AdjustStock saat checkout.
+func (repo *UnitRepository) ReceiveStock(ctx context.Context, receipt *models.StockReceipt) error {
+	ctx, cancel := repositories.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var (
+		res sql.Result
+		err error
+	)
+	if receipt.VariantID != nil {
+		res, err = repo.db.ExecContext(ctx, `
+			UPDATE product_variants SET stock = ROUND(stock + ?, 3) WHERE id = ? AND product_id = ? AND tenant_id = ?`,
+			receipt.BaseQuantity, *receipt.VariantID, receipt.ProductID, repo.tenantID)
+	} else {
+		res, err = repo.db.ExecContext(ctx, `UPDATE products SET stock = ROUND(stock + ?, 3) WHERE id = ? AND tenant_id = ?`,
+			receipt.BaseQuantity, receipt.ProductID, repo.tenantID)
+	}
+	return affected(res, err, "product")
+}
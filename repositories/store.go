@@ -0,0 +1,265 @@
+package repositories
+
+import (
+	"context"
+	"kasir-api/models"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Interface penyimpanan untuk semua data satu tenant. Service bergantung pada interface
+// ini, bukan pada repository Postgres, sehingga backend lain (repositories/sqlite, atau
+// repositories/memory untuk katalog dan transaksi) bisa dipasang tanpa mengubah service.
+
+// Stores adalah rangkaian penyimpanan satu tenant dari satu backend (lihat NewStores dan
+// sqlite.NewStores).
+type Stores struct {
+	Users        UserStore
+	Sessions     SessionStore
+	Terminals    TerminalStore
+	APIKeys      APIKeyStore
+	Audit        AuditStore
+	Products     ProductStore
+	Categories   CategoryStore
+	Variants     VariantStore
+	Units        UnitStore
+	Modifiers    ModifierStore
+	Customers    CustomerStore
+	Loyalty      LoyaltyStore
+	Receivables  ReceivableStore
+	PriceLists   PriceListStore
+	PriceHistory PriceHistoryStore
+	Transactions TransactionStore
+	Reports      ReportStore
+}
+
+// NewStores merakit semua repository Postgres untuk satu tenant.
+func NewStores(pool *pgxpool.Pool, tenantID int) Stores {
+	return Stores{
+		Users:        NewUserRepository(pool, tenantID),
+		Sessions:     NewSessionRepository(pool, tenantID),
+		Terminals:    NewTerminalRepository(pool, tenantID),
+		APIKeys:      NewAPIKeyRepository(pool, tenantID),
+		Audit:        NewAuditRepository(pool, tenantID),
+		Products:     NewProductRepository(pool, tenantID),
+		Categories:   NewCategoryRepository(pool, tenantID),
+		Variants:     NewVariantRepository(pool, tenantID),
+		Units:        NewUnitRepository(pool, tenantID),
+		Modifiers:    NewModifierRepository(pool, tenantID),
+		Customers:    NewCustomerRepository(pool, tenantID),
+		Loyalty:      NewLoyaltyRepository(pool, tenantID),
+		Receivables:  NewReceivableRepository(pool, tenantID),
+		PriceLists:   NewPriceListRepository(pool, tenantID),
+		PriceHistory: NewPriceHistoryRepository(pool, tenantID),
+		Transactions: NewTransactionRepository(pool, tenantID),
+		Reports:      NewReportRepository(pool, tenantID),
+	}
+}
+
+// TenantStore tidak terikat ke satu tenant: dipakai untuk mengelola toko dan menentukan
+// tenant dari kunci pada request.
+type TenantStore interface {
+	GetAll(ctx context.Context) ([]models.Tenant, error)
+	GetByID(ctx context.Context, id int) (*models.Tenant, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	GetByAPIKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error)
+	GetByTerminalKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error)
+	Create(ctx context.Context, t *models.Tenant, admin *models.User) error
+}
+
+type UserStore interface {
+	GetAll(ctx context.Context) ([]models.User, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	Count(ctx context.Context) (int, error)
+	Create(ctx context.Context, u *models.User) error
+	Update(ctx context.Context, u *models.User) error
+	RecordPINFailure(ctx context.Context, id, maxAttempts int, lockFor time.Duration) error
+	ResetPINFailures(ctx context.Context, id int) error
+	Delete(ctx context.Context, id int) error
+}
+
+type SessionStore interface {
+	Create(ctx context.Context, s *models.Session) error
+	Rotate(ctx context.Context, oldHash, newHash string) (*models.Session, error)
+	GetActiveUser(ctx context.Context, id int) (*models.User, *int, error)
+	Revoke(ctx context.Context, id int) error
+	RevokeTerminalSessions(ctx context.Context, terminalID int) error
+}
+
+type TerminalStore interface {
+	GetAll(ctx context.Context) ([]models.Terminal, error)
+	GetByID(ctx context.Context, id int) (*models.Terminal, error)
+	GetByKeyHash(ctx context.Context, keyHash string) (*models.Terminal, error)
+	Create(ctx context.Context, t *models.Terminal) error
+	Update(ctx context.Context, t *models.Terminal) error
+	Delete(ctx context.Context, id int) error
+}
+
+type APIKeyStore interface {
+	GetAll(ctx context.Context) ([]models.APIKey, error)
+	GetByID(ctx context.Context, id int) (*models.APIKey, error)
+	GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	Create(ctx context.Context, k *models.APIKey) error
+	Update(ctx context.Context, k *models.APIKey) error
+	Rotate(ctx context.Context, id int, prefix, keyHash string) error
+	Revoke(ctx context.Context, id int) error
+}
+
+// AuditStore: Record menjalankan change dan menulis audit log-nya dalam satu transaksi;
+// repository dari backend yang sama yang dipanggil dengan ctx milik change ikut di dalamnya.
+type AuditStore interface {
+	Record(ctx context.Context, change func(ctx context.Context) (*models.AuditLog, error)) error
+	Create(ctx context.Context, a *models.AuditLog) error
+	GetAll(ctx context.Context, f models.AuditFilter) ([]models.AuditLog, error)
+}
+
+type ProductStore interface {
+	GetAll(ctx context.Context, nameFilter string) ([]models.Product, error)
+	GetByID(ctx context.Context, id int) (*models.Product, error)
+	GetByPLU(ctx context.Context, plu string) (*models.Product, error)
+	Create(ctx context.Context, product *models.Product) error
+	Update(ctx context.Context, product *models.Product) error
+	Delete(ctx context.Context, id int) error
+}
+
+type CategoryStore interface {
+	GetAllCategories(ctx context.Context) ([]models.Category, error)
+	GetCategoryByID(ctx context.Context, id int) (*models.Category, error)
+	CreateCategory(ctx context.Context, c *models.Category) error
+	UpdateCategory(ctx context.Context, c *models.Category) error
+	DeleteCategory(ctx context.Context, id int) error
+}
+
+// TransactionStore: CreateTransaction dan Refund harus atomik — jika gagal, stok dan
+// data lain tidak boleh berubah sebagian.
+type TransactionStore interface {
+	CreateTransaction(ctx context.Context, req *models.CheckoutRequest) (*models.Transaction, error)
+	Refund(ctx context.Context, id int, refundedBy *int) error
+	GetByID(ctx context.Context, id int) (*models.Transaction, error)
+	GetByCustomerID(ctx context.Context, customerID, limit int) ([]models.Transaction, error)
+}
+
+type ReportStore interface {
+	GetTodayReport(ctx context.Context, byVariant bool) (*models.TodayReport, error)
+}
+
+type VariantStore interface {
+	VariantLookup
+	GetByID(ctx context.Context, id int) (*models.ProductVariant, error)
+	Create(ctx context.Context, v *models.ProductVariant) error
+	Update(ctx context.Context, v *models.ProductVariant) error
+	Delete(ctx context.Context, id int) error
+}
+
+type UnitStore interface {
+	UnitLookup
+	GetByID(ctx context.Context, id int) (*models.ProductUnit, error)
+	Create(ctx context.Context, u *models.ProductUnit) error
+	Update(ctx context.Context, u *models.ProductUnit) error
+	Delete(ctx context.Context, id int) error
+	ReceiveStock(ctx context.Context, receipt *models.StockReceipt) error
+}
+
+type ModifierStore interface {
+	GetAll(ctx context.Context, productID int) ([]models.ModifierGroup, error)
+	GetByID(ctx context.Context, id int) (*models.ModifierGroup, error)
+	Create(ctx context.Context, g *models.ModifierGroup) error
+	Update(ctx context.Context, g *models.ModifierGroup) error
+	Delete(ctx context.Context, id int) error
+}
+
+type CustomerStore interface {
+	CustomerLookup
+	GetAll(ctx context.Context, search string) ([]models.Customer, error)
+	Create(ctx context.Context, c *models.Customer) error
+	Update(ctx context.Context, c *models.Customer) error
+	Delete(ctx context.Context, id int) error
+	GetSummary(ctx context.Context, id int) (*models.CustomerSummary, error)
+}
+
+type LoyaltyStore interface {
+	GetRules(ctx context.Context) (*models.LoyaltyRules, error)
+	UpdateRules(ctx context.Context, rules *models.LoyaltyRules) error
+	GetBalance(ctx context.Context, customerID int) (*models.LoyaltyBalance, error)
+}
+
+type ReceivableStore interface {
+	ReceivableAging
+	GetCredit(ctx context.Context, customerID int) (*models.CustomerCredit, error)
+	Repay(ctx context.Context, p *models.ReceivablePayment) error
+}
+
+type PriceListStore interface {
+	PriceListLookup
+	GetAll(ctx context.Context) ([]models.PriceList, error)
+	Create(ctx context.Context, l *models.PriceList) error
+	Update(ctx context.Context, l *models.PriceList) error
+	Delete(ctx context.Context, id int) error
+	ReplaceItems(ctx context.Context, priceListID int, items []models.PriceListItem) error
+}
+
+// PriceHistoryStore: ApplyDue berlaku lintas tenant (satu scheduler untuk semua toko).
+type PriceHistoryStore interface {
+	GetHistory(ctx context.Context, productID int) ([]models.PriceChange, error)
+	GetScheduled(ctx context.Context, productID int) ([]models.ScheduledPrice, error)
+	GetScheduledByID(ctx context.Context, id int) (*models.ScheduledPrice, error)
+	Schedule(ctx context.Context, sp *models.ScheduledPrice) error
+	Cancel(ctx context.Context, id int) error
+	ApplyDue(ctx context.Context, now time.Time) ([]models.ScheduledPrice, error)
+}
+
+// Lookup untuk data yang tidak dimiliki semua backend (satuan alternatif, varian,
+// pelanggan, daftar harga, kasbon). Service menerima nil untuk backend yang tidak
+// menyimpannya dan menolak fitur terkait dengan models.ErrUnsupported.
+
+type UnitLookup interface {
+	GetByProductID(ctx context.Context, productID int) ([]models.ProductUnit, error)
+	GetByBarcode(ctx context.Context, barcode string) (*models.ProductUnit, error)
+}
+
+type VariantLookup interface {
+	GetByProductID(ctx context.Context, productID int) ([]models.ProductVariant, error)
+}
+
+type CustomerLookup interface {
+	GetByID(ctx context.Context, id int) (*models.Customer, error)
+	GetByPhone(ctx context.Context, phone string) (*models.Customer, error)
+}
+
+type PriceListLookup interface {
+	GetByID(ctx context.Context, id int) (*models.PriceList, error)
+	GetByCode(ctx context.Context, code string) (*models.PriceList, error)
+}
+
+type ReceivableAging interface {
+	GetAging(ctx context.Context) (*models.AgingReport, error)
+}
+
+var (
+	_ TenantStore       = (*TenantRepository)(nil)
+	_ UserStore         = (*UserRepository)(nil)
+	_ SessionStore      = (*SessionRepository)(nil)
+	_ TerminalStore     = (*TerminalRepository)(nil)
+	_ APIKeyStore       = (*APIKeyRepository)(nil)
+	_ AuditStore        = (*AuditRepository)(nil)
+	_ ProductStore      = (*ProductRepository)(nil)
+	_ CategoryStore     = (*CategoryRepository)(nil)
+	_ VariantStore      = (*VariantRepository)(nil)
+	_ UnitStore         = (*UnitRepository)(nil)
+	_ ModifierStore     = (*ModifierRepository)(nil)
+	_ CustomerStore     = (*CustomerRepository)(nil)
+	_ LoyaltyStore      = (*LoyaltyRepository)(nil)
+	_ ReceivableStore   = (*ReceivableRepository)(nil)
+	_ PriceListStore    = (*PriceListRepository)(nil)
+	_ PriceHistoryStore = (*PriceHistoryRepository)(nil)
+	_ TransactionStore  = (*TransactionRepository)(nil)
+	_ ReportStore       = (*ReportRepository)(nil)
+
+	_ UnitLookup      = (*UnitRepository)(nil)
+	_ VariantLookup   = (*VariantRepository)(nil)
+	_ CustomerLookup  = (*CustomerRepository)(nil)
+	_ PriceListLookup = (*PriceListRepository)(nil)
+	_ ReceivableAging = (*ReceivableRepository)(nil)
+)
@@ -0,0 +1,167 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kasir-api/database"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/repositories/storetest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openTestPool membuka dan memigrasikan database TEST_DATABASE_URL, atau melewati test
+// jika tidak diisi.
+func openTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	conn := os.Getenv("TEST_DATABASE_URL")
+	if conn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	pool, err := database.InitDB(conn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	if _, err := database.MigrateUp(pool); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return pool
+}
+
+func createTestTenant(t *testing.T, pool *pgxpool.Pool) int {
+	t.Helper()
+	var id int
+	slug := fmt.Sprintf("storetest-%d", time.Now().UnixNano())
+	err := pool.QueryRow(context.Background(), `INSERT INTO tenants (slug, name) VALUES ($1, $1) RETURNING id`, slug).Scan(&id)
+	if err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	return id
+}
+
+// TestConformance menjalankan uji kesesuaian yang sama dengan backend SQLite dan memori
+// terhadap Postgres sungguhan; setiap subtest memakai tenant baru.
+func TestConformance(t *testing.T) {
+	pool := openTestPool(t)
+	storetest.Run(t, func(t *testing.T) storetest.Stores {
+		tenantID := createTestTenant(t, pool)
+		return storetest.Stores{
+			Products:     repositories.NewProductRepository(pool, tenantID),
+			Categories:   repositories.NewCategoryRepository(pool, tenantID),
+			Transactions: repositories.NewTransactionRepository(pool, tenantID),
+			Reports:      repositories.NewReportRepository(pool, tenantID),
+		}
+	})
+}
+
+// Repository yang terikat ke satu tenant tidak boleh melihat atau mengubah data tenant lain,
+// walaupun ID-nya diketahui.
+func TestTenantIsolation(t *testing.T) {
+	pool := openTestPool(t)
+	ctx := context.Background()
+	a, b := createTestTenant(t, pool), createTestTenant(t, pool)
+
+	categoryA := models.Category{Name: "Minuman"}
+	if err := repositories.NewCategoryRepository(pool, a).CreateCategory(ctx, &categoryA); err != nil {
+		t.Fatal(err)
+	}
+	productA := models.Product{Name: "Teh", Price: 5000, Stock: 10, Unit: "pcs", PLU: "100"}
+	if err := repositories.NewProductRepository(pool, a).Create(ctx, &productA); err != nil {
+		t.Fatal(err)
+	}
+	txA, err := repositories.NewTransactionRepository(pool, a).CreateTransaction(ctx, &models.CheckoutRequest{
+		Items: []models.CheckoutItem{{ProductID: productA.ID, Quantity: 1}}, PaymentMethod: models.PaymentCash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	products := repositories.NewProductRepository(pool, b)
+	categories := repositories.NewCategoryRepository(pool, b)
+	transactions := repositories.NewTransactionRepository(pool, b)
+
+	if all, err := products.GetAll(ctx, ""); err != nil || len(all) != 0 {
+		t.Errorf("tenant b sees products %+v, %v", all, err)
+	}
+	if _, err := products.GetByID(ctx, productA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetByID across tenants: err = %v, want ErrNotFound", err)
+	}
+	if _, err := products.GetByPLU(ctx, "100"); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetByPLU across tenants: err = %v, want ErrNotFound", err)
+	}
+	stolen := productA
+	stolen.Price = 1
+	if err := products.Update(ctx, &stolen); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Update across tenants: err = %v, want ErrNotFound", err)
+	}
+	if err := products.Delete(ctx, productA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Delete across tenants: err = %v, want ErrNotFound", err)
+	}
+	if _, err := categories.GetCategoryByID(ctx, categoryA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("GetCategoryByID across tenants: err = %v, want ErrNotFound", err)
+	}
+	// foreign key komposit menolak produk tenant b yang menunjuk kategori tenant a
+	crossCategory := models.Product{Name: "Kopi", Price: 1, Unit: "pcs", CategoryID: &categoryA.ID}
+	if err := products.Create(ctx, &crossCategory); err == nil {
+		t.Error("tenant b created a product in tenant a's category")
+	}
+	if _, err := transactions.GetByID(ctx, txA.ID); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("transaction GetByID across tenants: err = %v, want ErrNotFound", err)
+	}
+	if err := transactions.Refund(ctx, txA.ID, nil); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("Refund across tenants: err = %v, want ErrNotFound", err)
+	}
+	_, err = transactions.CreateTransaction(ctx, &models.CheckoutRequest{
+		Items: []models.CheckoutItem{{ProductID: productA.ID, Quantity: 1}}, PaymentMethod: models.PaymentCash})
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("checkout of tenant a's product by tenant b: err = %v, want ErrNotFound", err)
+	}
+
+	p, err := repositories.NewProductRepository(pool, a).GetByID(ctx, productA.ID)
+	if err != nil || p.Price != 5000 || p.Stock != 9 {
+		t.Errorf("tenant a product = %+v, %v; want untouched (price 5000, stock 9)", p, err)
+	}
+}
+
+// Perubahan dan audit log-nya ditulis dalam satu transaksi: audit yang gagal ditulis
+// membatalkan perubahannya, termasuk yang dibuat repository lain dengan ctx yang sama.
+func TestAuditRecordIsAtomic(t *testing.T) {
+	pool := openTestPool(t)
+	ctx := context.Background()
+	tenantID := createTestTenant(t, pool)
+	products := repositories.NewProductRepository(pool, tenantID)
+	audit := repositories.NewAuditRepository(pool, tenantID)
+
+	create := func(name string, entry models.AuditLog) error {
+		return audit.Record(ctx, func(ctx context.Context) (*models.AuditLog, error) {
+			p := models.Product{Name: name, Price: 5000, Unit: "pcs"}
+			if err := products.Create(ctx, &p); err != nil {
+				return nil, err
+			}
+			entry.EntityID = &p.ID
+			return &entry, nil
+		})
+	}
+	if err := create("Teh", models.AuditLog{Action: models.AuditCreate, Entity: "product"}); err != nil {
+		t.Fatalf("audited create: %v", err)
+	}
+	// snapshot yang bukan JSON ditolak kolom jsonb
+	broken := models.AuditLog{Action: models.AuditCreate, Entity: "product", After: []byte("{")}
+	if err := create("Kopi", broken); err == nil {
+		t.Fatal("audit write with invalid snapshot succeeded")
+	}
+
+	all, err := products.GetAll(ctx, "")
+	if err != nil || len(all) != 1 || all[0].Name != "Teh" {
+		t.Errorf("products = %+v, %v; want only Teh", all, err)
+	}
+	logs, err := audit.GetAll(ctx, models.AuditFilter{Entity: "product", Limit: 10})
+	if err != nil || len(logs) != 1 || logs[0].EntityID == nil || *logs[0].EntityID != all[0].ID {
+		t.Errorf("audit logs = %+v, %v; want one entry for Teh", logs, err)
+	}
+}
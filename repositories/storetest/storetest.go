@@ -0,0 +1,357 @@
+// Package storetest adalah uji kesesuaian untuk backend penyimpanan: perilaku yang
+// dijanjikan interface di repositories/store.go harus sama di Postgres, SQLite, dan memori.
+// Setiap backend memanggil Run dari file _test.go-nya sendiri.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Stores adalah satu backend yang diuji. Semua store berbagi data yang sama.
+type Stores struct {
+	Products     repositories.ProductStore
+	Categories   repositories.CategoryStore
+	Transactions repositories.TransactionStore
+	Reports      repositories.ReportStore
+}
+
+// Run menjalankan seluruh uji kesesuaian. open dipanggil sekali per subtest dan harus
+// mengembalikan backend kosong (untuk Postgres: tenant baru).
+func Run(t *testing.T, open func(t *testing.T) Stores) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s Stores)
+	}{
+		{"ProductCRUD", testProductCRUD},
+		{"ProductConstraints", testProductConstraints},
+		{"CategoryDeleteKeepsProducts", testCategoryDeleteKeepsProducts},
+		{"Checkout", testCheckout},
+		{"CheckoutIsAtomic", testCheckoutIsAtomic},
+		{"ConcurrentCheckoutDoesNotOversell", testConcurrentCheckout},
+		{"Refund", testRefund},
+		{"SoldProductCannotBeDeleted", testSoldProductCannotBeDeleted},
+		{"TodayReport", testTodayReport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, open(t))
+		})
+	}
+}
+
+func createCategory(t *testing.T, s Stores, name string) int {
+	t.Helper()
+	c := models.Category{Name: name}
+	if err := s.Categories.CreateCategory(context.Background(), &c); err != nil {
+		t.Fatalf("create category %s: %v", name, err)
+	}
+	return c.ID
+}
+
+func createProduct(t *testing.T, s Stores, p models.Product) int {
+	t.Helper()
+	if p.Unit == "" {
+		p.Unit = "pcs"
+	}
+	if err := s.Products.Create(context.Background(), &p); err != nil {
+		t.Fatalf("create product %s: %v", p.Name, err)
+	}
+	if p.ID == 0 {
+		t.Fatalf("create product %s: no id assigned", p.Name)
+	}
+	return p.ID
+}
+
+func getProduct(t *testing.T, s Stores, id int) *models.Product {
+	t.Helper()
+	p, err := s.Products.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get product %d: %v", id, err)
+	}
+	return p
+}
+
+func checkout(t *testing.T, s Stores, items ...models.CheckoutItem) *models.Transaction {
+	t.Helper()
+	tx, err := s.Transactions.CreateTransaction(context.Background(), cashCheckout(items...))
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	return tx
+}
+
+func cashCheckout(items ...models.CheckoutItem) *models.CheckoutRequest {
+	return &models.CheckoutRequest{Items: items, PaymentMethod: models.PaymentCash}
+}
+
+func item(productID int, qty float64) models.CheckoutItem {
+	return models.CheckoutItem{ProductID: productID, Quantity: qty}
+}
+
+func wantErr(t *testing.T, what string, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("%s: err = %v, want %v", what, err, target)
+	}
+}
+
+func testProductCRUD(t *testing.T, s Stores) {
+	ctx := context.Background()
+	drinks := createCategory(t, s, "Minuman")
+	id := createProduct(t, s, models.Product{Name: "Teh Botol", Price: 5000, Stock: 2.5, PLU: "12345", CategoryID: &drinks})
+	createProduct(t, s, models.Product{Name: "Kopi", Price: 8000, Stock: 1})
+
+	p := getProduct(t, s, id)
+	if p.Name != "Teh Botol" || p.Price != 5000 || p.Stock != 2.5 || p.Unit != "pcs" || p.PLU != "12345" {
+		t.Errorf("product = %+v", p)
+	}
+	if p.CategoryID == nil || *p.CategoryID != drinks || p.CategoryName != "Minuman" {
+		t.Errorf("category = %v %q, want %d Minuman", p.CategoryID, p.CategoryName, drinks)
+	}
+	if byPLU, err := s.Products.GetByPLU(ctx, "12345"); err != nil || byPLU.ID != id {
+		t.Errorf("GetByPLU = %+v, %v; want product %d", byPLU, err, id)
+	}
+
+	all, err := s.Products.GetAll(ctx, "")
+	if err != nil || len(all) != 2 {
+		t.Fatalf("GetAll = %d products, %v; want 2", len(all), err)
+	}
+	filtered, err := s.Products.GetAll(ctx, "TEH")
+	if err != nil || len(filtered) != 1 || filtered[0].ID != id {
+		t.Errorf("GetAll(TEH) = %+v, %v; want only product %d", filtered, err, id)
+	}
+
+	p.Price = 5500
+	p.CategoryID = nil
+	if err := s.Products.Update(ctx, p); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := getProduct(t, s, id); got.Price != 5500 || got.CategoryID != nil || got.Name != "Teh Botol" {
+		t.Errorf("updated = %+v, want price 5500 without category", got)
+	}
+
+	if err := s.Products.Delete(ctx, id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	_, err = s.Products.GetByID(ctx, id)
+	wantErr(t, "get deleted", err, models.ErrNotFound)
+	_, err = s.Products.GetByPLU(ctx, "12345")
+	wantErr(t, "get deleted by plu", err, models.ErrNotFound)
+	wantErr(t, "update deleted", s.Products.Update(ctx, p), models.ErrNotFound)
+	wantErr(t, "delete deleted", s.Products.Delete(ctx, id), models.ErrNotFound)
+}
+
+// Pelanggaran batasan skema harus gagal dengan jenis error domain yang sama di semua
+// backend; tiap repository menerjemahkan error database-nya sendiri.
+func testProductConstraints(t *testing.T, s Stores) {
+	ctx := context.Background()
+	createProduct(t, s, models.Product{Name: "Apel", Price: 1000, PLU: "777"})
+
+	dup := models.Product{Name: "Jeruk", Price: 1000, Unit: "kg", PLU: "777"}
+	wantErr(t, "duplicate PLU", s.Products.Create(ctx, &dup), models.ErrConflict)
+	missing := 9999
+	orphan := models.Product{Name: "Pir", Price: 1000, Unit: "kg", CategoryID: &missing}
+	wantErr(t, "unknown category", s.Products.Create(ctx, &orphan), models.ErrNotFound)
+	if all, err := s.Products.GetAll(ctx, ""); err != nil || len(all) != 1 {
+		t.Errorf("GetAll = %d products, %v; want only the first product", len(all), err)
+	}
+}
+
+func testCategoryDeleteKeepsProducts(t *testing.T, s Stores) {
+	ctx := context.Background()
+	snacks := createCategory(t, s, "Camilan")
+	chips := createProduct(t, s, models.Product{Name: "Keripik", Price: 3000, CategoryID: &snacks})
+
+	c, err := s.Categories.GetCategoryByID(ctx, snacks)
+	if err != nil || c.Name != "Camilan" {
+		t.Fatalf("category = %+v, %v", c, err)
+	}
+	c.Description = "makanan ringan"
+	if err := s.Categories.UpdateCategory(ctx, c); err != nil {
+		t.Fatalf("update category: %v", err)
+	}
+	if err := s.Categories.DeleteCategory(ctx, snacks); err != nil {
+		t.Fatalf("delete category: %v", err)
+	}
+	if p := getProduct(t, s, chips); p.CategoryID != nil {
+		t.Errorf("product category = %d after category delete, want none", *p.CategoryID)
+	}
+	_, err = s.Categories.GetCategoryByID(ctx, snacks)
+	wantErr(t, "get deleted category", err, models.ErrNotFound)
+	wantErr(t, "delete deleted category", s.Categories.DeleteCategory(ctx, snacks), models.ErrNotFound)
+	if all, err := s.Categories.GetAllCategories(ctx); err != nil || len(all) != 0 {
+		t.Errorf("categories = %+v, %v; want none", all, err)
+	}
+}
+
+func testCheckout(t *testing.T, s Stores) {
+	ctx := context.Background()
+	tea := createProduct(t, s, models.Product{Name: "Teh", Price: 5000, Stock: 10})
+	coffee := createProduct(t, s, models.Product{Name: "Kopi", Price: 8000, Stock: 4})
+
+	tx := checkout(t, s, item(tea, 3), item(coffee, 1))
+	if tx.ID == 0 || tx.TotalAmount != money.New(23000) || tx.Status != models.TransactionCompleted {
+		t.Errorf("transaction = %+v, want total 23000 completed", tx)
+	}
+	if got := getProduct(t, s, tea).Stock; got != 7 {
+		t.Errorf("tea stock = %g, want 7", got)
+	}
+	if got := getProduct(t, s, coffee).Stock; got != 3 {
+		t.Errorf("coffee stock = %g, want 3", got)
+	}
+
+	stored, err := s.Transactions.GetByID(ctx, tx.ID)
+	if err != nil {
+		t.Fatalf("get transaction: %v", err)
+	}
+	if stored.TotalAmount != tx.TotalAmount || stored.PaymentMethod != models.PaymentCash || len(stored.Details) != 2 {
+		t.Fatalf("stored = %+v", stored)
+	}
+	if d := stored.Details[0]; d.ProductID != tea || d.ProductName != "Teh" || d.Quantity != 3 || d.UnitPrice != 5000 || d.Subtotal != 15000 {
+		t.Errorf("detail = %+v", d)
+	}
+	_, err = s.Transactions.GetByID(ctx, tx.ID+1000)
+	wantErr(t, "get unknown transaction", err, models.ErrNotFound)
+}
+
+func testCheckoutIsAtomic(t *testing.T, s Stores) {
+	ctx := context.Background()
+	tea := createProduct(t, s, models.Product{Name: "Teh", Price: 5000, Stock: 10})
+	coffee := createProduct(t, s, models.Product{Name: "Kopi", Price: 8000, Stock: 1})
+
+	_, err := s.Transactions.CreateTransaction(ctx, cashCheckout(item(tea, 2), item(coffee, 5)))
+	var stock *models.InsufficientStockError
+	if !errors.As(err, &stock) {
+		t.Fatalf("err = %v, want InsufficientStockError", err)
+	}
+	if stock.ProductID != coffee || stock.Available != 1 || stock.Requested != 5 {
+		t.Errorf("stock error = %+v", stock)
+	}
+
+	_, err = s.Transactions.CreateTransaction(ctx, cashCheckout(item(tea, 1), item(coffee+1000, 1)))
+	wantErr(t, "checkout unknown product", err, models.ErrNotFound)
+
+	if got := getProduct(t, s, tea).Stock; got != 10 {
+		t.Errorf("tea stock = %g after failed checkouts, want 10", got)
+	}
+	report, err := s.Reports.GetTodayReport(ctx, false)
+	if err != nil || report.TotalTransactions != 0 {
+		t.Errorf("report = %+v, %v; want no transactions", report, err)
+	}
+}
+
+// Checkout paralel atas produk yang sama tidak boleh sama-sama lolos cek stok: dua
+// keranjang (urutan produk terbalik, untuk menguji deadlock) berebut stok yang cukup
+// untuk separuh dari mereka.
+func testConcurrentCheckout(t *testing.T, s Stores) {
+	const carts = 8
+	tea := createProduct(t, s, models.Product{Name: "Teh", Price: 5000, Stock: carts / 2})
+	coffee := createProduct(t, s, models.Product{Name: "Kopi", Price: 8000, Stock: carts})
+
+	var wg sync.WaitGroup
+	errs := make([]error, carts)
+	for i := range carts {
+		items := []models.CheckoutItem{item(tea, 1), item(coffee, 1)}
+		if i%2 == 1 {
+			items[0], items[1] = items[1], items[0]
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = s.Transactions.CreateTransaction(context.Background(), cashCheckout(items...))
+		}()
+	}
+	wg.Wait()
+
+	sold := 0
+	for _, err := range errs {
+		var stock *models.InsufficientStockError
+		switch {
+		case err == nil:
+			sold++
+		case !errors.As(err, &stock):
+			t.Errorf("checkout: %v, want success or InsufficientStockError", err)
+		}
+	}
+	if sold != carts/2 {
+		t.Errorf("%d checkouts succeeded, want %d", sold, carts/2)
+	}
+	if got := getProduct(t, s, tea).Stock; got != 0 {
+		t.Errorf("tea stock = %g, want 0", got)
+	}
+	if got := getProduct(t, s, coffee).Stock; got != float64(carts-sold) {
+		t.Errorf("coffee stock = %g, want %d", got, carts-sold)
+	}
+}
+
+func testRefund(t *testing.T, s Stores) {
+	ctx := context.Background()
+	tea := createProduct(t, s, models.Product{Name: "Teh", Price: 5000, Stock: 10})
+	tx := checkout(t, s, item(tea, 4))
+
+	if err := s.Transactions.Refund(ctx, tx.ID, nil); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if got := getProduct(t, s, tea).Stock; got != 10 {
+		t.Errorf("stock = %g after refund, want 10", got)
+	}
+	refunded, err := s.Transactions.GetByID(ctx, tx.ID)
+	if err != nil || refunded.Status != models.TransactionRefunded || refunded.RefundedAt == nil {
+		t.Errorf("refunded = %+v, %v", refunded, err)
+	}
+
+	wantErr(t, "second refund", s.Transactions.Refund(ctx, tx.ID, nil), models.ErrConflict)
+	if got := getProduct(t, s, tea).Stock; got != 10 {
+		t.Errorf("stock = %g after second refund, want 10", got)
+	}
+	wantErr(t, "refund unknown", s.Transactions.Refund(ctx, tx.ID+1000, nil), models.ErrNotFound)
+}
+
+func testSoldProductCannotBeDeleted(t *testing.T, s Stores) {
+	tea := createProduct(t, s, models.Product{Name: "Teh", Price: 5000, Stock: 10})
+	checkout(t, s, item(tea, 1))
+
+	if err := s.Products.Delete(context.Background(), tea); err == nil {
+		t.Fatal("deleted a product that has transactions")
+	}
+	getProduct(t, s, tea)
+}
+
+func testTodayReport(t *testing.T, s Stores) {
+	ctx := context.Background()
+	tea := createProduct(t, s, models.Product{Name: "Teh", Price: 5000, Stock: 100})
+	coffee := createProduct(t, s, models.Product{Name: "Kopi", Price: 8000, Stock: 100})
+	// produk lain dengan nama sama tidak boleh digabung ke Teh di atas
+	bottled := createProduct(t, s, models.Product{Name: "Teh", Price: 1000, Stock: 100})
+
+	checkout(t, s, item(tea, 2))
+	checkout(t, s, item(coffee, 1), item(tea, 3), item(bottled, 4))
+	// transaksi yang direfund tidak dihitung
+	refunded := checkout(t, s, item(coffee, 10))
+	if err := s.Transactions.Refund(ctx, refunded.ID, nil); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+
+	report, err := s.Reports.GetTodayReport(ctx, false)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if report.TotalTransactions != 2 || report.TotalRevenue != money.New(37000) {
+		t.Errorf("report = %d transactions, revenue %+v; want 2, 37000", report.TotalTransactions, report.TotalRevenue)
+	}
+	if len(report.BestsellingProducts) != 1 {
+		t.Fatalf("bestselling = %+v, want one product", report.BestsellingProducts)
+	}
+	best := report.BestsellingProducts[0]
+	wantUnits := []models.UnitQuantity{{Unit: "pcs", Quantity: 5}}
+	if best.ProductID != tea || best.QtySold != 5 || best.Unit != "pcs" || !slices.Equal(best.Units, wantUnits) {
+		t.Errorf("bestselling = %+v, want product %d x5 pcs", best, tea)
+	}
+}
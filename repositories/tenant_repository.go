@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TenantRepository adalah satu-satunya repository yang tidak terikat ke satu tenant:
+// dipakai untuk mengelola toko dan menentukan tenant dari kunci pada request.
+type TenantRepository struct {
+	pool pgDB
+}
+
+func NewTenantRepository(pool *pgxpool.Pool) *TenantRepository {
+	return &TenantRepository{pool: pgDB{pool}}
+}
+
+const tenantColumns = `id, slug, name, active, created_at`
+
+func scanTenant(row pgx.Row, t *models.Tenant) error {
+	return row.Scan(&t.ID, &t.Slug, &t.Name, &t.Active, &t.CreatedAt)
+}
+
+func (repo *TenantRepository) GetAll(ctx context.Context) ([]models.Tenant, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.pool.Query(ctx, `SELECT `+tenantColumns+` FROM tenants ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := make([]models.Tenant, 0)
+	for rows.Next() {
+		var t models.Tenant
+		if err := scanTenant(rows, &t); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, rows.Err()
+}
+
+func (repo *TenantRepository) GetByID(ctx context.Context, id int) (*models.Tenant, error) {
+	return repo.getOne(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE id = $1`, id)
+}
+
+func (repo *TenantRepository) GetBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	return repo.getOne(ctx, `SELECT `+tenantColumns+` FROM tenants WHERE slug = $1`, slug)
+}
+
+// GetByAPIKeyHash mencari pemilik kunci API; keabsahan kunci tetap diperiksa oleh
+// APIKeyRepository milik tenant tersebut.
+func (repo *TenantRepository) GetByAPIKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error) {
+	return repo.getOne(ctx, `
+		SELECT `+tenantColumns+` FROM tenants WHERE id = (SELECT tenant_id FROM api_keys WHERE key_hash = $1)
+	`, keyHash)
+}
+
+// GetByTerminalKeyHash mencari pemilik kunci terminal (login PIN).
+func (repo *TenantRepository) GetByTerminalKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error) {
+	return repo.getOne(ctx, `
+		SELECT `+tenantColumns+` FROM tenants WHERE id = (SELECT tenant_id FROM terminals WHERE key_hash = $1)
+	`, keyHash)
+}
+
+func (repo *TenantRepository) getOne(ctx context.Context, query string, arg any) (*models.Tenant, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var t models.Tenant
+	if err := scanTenant(repo.pool.QueryRow(ctx, query, arg), &t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("tenant")
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Create membuat tenant dan user admin pertamanya dalam satu transaksi, supaya tidak
+// ada toko yang tidak bisa dipakai login.
+func (repo *TenantRepository) Create(ctx context.Context, t *models.Tenant, admin *models.User) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO tenants (slug, name, active) VALUES ($1, $2, $3) RETURNING id, created_at
+	`, t.Slug, t.Name, t.Active).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (tenant_id, username, name, role, password_hash, active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, t.ID, admin.Username, admin.Name, admin.Role, admin.PasswordHash, admin.Active).Scan(&admin.ID, &admin.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
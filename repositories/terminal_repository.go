@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TerminalRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewTerminalRepository(pool *pgxpool.Pool, tenantID int) *TerminalRepository {
+	return &TerminalRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+const terminalColumns = `t.id, t.name, t.active, t.key_hash, t.last_seen_at, t.created_at,
+	COALESCE((SELECT array_agg(tu.user_id ORDER BY tu.user_id) FROM terminal_users tu
+	          WHERE tu.terminal_id = t.id AND tu.tenant_id = t.tenant_id), '{}')`
+
+func scanTerminal(row pgx.Row, t *models.Terminal) error {
+	return row.Scan(&t.ID, &t.Name, &t.Active, &t.KeyHash, &t.LastSeenAt, &t.CreatedAt, &t.UserIDs)
+}
+
+func (repo *TerminalRepository) GetAll(ctx context.Context) ([]models.Terminal, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.pool.Query(ctx, `
+		SELECT `+terminalColumns+` FROM terminals t WHERE t.tenant_id = $1 ORDER BY t.name, t.id
+	`, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terminals := make([]models.Terminal, 0)
+	for rows.Next() {
+		var t models.Terminal
+		if err := scanTerminal(rows, &t); err != nil {
+			return nil, err
+		}
+		terminals = append(terminals, t)
+	}
+	return terminals, rows.Err()
+}
+
+func (repo *TerminalRepository) GetByID(ctx context.Context, id int) (*models.Terminal, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var t models.Terminal
+	if err := scanTerminal(repo.pool.QueryRow(ctx, `
+		SELECT `+terminalColumns+` FROM terminals t WHERE t.id = $1 AND t.tenant_id = $2
+	`, id, repo.tenantID), &t); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("terminal")
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetByKeyHash mencari terminal aktif dari hash kunci terminal dan mencatat kapan terakhir dipakai.
+func (repo *TerminalRepository) GetByKeyHash(ctx context.Context, keyHash string) (*models.Terminal, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var t models.Terminal
+	err := scanTerminal(repo.pool.QueryRow(ctx, `
+		UPDATE terminals t SET last_seen_at = now()
+		WHERE t.key_hash = $1 AND t.tenant_id = $2 AND t.active
+		RETURNING `+terminalColumns, keyHash, repo.tenantID), &t)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("terminal")
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (repo *TerminalRepository) Create(ctx context.Context, t *models.Terminal) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO terminals (tenant_id, name, key_hash, active) VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, repo.tenantID, t.Name, t.KeyHash, t.Active).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := replaceTerminalUsers(ctx, tx, repo.tenantID, t.ID, t.UserIDs); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Update menyimpan nama, status aktif, dan daftar user yang boleh login di terminal.
+// Terminal yang dinonaktifkan langsung kehilangan semua sesinya.
+func (repo *TerminalRepository) Update(ctx context.Context, t *models.Terminal) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	ct, err := tx.Exec(ctx, `
+		UPDATE terminals SET name = $1, active = $2 WHERE id = $3 AND tenant_id = $4
+	`, t.Name, t.Active, t.ID, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("terminal")
+	}
+	if err := replaceTerminalUsers(ctx, tx, repo.tenantID, t.ID, t.UserIDs); err != nil {
+		return err
+	}
+	if !t.Active {
+		_, err := tx.Exec(ctx, `
+			UPDATE sessions SET revoked_at = now() WHERE terminal_id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+		`, t.ID, repo.tenantID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (repo *TerminalRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `DELETE FROM terminals WHERE id = $1 AND tenant_id = $2`, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("terminal")
+	}
+	return nil
+}
+
+func replaceTerminalUsers(ctx context.Context, tx pgx.Tx, tenantID, terminalID int, userIDs []int) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM terminal_users WHERE terminal_id = $1 AND tenant_id = $2`, terminalID, tenantID); err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO terminal_users (tenant_id, terminal_id, user_id) VALUES ($1, $2, $3)
+		`, tenantID, terminalID, userID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
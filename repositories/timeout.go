@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts adalah batas waktu per jenis operasi database. Context dari request tetap
+// berlaku (klien putus atau deadline request membatalkan query lebih cepat); timeout
+// ini hanya batas atasnya.
+type Timeouts struct {
+	// Query untuk baca dan tulis sederhana (CRUD biasa)
+	Query time.Duration
+	// Write untuk transaksi multi-langkah: checkout, refund, pembayaran kasbon,
+	// penggantian isi daftar harga, penerapan harga terjadwal
+	Write time.Duration
+	// Report untuk laporan yang memindai banyak transaksi
+	Report time.Duration
+}
+
+var timeouts = Timeouts{Query: 5 * time.Second, Write: 10 * time.Second, Report: 5 * time.Second}
+
+// ConfigureTimeouts mengganti batas waktu; nilai nol memakai bawaan. Dipanggil sekali
+// saat startup, sebelum server menerima request.
+func ConfigureTimeouts(t Timeouts) {
+	if t.Query > 0 {
+		timeouts.Query = t.Query
+	}
+	if t.Write > 0 {
+		timeouts.Write = t.Write
+	}
+	if t.Report > 0 {
+		timeouts.Report = t.Report
+	}
+}
+
+// CurrentTimeouts mengembalikan batas waktu yang sedang berlaku.
+func CurrentTimeouts() Timeouts {
+	return timeouts
+}
+
+func WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, timeouts.Query)
+}
+
+func WithWriteTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, timeouts.Write)
+}
+
+func WithReportTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, timeouts.Report)
+}
@@ -4,22 +4,24 @@ import (
 	"context"
 	"errors"
 	"kasir-api/models"
-	"time"
+	"kasir-api/money"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type TransactionRepository struct {
-	pool *pgxpool.Pool
+	pool     pgDB
+	tenantID int
 }
 
-func NewTransactionRepository(pool *pgxpool.Pool) *TransactionRepository {
-	return &TransactionRepository{pool: pool}
+func NewTransactionRepository(pool *pgxpool.Pool, tenantID int) *TransactionRepository {
+	return &TransactionRepository{pool: pgDB{pool}, tenantID: tenantID}
 }
 
-func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem) (*models.Transaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// CreateTransaction menjalankan Checkout dalam satu transaksi database.
+func (repo *TransactionRepository) CreateTransaction(ctx context.Context, req *models.CheckoutRequest) (*models.Transaction, error) {
+	ctx, cancel := WithWriteTimeout(ctx)
 	defer cancel()
 
 	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
@@ -28,85 +30,416 @@ func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem
 	}
 	defer tx.Rollback(ctx)
 
-	totalAmount := 0
-	details := make([]models.TransactionDetail, 0, len(items))
+	t, err := Checkout(ctx, checkoutTx{tx, repo.tenantID}, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Refund membatalkan seluruh transaksi (lihat Refund) dalam satu transaksi database.
+func (repo *TransactionRepository) Refund(ctx context.Context, id int, refundedBy *int) error {
+	ctx, cancel := WithWriteTimeout(ctx)
+	defer cancel()
 
-	for _, item := range items {
-		var productName string
-		var productPrice int
-		var stock int
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-		// get data product
-		err = tx.QueryRow(ctx, `
-            SELECT name, price, stock
-            FROM products
-            WHERE id = $1
-        `, item.ProductID).Scan(&productName, &productPrice, &stock)
-		if err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
-				return nil, errors.New("product id not found")
-			}
-			return nil, err
+	if err := Refund(ctx, checkoutTx{tx, repo.tenantID}, id, refundedBy); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// checkoutTx adalah CheckoutTx di atas transaksi Postgres. Baris yang dibaca untuk
+// checkout dikunci FOR UPDATE sampai transaksi selesai.
+type checkoutTx struct {
+	tx       pgx.Tx
+	tenantID int
+}
+
+var _ CheckoutTx = checkoutTx{}
+
+func (c checkoutTx) LockProducts(ctx context.Context, ids []int) error {
+	_, err := c.tx.Exec(ctx, `
+        SELECT id FROM products
+        WHERE id = ANY($1) AND tenant_id = $2
+        ORDER BY id
+        FOR UPDATE
+    `, ids, c.tenantID)
+	return err
+}
+
+func (c checkoutTx) Product(ctx context.Context, id int) (*models.Product, error) {
+	p := models.Product{ID: id}
+	err := c.tx.QueryRow(ctx, `
+        SELECT name, price, stock, unit, decimal_qty, option_axes, category_id
+        FROM products
+        WHERE id = $1 AND tenant_id = $2
+        FOR UPDATE
+    `, id, c.tenantID).Scan(&p.Name, &p.Price, &p.Stock, &p.Unit, &p.DecimalQty, &p.OptionAxes, &p.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (c checkoutTx) Variant(ctx context.Context, productID, variantID int) (*models.ProductVariant, error) {
+	v := models.ProductVariant{ID: variantID, ProductID: productID}
+	err := c.tx.QueryRow(ctx, `
+        SELECT name, price, stock
+        FROM product_variants
+        WHERE id = $1 AND product_id = $2 AND tenant_id = $3
+        FOR UPDATE
+    `, variantID, productID, c.tenantID).Scan(&v.Name, &v.Price, &v.Stock)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (c checkoutTx) SellUnit(ctx context.Context, productID int, name string) (*models.ProductUnit, error) {
+	var u models.ProductUnit
+	err := scanUnit(c.tx.QueryRow(ctx, `
+        SELECT `+unitColumns+`
+        FROM product_units
+        WHERE product_id = $1 AND name = $2 AND sellable AND tenant_id = $3
+    `, productID, name, c.tenantID), &u)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (c checkoutTx) ModifierGroups(ctx context.Context, productID int) ([]models.ModifierGroup, error) {
+	return loadModifierGroups(ctx, c.tx, c.tenantID, applicableGroupsWhere, productID)
+}
+
+func (c checkoutTx) ListPrice(ctx context.Context, priceListID, productID int, variantID *int, baseQuantity float64) (money.Amount, bool, error) {
+	var price money.Amount
+	err := c.tx.QueryRow(ctx, `
+		SELECT price
+		FROM price_list_items
+		WHERE price_list_id = $1 AND product_id = $2 AND tenant_id = $5
+		  AND (variant_id = $3 OR variant_id IS NULL)
+		  AND min_quantity <= $4
+		ORDER BY variant_id IS NULL, min_quantity DESC
+		LIMIT 1
+	`, priceListID, productID, variantID, baseQuantity, c.tenantID).Scan(&price)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return price, true, nil
+}
+
+func (c checkoutTx) LoyaltyRules(ctx context.Context) (*models.LoyaltyRules, error) {
+	return loadLoyaltyRules(ctx, c.tx, c.tenantID)
+}
+
+func (c checkoutTx) OutstandingCredit(ctx context.Context, customerID int) (money.Amount, error) {
+	var outstanding money.Amount
+	err := c.tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount - paid_amount), 0)
+		FROM receivables
+		WHERE customer_id = $1 AND status = $2 AND tenant_id = $3
+	`, customerID, models.ReceivableOpen, c.tenantID).Scan(&outstanding)
+	return outstanding, err
+}
+
+func (c checkoutTx) LockCustomer(ctx context.Context, customerID int) (*models.Customer, error) {
+	var customer models.Customer
+	err := scanCustomer(c.tx.QueryRow(ctx, `
+		SELECT `+customerColumns+` FROM customers WHERE id = $1 AND tenant_id = $2 FOR UPDATE
+	`, customerID, c.tenantID), &customer)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("customer")
 		}
-		// validation stoct
-		if stock < item.Quantity {
-			return nil, errors.New("insufficient stock")
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (c checkoutTx) LoyaltyLedger(ctx context.Context, customerID int) ([]models.LoyaltyEntry, error) {
+	rows, err := c.tx.Query(ctx, `
+		SELECT id, customer_id, transaction_id, kind, points, expires_at, created_at
+		FROM loyalty_ledger
+		WHERE customer_id = $1 AND tenant_id = $2
+		ORDER BY id
+	`, customerID, c.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ledger := make([]models.LoyaltyEntry, 0)
+	for rows.Next() {
+		var e models.LoyaltyEntry
+		if err := rows.Scan(&e.ID, &e.CustomerID, &e.TransactionID, &e.Kind, &e.Points, &e.ExpiresAt, &e.CreatedAt); err != nil {
+			return nil, err
 		}
+		ledger = append(ledger, e)
+	}
+	return ledger, rows.Err()
+}
 
-		subtotal := productPrice * item.Quantity
-		totalAmount += subtotal
+func (c checkoutTx) InsertLedger(ctx context.Context, e *models.LoyaltyEntry) error {
+	return c.tx.QueryRow(ctx, `
+		INSERT INTO loyalty_ledger (tenant_id, customer_id, transaction_id, kind, points, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, c.tenantID, e.CustomerID, e.TransactionID, e.Kind, e.Points, e.ExpiresAt).Scan(&e.ID, &e.CreatedAt)
+}
 
-		// Reduce stock
-		_, err = tx.Exec(ctx, `
+func (c checkoutTx) AdjustStock(ctx context.Context, productID int, variantID *int, delta float64) error {
+	var err error
+	if variantID != nil {
+		_, err = c.tx.Exec(ctx, `
+            UPDATE product_variants
+            SET stock = stock + $1
+            WHERE id = $2 AND tenant_id = $3
+        `, delta, *variantID, c.tenantID)
+	} else {
+		_, err = c.tx.Exec(ctx, `
             UPDATE products
-            SET stock = stock - $1
-            WHERE id = $2
-        `, item.Quantity, item.ProductID)
+            SET stock = stock + $1
+            WHERE id = $2 AND tenant_id = $3
+        `, delta, productID, c.tenantID)
+	}
+	return err
+}
+
+func (c checkoutTx) InsertTransaction(ctx context.Context, t *models.Transaction) error {
+	return c.tx.QueryRow(ctx, `
+        INSERT INTO transactions (tenant_id, status, subtotal, discount_percent, discount, loyalty_discount, tax, total_amount,
+                                  rounding_adjustment, currency, payment_method, customer_id, points_earned,
+                                  points_redeemed, cashier_id, terminal_id, approved_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+        RETURNING id, created_at
+    `, c.tenantID, t.Status, t.Subtotal, t.DiscountPercent, t.Discount, t.LoyaltyDiscount, t.Tax, t.TotalAmount.Amount,
+		t.RoundingAdjustment, t.TotalAmount.Currency, t.PaymentMethod, t.CustomerID, t.PointsEarned,
+		t.PointsRedeemed, t.CashierID, t.TerminalID, t.ApprovedBy).Scan(&t.ID, &t.CreatedAt)
+}
+
+func (c checkoutTx) InsertDetail(ctx context.Context, d *models.TransactionDetail) error {
+	err := c.tx.QueryRow(ctx, `
+        INSERT INTO transaction_details (tenant_id, transaction_id, product_id, variant_id, quantity, unit, base_quantity,
+                                         price_list_id, unit_price, price_override, subtotal)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING id
+    `, c.tenantID, d.TransactionID, d.ProductID, d.VariantID, d.Quantity, d.Unit, d.BaseQuantity,
+		d.PriceListID, d.UnitPrice, d.PriceOverride, d.Subtotal).Scan(&d.ID)
+	if err != nil {
+		return err
+	}
+	for _, m := range d.Modifiers {
+		_, err := c.tx.Exec(ctx, `
+            INSERT INTO transaction_detail_modifiers (tenant_id, transaction_detail_id, modifier_id, name, price_delta)
+            VALUES ($1, $2, $3, $4, $5)
+        `, c.tenantID, d.ID, m.ModifierID, m.Name, m.PriceDelta)
 		if err != nil {
-			return nil, err
+			return err
 		}
+	}
+	return nil
+}
+
+func (c checkoutTx) InsertReceivable(ctx context.Context, r *models.Receivable) error {
+	return c.tx.QueryRow(ctx, `
+        INSERT INTO receivables (tenant_id, customer_id, transaction_id, amount, status)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at
+    `, c.tenantID, r.CustomerID, r.TransactionID, r.Amount, r.Status).Scan(&r.ID, &r.CreatedAt)
+}
 
-		details = append(details, models.TransactionDetail{
-			ProductID:   item.ProductID,
-			ProductName: productName,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
-		})
+func (c checkoutTx) LockTransaction(ctx context.Context, id int) (*models.Transaction, error) {
+	t := models.Transaction{ID: id}
+	err := c.tx.QueryRow(ctx, `
+        SELECT status, customer_id, points_earned, points_redeemed
+        FROM transactions
+        WHERE id = $1 AND tenant_id = $2
+        FOR UPDATE
+    `, id, c.tenantID).Scan(&t.Status, &t.CustomerID, &t.PointsEarned, &t.PointsRedeemed)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("transaction")
+		}
+		return nil, err
 	}
 
-	// Insert transaction
-	var transactionID int
-	err = tx.QueryRow(ctx, `
-        INSERT INTO transactions (total_amount)
-        VALUES ($1)
-        RETURNING id
-    `, totalAmount).Scan(&transactionID)
+	rows, err := c.tx.Query(ctx, `
+        SELECT product_id, variant_id, base_quantity
+        FROM transaction_details
+        WHERE transaction_id = $1 AND tenant_id = $2
+        ORDER BY id
+    `, id, c.tenantID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+	for rows.Next() {
+		d := models.TransactionDetail{TransactionID: id}
+		if err := rows.Scan(&d.ProductID, &d.VariantID, &d.BaseQuantity); err != nil {
+			return nil, err
+		}
+		t.Details = append(t.Details, d)
+	}
+	return &t, rows.Err()
+}
 
-	// Insert transaction detail
-	for i := range details {
-		details[i].TransactionID = transactionID
-		var detailID int
-		err = tx.QueryRow(ctx, `
-            INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal)
-			VALUES ($1, $2, $3, $4)
-			RETURNING id
-        `, transactionID, details[i].ProductID, details[i].Quantity, details[i].Subtotal).Scan(&detailID)
-		if err != nil {
+func (c checkoutTx) MarkRefunded(ctx context.Context, id int, refundedBy *int) error {
+	_, err := c.tx.Exec(ctx, `
+        UPDATE transactions SET status = $1, refunded_at = now(), refunded_by = $2 WHERE id = $3 AND tenant_id = $4
+    `, models.TransactionRefunded, refundedBy, id, c.tenantID)
+	return err
+}
+
+func (c checkoutTx) VoidReceivables(ctx context.Context, transactionID int) error {
+	_, err := c.tx.Exec(ctx, `
+        UPDATE receivables SET status = $1, settled_at = now()
+        WHERE transaction_id = $2 AND status = $3 AND tenant_id = $4
+    `, models.ReceivableVoid, transactionID, models.ReceivableOpen, c.tenantID)
+	return err
+}
+
+const transactionColumns = `id, status, subtotal, discount_percent, discount, loyalty_discount, tax, total_amount,
+	rounding_adjustment, currency, payment_method, customer_id, points_earned, points_redeemed, cashier_id,
+	terminal_id, approved_by, created_at, refunded_at, refunded_by`
+
+func scanTransaction(row pgx.Row, t *models.Transaction) error {
+	return row.Scan(&t.ID, &t.Status, &t.Subtotal, &t.DiscountPercent, &t.Discount, &t.LoyaltyDiscount, &t.Tax, &t.TotalAmount.Amount,
+		&t.RoundingAdjustment, &t.TotalAmount.Currency, &t.PaymentMethod, &t.CustomerID, &t.PointsEarned, &t.PointsRedeemed, &t.CashierID,
+		&t.TerminalID, &t.ApprovedBy, &t.CreatedAt, &t.RefundedAt, &t.RefundedBy)
+}
+
+// GetByID membaca satu transaksi lengkap dengan detail dan modifier-nya (untuk struk).
+func (repo *TransactionRepository) GetByID(ctx context.Context, id int) (*models.Transaction, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var t models.Transaction
+	err := scanTransaction(repo.pool.QueryRow(ctx, `SELECT `+transactionColumns+` FROM transactions WHERE id = $1 AND tenant_id = $2`,
+		id, repo.tenantID), &t)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("transaction")
+		}
+		return nil, err
+	}
+
+	transactions := []models.Transaction{t}
+	if err := repo.loadDetails(ctx, transactions); err != nil {
+		return nil, err
+	}
+	return &transactions[0], nil
+}
+
+// GetByCustomerID mengembalikan riwayat belanja pelanggan, terbaru lebih dulu.
+func (repo *TransactionRepository) GetByCustomerID(ctx context.Context, customerID, limit int) ([]models.Transaction, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.pool.Query(ctx, `
+        SELECT `+transactionColumns+`
+        FROM transactions
+        WHERE customer_id = $1 AND tenant_id = $3
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2
+    `, customerID, limit, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]models.Transaction, 0)
+	for rows.Next() {
+		var t models.Transaction
+		if err := scanTransaction(rows, &t); err != nil {
+			rows.Close()
 			return nil, err
 		}
-		details[i].ID = detailID
+		transactions = append(transactions, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
+	if err := repo.loadDetails(ctx, transactions); err != nil {
 		return nil, err
 	}
+	return transactions, nil
+}
+
+// loadDetails mengisi Details (beserta modifier) untuk semua transaksi di slice.
+func (repo *TransactionRepository) loadDetails(ctx context.Context, transactions []models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(transactions))
+	byTransaction := map[int]int{}
+	for i := range transactions {
+		transactions[i].Details = make([]models.TransactionDetail, 0)
+		ids = append(ids, transactions[i].ID)
+		byTransaction[transactions[i].ID] = i
+	}
 
-	return &models.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		Details:     details,
-	}, nil
+	rows, err := repo.pool.Query(ctx, `
+        SELECT d.id, d.transaction_id, d.product_id, p.name, d.variant_id, COALESCE(v.name, ''),
+               d.quantity, d.unit, d.base_quantity, d.price_list_id, d.unit_price, d.price_override, d.subtotal
+        FROM transaction_details d
+        JOIN products p ON p.id = d.product_id
+        LEFT JOIN product_variants v ON v.id = d.variant_id
+        WHERE d.transaction_id = ANY($1) AND d.tenant_id = $2
+        ORDER BY d.id
+    `, ids, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	type position struct{ transaction, detail int }
+	byDetail := map[int]position{}
+	for rows.Next() {
+		var d models.TransactionDetail
+		if err := rows.Scan(&d.ID, &d.TransactionID, &d.ProductID, &d.ProductName, &d.VariantID, &d.VariantName,
+			&d.Quantity, &d.Unit, &d.BaseQuantity, &d.PriceListID, &d.UnitPrice, &d.PriceOverride, &d.Subtotal); err != nil {
+			rows.Close()
+			return err
+		}
+		t := &transactions[byTransaction[d.TransactionID]]
+		byDetail[d.ID] = position{transaction: byTransaction[d.TransactionID], detail: len(t.Details)}
+		t.Details = append(t.Details, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = repo.pool.Query(ctx, `
+        SELECT m.transaction_detail_id, m.modifier_id, m.name, m.price_delta
+        FROM transaction_detail_modifiers m
+        JOIN transaction_details d ON d.id = m.transaction_detail_id
+        WHERE d.transaction_id = ANY($1) AND d.tenant_id = $2
+        ORDER BY m.id
+    `, ids, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var detailID int
+		var m models.TransactionDetailModifier
+		if err := rows.Scan(&detailID, &m.ModifierID, &m.Name, &m.PriceDelta); err != nil {
+			return err
+		}
+		pos := byDetail[detailID]
+		d := &transactions[pos.transaction].Details[pos.detail]
+		d.Modifiers = append(d.Modifiers, m)
+	}
+	return rows.Err()
 }
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UnitRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewUnitRepository(pool *pgxpool.Pool, tenantID int) *UnitRepository {
+	return &UnitRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+const unitColumns = `id, product_id, name, factor, price, COALESCE(barcode, ''), sellable, purchasable`
+
+func scanUnit(row pgx.Row, u *models.ProductUnit) error {
+	return row.Scan(&u.ID, &u.ProductID, &u.Name, &u.Factor, &u.Price, &u.Barcode, &u.Sellable, &u.Purchasable)
+}
+
+func (repo *UnitRepository) GetByProductID(ctx context.Context, productID int) ([]models.ProductUnit, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.pool.Query(ctx, `SELECT `+unitColumns+` FROM product_units WHERE product_id = $1 AND tenant_id = $2 ORDER BY factor, id`,
+		productID, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := make([]models.ProductUnit, 0)
+	for rows.Next() {
+		var u models.ProductUnit
+		if err := scanUnit(rows, &u); err != nil {
+			return nil, err
+		}
+		units = append(units, u)
+	}
+	return units, rows.Err()
+}
+
+func (repo *UnitRepository) GetByID(ctx context.Context, id int) (*models.ProductUnit, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var u models.ProductUnit
+	if err := scanUnit(repo.pool.QueryRow(ctx, `SELECT `+unitColumns+` FROM product_units WHERE id = $1 AND tenant_id = $2`, id, repo.tenantID), &u); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("unit")
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UnitRepository) GetByBarcode(ctx context.Context, barcode string) (*models.ProductUnit, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var u models.ProductUnit
+	if err := scanUnit(repo.pool.QueryRow(ctx, `SELECT `+unitColumns+` FROM product_units WHERE barcode = $1 AND tenant_id = $2`, barcode, repo.tenantID), &u); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("barcode")
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UnitRepository) Create(ctx context.Context, u *models.ProductUnit) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `
+		INSERT INTO product_units (tenant_id, product_id, name, factor, price, barcode, sellable, purchasable)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8) RETURNING id`
+	return repo.pool.QueryRow(ctx, query, repo.tenantID, u.ProductID, u.Name, u.Factor, u.Price, u.Barcode, u.Sellable, u.Purchasable).Scan(&u.ID)
+}
+
+func (repo *UnitRepository) Update(ctx context.Context, u *models.ProductUnit) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `UPDATE product_units
+				   SET name = $1, factor = $2, price = $3, barcode = NULLIF($4, ''), sellable = $5, purchasable = $6
+				   WHERE id = $7 AND tenant_id = $8`
+	ct, err := repo.pool.Exec(ctx, query, u.Name, u.Factor, u.Price, u.Barcode, u.Sellable, u.Purchasable, u.ID, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("unit")
+	}
+	return nil
+}
+
+func (repo *UnitRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `DELETE FROM product_units WHERE id = $1 AND tenant_id = $2`, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("unit")
+	}
+	return nil
+}
+
+// ReceiveStock menambah stok produk (atau varian) sebanyak BaseQuantity satuan dasar.
+func (repo *UnitRepository) ReceiveStock(ctx context.Context, receipt *models.StockReceipt) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var (
+		ct  pgconn.CommandTag
+		err error
+	)
+	if receipt.VariantID != nil {
+		ct, err = repo.pool.Exec(ctx, `UPDATE product_variants SET stock = stock + $1 WHERE id = $2 AND product_id = $3 AND tenant_id = $4`,
+			receipt.BaseQuantity, *receipt.VariantID, receipt.ProductID, repo.tenantID)
+	} else {
+		ct, err = repo.pool.Exec(ctx, `UPDATE products SET stock = stock + $1 WHERE id = $2 AND tenant_id = $3`,
+			receipt.BaseQuantity, receipt.ProductID, repo.tenantID)
+	}
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("product")
+	}
+	return nil
+}
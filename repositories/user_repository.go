@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewUserRepository(pool *pgxpool.Pool, tenantID int) *UserRepository {
+	return &UserRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+// userColumns memakai alias u agar bisa dipakai juga di query yang join ke users.
+const userColumns = `u.id, u.username, u.name, u.role, u.password_hash, COALESCE(u.pin_hash, ''),
+	u.failed_pin_attempts, u.pin_locked_until, u.active, u.created_at`
+
+func scanUser(row pgx.Row, u *models.User) error {
+	if err := row.Scan(&u.ID, &u.Username, &u.Name, &u.Role, &u.PasswordHash, &u.PINHash,
+		&u.FailedPINAttempts, &u.PINLockedUntil, &u.Active, &u.CreatedAt); err != nil {
+		return err
+	}
+	u.HasPIN = u.PINHash != ""
+	return nil
+}
+
+func (repo *UserRepository) GetAll(ctx context.Context) ([]models.User, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := repo.pool.Query(ctx, `SELECT `+userColumns+` FROM users u WHERE u.tenant_id = $1 ORDER BY u.username`, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		var u models.User
+		if err := scanUser(rows, &u); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (repo *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var u models.User
+	if err := scanUser(repo.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users u WHERE u.id = $1 AND u.tenant_id = $2`, id, repo.tenantID), &u); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("user")
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var u models.User
+	if err := scanUser(repo.pool.QueryRow(ctx, `
+		SELECT `+userColumns+` FROM users u WHERE u.username = $1 AND u.tenant_id = $2
+	`, username, repo.tenantID), &u); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("user")
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (repo *UserRepository) Count(ctx context.Context) (int, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	var n int
+	err := repo.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE tenant_id = $1`, repo.tenantID).Scan(&n)
+	return n, err
+}
+
+func (repo *UserRepository) Create(ctx context.Context, u *models.User) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `
+		INSERT INTO users (tenant_id, username, name, role, password_hash, pin_hash, active)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+		RETURNING id, created_at`
+	return repo.pool.QueryRow(ctx, query, repo.tenantID, u.Username, u.Name, u.Role, u.PasswordHash, u.PINHash, u.Active).Scan(&u.ID, &u.CreatedAt)
+}
+
+// Update menyimpan data user termasuk password_hash; user yang dinonaktifkan
+// langsung kehilangan semua sesinya.
+func (repo *UserRepository) Update(ctx context.Context, u *models.User) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := repo.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	ct, err := tx.Exec(ctx, `
+		UPDATE users SET username = $1, name = $2, role = $3, password_hash = $4, pin_hash = NULLIF($5, ''), active = $6
+		WHERE id = $7 AND tenant_id = $8
+	`, u.Username, u.Name, u.Role, u.PasswordHash, u.PINHash, u.Active, u.ID, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("user")
+	}
+	if !u.Active {
+		if err := revokeUserSessions(ctx, tx, repo.tenantID, u.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RecordPINFailure menambah hitungan PIN salah; begitu mencapai maxAttempts, login PIN
+// dikunci selama lockFor dan hitungan dimulai lagi dari nol.
+func (repo *UserRepository) RecordPINFailure(ctx context.Context, id, maxAttempts int, lockFor time.Duration) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := repo.pool.Exec(ctx, `
+		UPDATE users
+		SET failed_pin_attempts = CASE WHEN failed_pin_attempts + 1 >= $2 THEN 0 ELSE failed_pin_attempts + 1 END,
+		    pin_locked_until = CASE WHEN failed_pin_attempts + 1 >= $2 THEN now() + $3::interval ELSE pin_locked_until END
+		WHERE id = $1 AND tenant_id = $4
+	`, id, maxAttempts, lockFor, repo.tenantID)
+	return err
+}
+
+func (repo *UserRepository) ResetPINFailures(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := repo.pool.Exec(ctx, `
+		UPDATE users SET failed_pin_attempts = 0, pin_locked_until = NULL
+		WHERE id = $1 AND tenant_id = $2 AND (failed_pin_attempts > 0 OR pin_locked_until IS NOT NULL)
+	`, id, repo.tenantID)
+	return err
+}
+
+func (repo *UserRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	ct, err := repo.pool.Exec(ctx, `DELETE FROM users WHERE id = $1 AND tenant_id = $2`, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("user")
+	}
+	return nil
+}
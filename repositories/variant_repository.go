@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type VariantRepository struct {
+	pool     pgDB
+	tenantID int
+}
+
+func NewVariantRepository(pool *pgxpool.Pool, tenantID int) *VariantRepository {
+	return &VariantRepository{pool: pgDB{pool}, tenantID: tenantID}
+}
+
+func (repo *VariantRepository) GetByProductID(ctx context.Context, productID int) ([]models.ProductVariant, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `
+		SELECT id, product_id, sku, name, options, price, stock
+		FROM product_variants
+		WHERE product_id = $1 AND tenant_id = $2
+		ORDER BY id`
+	rows, err := repo.pool.Query(ctx, query, productID, repo.tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := make([]models.ProductVariant, 0)
+	for rows.Next() {
+		var v models.ProductVariant
+		if err := rows.Scan(&v.ID, &v.ProductID, &v.SKU, &v.Name, &v.Options, &v.Price, &v.Stock); err != nil {
+			return nil, err
+		}
+		variants = append(variants, v)
+	}
+	return variants, rows.Err()
+}
+
+func (repo *VariantRepository) GetByID(ctx context.Context, id int) (*models.ProductVariant, error) {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `
+		SELECT id, product_id, sku, name, options, price, stock
+		FROM product_variants
+		WHERE id = $1 AND tenant_id = $2`
+	var v models.ProductVariant
+	err := repo.pool.QueryRow(ctx, query, id, repo.tenantID).Scan(&v.ID, &v.ProductID, &v.SKU, &v.Name, &v.Options, &v.Price, &v.Stock)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.NotFound("variant")
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (repo *VariantRepository) Create(ctx context.Context, v *models.ProductVariant) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `
+		INSERT INTO product_variants (tenant_id, product_id, sku, name, options, price, stock)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	return repo.pool.QueryRow(ctx, query, repo.tenantID, v.ProductID, v.SKU, v.Name, v.Options, v.Price, v.Stock).Scan(&v.ID)
+}
+
+func (repo *VariantRepository) Update(ctx context.Context, v *models.ProductVariant) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `UPDATE product_variants
+				   SET sku = $1, name = $2, options = $3, price = $4, stock = $5
+				   WHERE id = $6 AND tenant_id = $7`
+	ct, err := repo.pool.Exec(ctx, query, v.SKU, v.Name, v.Options, v.Price, v.Stock, v.ID, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("variant")
+	}
+	return nil
+}
+
+func (repo *VariantRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := WithQueryTimeout(ctx)
+	defer cancel()
+
+	const query = `DELETE FROM product_variants WHERE id = $1 AND tenant_id = $2`
+	ct, err := repo.pool.Exec(ctx, query, id, repo.tenantID)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return models.NotFound("variant")
+	}
+	return nil
+}
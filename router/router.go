@@ -0,0 +1,94 @@
+// Package router membungkus http.ServeMux dengan pola "METHOD /path/{id}" (Go 1.22)
+// agar 404 dan 405 ditulis sebagai problem JSON, OPTIONS dijawab dengan header Allow,
+// dan path lama bisa tetap dilayani sebagai alias usang.
+package router
+
+import (
+	"kasir-api/problem"
+	"net/http"
+	"strings"
+)
+
+type Router struct {
+	mux *http.ServeMux
+}
+
+func New() *Router {
+	return &Router{mux: http.NewServeMux()}
+}
+
+// HandleFunc mendaftarkan handler untuk pola ServeMux, mis. "GET /api/products/{id}".
+// Pola GET juga melayani HEAD.
+func (rt *Router) HandleFunc(pattern string, handler http.HandlerFunc) {
+	rt.mux.HandleFunc(pattern, handler)
+}
+
+// Handle mendaftarkan http.Handler, mis. handler tenant di bawah "/".
+func (rt *Router) Handle(pattern string, handler http.Handler) {
+	rt.mux.Handle(pattern, handler)
+}
+
+// Alias meneruskan semua request di bawah oldPrefix ke path yang sama di bawah newPrefix
+// (mis. /api/product/5 → /api/products/5). Respons diberi header Deprecation dan Link
+// ke path baru agar klien bisa pindah.
+func (rt *Router) Alias(oldPrefix, newPrefix string) {
+	rt.mux.HandleFunc(oldPrefix, func(w http.ResponseWriter, r *http.Request) {
+		path := newPrefix + strings.TrimPrefix(r.URL.Path, oldPrefix)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+path+`>; rel="successor-version"`)
+
+		u := *r.URL
+		u.Path, u.RawPath = path, ""
+		r2 := r.WithContext(r.Context())
+		r2.URL = &u
+		rt.ServeHTTP(w, r2)
+	})
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// pattern kosong berarti ServeMux akan menjawab 404 atau 405 sendiri (text/plain);
+	// statusnya ditangkap dulu lalu ditulis ulang sebagai problem JSON
+	h, pattern := rt.mux.Handler(r)
+	if pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	rec := &statusRecorder{header: http.Header{}}
+	h.ServeHTTP(rec, r)
+	if allow := rec.header.Get("Allow"); allow != "" {
+		w.Header().Set("Allow", allow)
+		// preflight CORS: path ada, cukup beri tahu method yang didukung
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	switch rec.status {
+	case http.StatusMethodNotAllowed:
+		problem.Write(w, r, rec.status, "Method not allowed")
+	default:
+		problem.Write(w, r, http.StatusNotFound, "Not found")
+	}
+}
+
+// statusRecorder menampung respons bawaan ServeMux tanpa menuliskannya ke klien.
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (s *statusRecorder) Header() http.Header { return s.header }
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return len(b), nil
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+}
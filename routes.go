@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"kasir-api/auth"
+	"kasir-api/handlers"
+	"kasir-api/middleware"
+	"kasir-api/problem"
+	"kasir-api/repositories"
+	"kasir-api/router"
+	"kasir-api/services"
+	"net/http"
+)
+
+// newTenantHandler merakit semua service dan route untuk satu tenant di atas stores dari
+// backend mana pun (lihat backend.go). Setiap repository terikat ke tenantID, jadi handler
+// ini tidak bisa menyentuh data toko lain. Rate limiter dipakai bersama karena ID API key
+// unik di semua tenant.
+func newTenantHandler(stores repositories.Stores, config Config, issuer *auth.Issuer, limiter *middleware.RateLimiter, tenantID int) http.Handler {
+	// Auth: user, sesi login, dan access token
+	userRepo := stores.Users
+	sessionRepo := stores.Sessions
+	terminalRepo := stores.Terminals
+	apiKeyRepo := stores.APIKeys
+	userService := services.NewUserService(userRepo)
+	terminalService := services.NewTerminalService(terminalRepo, userRepo)
+	authService := services.NewAuthService(userRepo, sessionRepo, terminalRepo, apiKeyRepo, issuer, config.RefreshTokenTTL, tenantID)
+	authHandler := handlers.NewAuthHandler(authService, userService)
+	userHandler := handlers.NewUserHandler(userService)
+	terminalHandler := handlers.NewTerminalHandler(terminalService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(services.NewAPIKeyService(apiKeyRepo))
+	guard := middleware.NewGuard(authService)
+
+	// Audit log: siapa mengubah apa (produk, kategori, checkout, refund)
+	auditService := services.NewAuditService(stores.Audit)
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	productRepo := stores.Products
+	variantRepo := stores.Variants
+	unitRepo := stores.Units
+	categoryRepo := stores.Categories
+	productService := services.NewProductService(productRepo, categoryRepo, variantRepo, unitRepo)
+	// Riwayat harga & harga terjadwal (scheduler-nya dijalankan sekali untuk semua tenant di main)
+	priceHistoryService := services.NewPriceHistoryService(stores.PriceHistory, productRepo)
+	productHandler := handlers.NewProductHandler(productService, priceHistoryService, auditService)
+	// Variant
+	variantService := services.NewVariantService(variantRepo, productRepo)
+	variantHandler := handlers.NewVariantHandler(variantService)
+	// Unit of measure
+	unitService := services.NewUnitService(unitRepo, productRepo)
+	unitHandler := handlers.NewUnitHandler(unitService)
+	// Category
+	categoryService := services.NewCategoryService(categoryRepo)
+	categoryHandler := handlers.NewCategoryHandler(categoryService, auditService)
+	// Modifier
+	modifierService := services.NewModifierService(stores.Modifiers)
+	modifierHandler := handlers.NewModifierHandler(modifierService)
+	// Customer
+	customerRepo := stores.Customers
+	transactionRepo := stores.Transactions
+	loyaltyRepo := stores.Loyalty
+	receivableRepo := stores.Receivables
+	customerService := services.NewCustomerService(customerRepo, transactionRepo, loyaltyRepo, receivableRepo)
+	customerHandler := handlers.NewCustomerHandler(customerService)
+	// Loyalty
+	loyaltyService := services.NewLoyaltyService(loyaltyRepo)
+	loyaltyHandler := handlers.NewLoyaltyHandler(loyaltyService)
+	// Price list
+	priceListRepo := stores.PriceLists
+	priceListService := services.NewPriceListService(priceListRepo)
+	priceListHandler := handlers.NewPriceListHandler(priceListService)
+	// Transaction
+	transactionService := services.NewTransactionService(transactionRepo, productRepo, unitRepo, customerRepo, priceListRepo, config.MaxCashierDiscount)
+	transactionHandler := handlers.NewTransactionHandler(transactionService, guard, auditService)
+	// Report
+	reportService := services.NewReportService(stores.Reports, receivableRepo)
+	reportHandler := handlers.NewReportHandler(reportService)
+
+	// Setup routes: pola "METHOD /path/{id}", method lain dijawab 405 dengan header Allow
+	rt := router.New()
+	rt.HandleFunc("POST /api/auth/login", authHandler.HandleLogin)
+	rt.HandleFunc("POST /api/auth/pin-login", authHandler.HandlePINLogin)
+	rt.HandleFunc("POST /api/auth/refresh", authHandler.HandleRefresh)
+	rt.HandleFunc("POST /api/auth/logout", authHandler.HandleLogout)
+	rt.HandleFunc("GET /api/auth/me", authHandler.HandleMe)
+	// Izin per route: GET memakai izin baca, method lain izin tulis (lihat auth/permissions.go)
+	rt.HandleFunc("GET /api/users", guard.Require(auth.UsersManage, userHandler.GetAllUsers))
+	rt.HandleFunc("POST /api/users", guard.Require(auth.UsersManage, userHandler.CreateUser))
+	rt.HandleFunc("GET /api/users/{id}", guard.Require(auth.UsersManage, userHandler.GetUserByID))
+	rt.HandleFunc("PUT /api/users/{id}", guard.Require(auth.UsersManage, userHandler.UpdateUser))
+	rt.HandleFunc("DELETE /api/users/{id}", guard.Require(auth.UsersManage, userHandler.DeleteUser))
+	rt.HandleFunc("GET /api/terminals", guard.Require(auth.UsersManage, terminalHandler.GetAllTerminals))
+	rt.HandleFunc("POST /api/terminals", guard.Require(auth.UsersManage, terminalHandler.CreateTerminal))
+	rt.HandleFunc("GET /api/terminals/{id}", guard.Require(auth.UsersManage, terminalHandler.GetTerminalByID))
+	rt.HandleFunc("PUT /api/terminals/{id}", guard.Require(auth.UsersManage, terminalHandler.UpdateTerminal))
+	rt.HandleFunc("DELETE /api/terminals/{id}", guard.Require(auth.UsersManage, terminalHandler.DeleteTerminal))
+	rt.HandleFunc("GET /api/api-keys", guard.Require(auth.APIKeysManage, apiKeyHandler.GetAllAPIKeys))
+	rt.HandleFunc("POST /api/api-keys", guard.Require(auth.APIKeysManage, apiKeyHandler.CreateAPIKey))
+	rt.HandleFunc("GET /api/api-keys/{id}", guard.Require(auth.APIKeysManage, apiKeyHandler.GetAPIKeyByID))
+	rt.HandleFunc("PUT /api/api-keys/{id}", guard.Require(auth.APIKeysManage, apiKeyHandler.UpdateAPIKey))
+	// DELETE mencabut kunci (riwayatnya tetap tersimpan)
+	rt.HandleFunc("DELETE /api/api-keys/{id}", guard.Require(auth.APIKeysManage, apiKeyHandler.RevokeAPIKey))
+	rt.HandleFunc("POST /api/api-keys/{id}/rotate", guard.Require(auth.APIKeysManage, apiKeyHandler.RotateAPIKey))
+	rt.HandleFunc("GET /api/audit-logs", guard.Require(auth.AuditRead, auditHandler.HandleAuditLogs))
+	rt.HandleFunc("GET /api/products", guard.Require(auth.ProductsRead, productHandler.GetAll))
+	rt.HandleFunc("POST /api/products", guard.Require(auth.ProductsWrite, productHandler.Create))
+	rt.HandleFunc("GET /api/products/{id}", guard.Require(auth.ProductsRead, productHandler.GetByID))
+	rt.HandleFunc("PUT /api/products/{id}", guard.Require(auth.ProductsWrite, productHandler.Update))
+	rt.HandleFunc("DELETE /api/products/{id}", guard.Require(auth.ProductsWrite, productHandler.Delete))
+	rt.HandleFunc("GET /api/products/{id}/price-history", guard.Require(auth.ProductsRead, productHandler.GetPriceHistory))
+	rt.HandleFunc("GET /api/products/{id}/scheduled-prices", guard.Require(auth.ProductsRead, productHandler.GetScheduledPrices))
+	rt.HandleFunc("POST /api/products/{id}/scheduled-prices", guard.Require(auth.ProductsWrite, productHandler.SchedulePrice))
+	rt.HandleFunc("DELETE /api/products/{id}/scheduled-prices/{sid}", guard.Require(auth.ProductsWrite, productHandler.CancelScheduledPrice))
+	rt.HandleFunc("GET /api/categories", guard.Require(auth.CategoriesRead, categoryHandler.GetAllCategories))
+	rt.HandleFunc("POST /api/categories", guard.Require(auth.CategoriesWrite, categoryHandler.CreateCategory))
+	rt.HandleFunc("GET /api/categories/{id}", guard.Require(auth.CategoriesRead, categoryHandler.GetCategoryByID))
+	rt.HandleFunc("PUT /api/categories/{id}", guard.Require(auth.CategoriesWrite, categoryHandler.UpdateCategory))
+	rt.HandleFunc("DELETE /api/categories/{id}", guard.Require(auth.CategoriesWrite, categoryHandler.DeleteCategory))
+	rt.HandleFunc("GET /api/variants", guard.Require(auth.ProductsRead, variantHandler.GetVariantsByProduct))
+	rt.HandleFunc("POST /api/variants", guard.Require(auth.ProductsWrite, variantHandler.CreateVariant))
+	rt.HandleFunc("GET /api/variants/{id}", guard.Require(auth.ProductsRead, variantHandler.GetVariantByID))
+	rt.HandleFunc("PUT /api/variants/{id}", guard.Require(auth.ProductsWrite, variantHandler.UpdateVariant))
+	rt.HandleFunc("DELETE /api/variants/{id}", guard.Require(auth.ProductsWrite, variantHandler.DeleteVariant))
+	rt.HandleFunc("GET /api/product-units", guard.Require(auth.ProductsRead, unitHandler.GetUnitsByProduct))
+	rt.HandleFunc("POST /api/product-units", guard.Require(auth.ProductsWrite, unitHandler.CreateUnit))
+	rt.HandleFunc("GET /api/product-units/{id}", guard.Require(auth.ProductsRead, unitHandler.GetUnitByID))
+	rt.HandleFunc("PUT /api/product-units/{id}", guard.Require(auth.ProductsWrite, unitHandler.UpdateUnit))
+	rt.HandleFunc("DELETE /api/product-units/{id}", guard.Require(auth.ProductsWrite, unitHandler.DeleteUnit))
+	rt.HandleFunc("POST /api/stock/receive", guard.Require(auth.StockWrite, unitHandler.HandleReceiveStock))
+	rt.HandleFunc("GET /api/modifier-groups", guard.Require(auth.ProductsRead, modifierHandler.GetAllGroups))
+	rt.HandleFunc("POST /api/modifier-groups", guard.Require(auth.PricingWrite, modifierHandler.CreateGroup))
+	rt.HandleFunc("GET /api/modifier-groups/{id}", guard.Require(auth.ProductsRead, modifierHandler.GetGroupByID))
+	rt.HandleFunc("PUT /api/modifier-groups/{id}", guard.Require(auth.PricingWrite, modifierHandler.UpdateGroup))
+	rt.HandleFunc("DELETE /api/modifier-groups/{id}", guard.Require(auth.PricingWrite, modifierHandler.DeleteGroup))
+	rt.HandleFunc("GET /api/customers", guard.Require(auth.CustomersRead, customerHandler.GetAllCustomers))
+	rt.HandleFunc("POST /api/customers", guard.Require(auth.CustomersWrite, customerHandler.CreateCustomer))
+	rt.HandleFunc("GET /api/customers/{id}", guard.Require(auth.CustomersRead, customerHandler.GetCustomerByID))
+	rt.HandleFunc("PUT /api/customers/{id}", guard.Require(auth.CustomersWrite, customerHandler.UpdateCustomer))
+	rt.HandleFunc("DELETE /api/customers/{id}", guard.Require(auth.CustomersWrite, customerHandler.DeleteCustomer))
+	rt.HandleFunc("GET /api/customers/{id}/transactions", guard.Require(auth.CustomersRead, customerHandler.GetCustomerTransactions))
+	rt.HandleFunc("GET /api/customers/{id}/summary", guard.Require(auth.CustomersRead, customerHandler.GetCustomerSummary))
+	rt.HandleFunc("GET /api/customers/{id}/points", guard.Require(auth.CustomersRead, customerHandler.GetCustomerPoints))
+	rt.HandleFunc("GET /api/customers/{id}/receivables", guard.Require(auth.CustomersRead, customerHandler.GetCustomerReceivables))
+	rt.HandleFunc("POST /api/customers/{id}/repayments", guard.Require(auth.CustomersWrite, customerHandler.CreateRepayment))
+	rt.HandleFunc("GET /api/loyalty/rules", guard.Require(auth.CustomersRead, loyaltyHandler.GetRules))
+	rt.HandleFunc("PUT /api/loyalty/rules", guard.Require(auth.PricingWrite, loyaltyHandler.UpdateRules))
+	rt.HandleFunc("GET /api/price-lists", guard.Require(auth.ProductsRead, priceListHandler.GetAllPriceLists))
+	rt.HandleFunc("POST /api/price-lists", guard.Require(auth.PricingWrite, priceListHandler.CreatePriceList))
+	rt.HandleFunc("GET /api/price-lists/{id}", guard.Require(auth.ProductsRead, priceListHandler.GetPriceListByID))
+	rt.HandleFunc("PUT /api/price-lists/{id}", guard.Require(auth.PricingWrite, priceListHandler.UpdatePriceList))
+	rt.HandleFunc("DELETE /api/price-lists/{id}", guard.Require(auth.PricingWrite, priceListHandler.DeletePriceList))
+	// ganti seluruh harga di daftar
+	rt.HandleFunc("PUT /api/price-lists/{id}/items", guard.Require(auth.PricingWrite, priceListHandler.ReplaceItems))
+	rt.HandleFunc("POST /api/checkout", guard.Require(auth.TransactionsCreate, transactionHandler.Checkout))
+	rt.HandleFunc("GET /api/transactions/{id}", guard.Require(auth.TransactionsRead, transactionHandler.GetByID))
+	rt.HandleFunc("POST /api/transactions/{id}/refund", guard.Require(auth.TransactionsVoid, transactionHandler.Refund))
+	rt.HandleFunc("GET /api/report/today", guard.Require(auth.ReportsRead, reportHandler.HandleReportToday))
+	rt.HandleFunc("GET /api/report/receivables-aging", guard.Require(auth.ReportsRead, reportHandler.HandleReceivablesAging))
+
+	// Path tunggal lama tetap dilayani sebagai alias usang (header Deprecation + Link)
+	for _, a := range deprecatedAliases {
+		rt.Alias(a.old, a.plural)
+	}
+
+	//localhost:8080/api
+	rt.HandleFunc("GET /api", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{
+			"status":  "Success",
+			"message": "Welcome to the Cashier API",
+			"endpoints": []string{
+				"GET /api/tenants (X-Platform-Token)",
+				"POST /api/tenants (X-Platform-Token)",
+
+				"POST /api/auth/login",
+				"POST /api/auth/pin-login",
+				"POST /api/auth/refresh",
+				"POST /api/auth/logout",
+				"GET /api/auth/me",
+
+				"GET /api/users",
+				"POST /api/users",
+				"GET /api/users/{id}",
+				"PUT /api/users/{id}",
+				"DELETE /api/users/{id}",
+
+				"GET /api/terminals",
+				"POST /api/terminals",
+				"GET /api/terminals/{id}",
+				"PUT /api/terminals/{id}",
+				"DELETE /api/terminals/{id}",
+
+				"GET /api/api-keys",
+				"POST /api/api-keys",
+				"GET /api/api-keys/{id}",
+				"PUT /api/api-keys/{id}",
+				"DELETE /api/api-keys/{id}",
+				"POST /api/api-keys/{id}/rotate",
+
+				"GET /api/audit-logs?entity={entity}&entity_id={id}&action={action}&actor_id={id}&from={date}&to={date}",
+
+				"GET /api/products",
+				"POST /api/products",
+				"GET /api/products/{id}",
+				"PUT /api/products/{id}",
+				"DELETE /api/products/{id}",
+				"GET /api/products?name={name}",
+				"GET /api/products/{id}/price-history",
+				"GET /api/products/{id}/scheduled-prices",
+				"POST /api/products/{id}/scheduled-prices",
+				"DELETE /api/products/{id}/scheduled-prices/{sid}",
+
+				"GET /api/categories",
+				"POST /api/categories",
+				"GET /api/categories/{id}",
+				"PUT /api/categories/{id}",
+				"DELETE /api/categories/{id}",
+
+				"GET /api/variants?product_id={id}",
+				"POST /api/variants",
+				"GET /api/variants/{id}",
+				"PUT /api/variants/{id}",
+				"DELETE /api/variants/{id}",
+
+				"GET /api/product-units?product_id={id}",
+				"POST /api/product-units",
+				"GET /api/product-units/{id}",
+				"PUT /api/product-units/{id}",
+				"DELETE /api/product-units/{id}",
+				"POST /api/stock/receive",
+
+				"GET /api/modifier-groups?product_id={id}",
+				"POST /api/modifier-groups",
+				"GET /api/modifier-groups/{id}",
+				"PUT /api/modifier-groups/{id}",
+				"DELETE /api/modifier-groups/{id}",
+
+				"GET /api/customers?q={search}",
+				"GET /api/customers?phone={phone}",
+				"POST /api/customers",
+				"GET /api/customers/{id}",
+				"PUT /api/customers/{id}",
+				"DELETE /api/customers/{id}",
+				"GET /api/customers/{id}/transactions",
+				"GET /api/customers/{id}/summary",
+				"GET /api/customers/{id}/points",
+				"GET /api/customers/{id}/receivables",
+				"POST /api/customers/{id}/repayments",
+
+				"GET /api/loyalty/rules",
+				"PUT /api/loyalty/rules",
+
+				"GET /api/price-lists",
+				"POST /api/price-lists",
+				"GET /api/price-lists/{id}",
+				"PUT /api/price-lists/{id}",
+				"DELETE /api/price-lists/{id}",
+				"PUT /api/price-lists/{id}/items",
+
+				"POST /api/checkout",
+				"GET /api/transactions/{id}",
+				"POST /api/transactions/{id}/refund",
+				"GET /api/report/today",
+				"GET /api/report/today?group_by=variant",
+				"GET /api/report/receivables-aging",
+				"Comming Soon GET /api/report?date={date}",
+			},
+		}); err != nil {
+			problem.Error(w, r, err)
+			return
+		}
+	})
+
+	// Semua route wajib access token atau X-API-Key kecuali login dan refresh
+	// (refresh dipakai saat access token habis). API key dibatasi per menit.
+	requireAuth := middleware.Authenticate(authService, "/api/auth/login", "/api/auth/pin-login", "/api/auth/refresh")
+
+	return requireAuth(limiter.Limit(rt))
+}
+
+// deprecatedAliases memetakan prefix path tunggal lama ke path jamak, mis. /api/product/5
+// → /api/products/5.
+var deprecatedAliases = []struct{ old, plural string }{
+	{"/api/user/", "/api/users/"},
+	{"/api/terminal/", "/api/terminals/"},
+	{"/api/api-key/", "/api/api-keys/"},
+	{"/api/product/", "/api/products/"},
+	{"/api/category/", "/api/categories/"},
+	{"/api/variant/", "/api/variants/"},
+	{"/api/product-unit/", "/api/product-units/"},
+	{"/api/modifier-group/", "/api/modifier-groups/"},
+	{"/api/customer/", "/api/customers/"},
+	{"/api/price-list/", "/api/price-lists/"},
+	{"/api/transaction/", "/api/transactions/"},
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kasir-api/database"
+	"kasir-api/handlers"
+	"kasir-api/middleware"
+	"kasir-api/router"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"syscall"
+	"time"
+)
+
+// Batas bawaan server HTTP; bisa diubah lewat HTTP_*_TIMEOUT, SHUTDOWN_TIMEOUT, dan
+// MAX_BODY_BYTES. WriteTimeout harus lebih lama dari batas waktu database (lihat
+// repositories.Timeouts) agar error timeout query masih sempat dikirim ke klien.
+const (
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultReadTimeout       = 15 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 60 * time.Second
+	defaultShutdownTimeout   = 20 * time.Second
+	defaultMaxBodyBytes      = 1 << 20
+)
+
+// newServer membuat http.Server dengan batas waktu baca/tulis/idle dan semua middleware
+// tingkat server.
+func newServer(config Config, h http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + config.Port, // semua interface (IPv4/IPv6)
+		Handler:           withServerMiddleware(h, config.MaxBodyBytes),
+		ReadHeaderTimeout: defaultReadHeaderTimeout,
+		ReadTimeout:       config.HTTPReadTimeout,
+		WriteTimeout:      config.HTTPWriteTimeout,
+		IdleTimeout:       config.HTTPIdleTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+}
+
+// withServerMiddleware memasang lapisan yang dipakai semua request, termasuk endpoint
+// platform dan health check. Recover di dalam AccessLog agar panic tercatat sebagai 500.
+// Probe health check tidak memenuhi access log kecuali LOG_LEVEL=debug.
+func withServerMiddleware(h http.Handler, maxBodyBytes int64) http.Handler {
+	logger := slog.Default()
+	return middleware.Chain(h,
+		middleware.RequestID,
+		middleware.AccessLog(logger, "/healthz", "/readyz"),
+		middleware.Recover(logger),
+		middleware.MaxBodySize(maxBodyBytes),
+	)
+}
+
+// Diisi saat build, mis.:
+//
+//	go build -ldflags "-X main.commit=$(git rev-parse --short HEAD) -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	commit    string
+	buildTime string
+)
+
+// buildInfo untuk /version. Tanpa -ldflags, revisi dan waktu commit yang disematkan
+// go build (info VCS) dipakai sebagai gantinya.
+func buildInfo() handlers.BuildInfo {
+	info := handlers.BuildInfo{Commit: commit, BuildTime: buildTime, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok && info.Commit == "" {
+		var dirty bool
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Commit = s.Value
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			case "vcs.modified":
+				dirty = s.Value == "true"
+			}
+		}
+		if dirty && info.Commit != "" {
+			info.Commit += "-dirty"
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	return info
+}
+
+// handleHealth memasang /healthz, /readyz, dan /version di router terluar, sebelum
+// autentikasi dan resolusi tenant.
+func handleHealth(rt *router.Router, h *handlers.HealthHandler) {
+	rt.HandleFunc("GET /healthz", h.Healthz)
+	rt.HandleFunc("GET /readyz", h.Readyz)
+	rt.HandleFunc("GET /version", h.Version)
+}
+
+// migrationsApplied mengubah hasil PendingMigrations menjadi hasil pemeriksaan /readyz.
+func migrationsApplied(pending []database.Migration, err error) error {
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending migrations, first %04d_%s", len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
+
+// listenAndServe menjalankan srv di srv.Addr sampai SIGINT/SIGTERM. Sinyal kedua selama
+// menunggu request selesai langsung mematikan proses.
+func listenAndServe(srv *http.Server, config Config, health *handlers.HealthHandler) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return serve(ctx, srv, ln, config, health)
+}
+
+// serve melayani srv di ln sampai ctx selesai. Setelah itu /readyz langsung gagal; server
+// masih melayani selama ShutdownDelay agar load balancer sempat melepas instance ini, lalu
+// berhenti menerima koneksi baru dan menunggu request yang sedang berjalan (mis. checkout)
+// selesai, paling lama ShutdownTimeout; sisanya diputus paksa sehingga transaksinya
+// di-rollback.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, config Config, health *handlers.HealthHandler) error {
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("server running", "addr", ln.Addr().String())
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	health.SetShuttingDown()
+	if config.ShutdownDelay > 0 {
+		slog.Info("shutting down, draining before closing listeners", "delay", config.ShutdownDelay)
+		time.Sleep(config.ShutdownDelay)
+	}
+
+	slog.Info("shutting down, waiting for in-flight requests", "timeout", config.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		_ = srv.Close()
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
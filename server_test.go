@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"io"
+	"kasir-api/auth"
+	"kasir-api/handlers"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/repositories/sqlite"
+	"kasir-api/router"
+	"kasir-api/services"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Pembatalan ctx (yang di main berasal dari SIGTERM) harus menggagalkan /readyz, menolak
+// koneksi baru, dan menunggu checkout yang sedang berjalan selesai dan tersimpan sebelum
+// serve kembali.
+func TestServeGracefulShutdown(t *testing.T) {
+	config := Config{
+		MaxCashierDiscount: 10,
+		MaxBodyBytes:       defaultMaxBodyBytes,
+		ShutdownDelay:      300 * time.Millisecond,
+		ShutdownTimeout:    5 * time.Second,
+	}
+	db, err := sqlite.Open("sqlite://" + filepath.Join(t.TempDir(), "kasir.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := sqlite.MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+	stores := sqlite.NewStores(db, models.DefaultTenantID)
+	tea := models.Product{Name: "Teh", Price: 5000, Stock: 10, Unit: "pcs"}
+	if err := stores.Products.Create(context.Background(), &tea); err != nil {
+		t.Fatal(err)
+	}
+	key := models.APIKey{Name: "till", Scopes: []string{string(auth.TransactionsCreate)}}
+	if err := services.NewAPIKeyService(stores.APIKeys).Create(context.Background(), &key); err != nil {
+		t.Fatal(err)
+	}
+
+	// checkout ditahan sampai release ditutup, seperti checkout lambat yang sedang berjalan
+	started, release := make(chan struct{}), make(chan struct{})
+	app := newTenantHandler(stores, config, auth.NewIssuer("test-secret", time.Minute), middleware.NewRateLimiter(), models.DefaultTenantID)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/checkout" {
+			close(started)
+			<-release
+		}
+		app.ServeHTTP(w, r)
+	})
+	health := handlers.NewHealthHandler(buildInfo())
+	rt := router.New()
+	handleHealth(rt, health)
+	rt.Handle("/", slow)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := "http://" + ln.Addr().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() { served <- serve(ctx, newServer(config, rt), ln, config, health) }()
+
+	// setiap probe memakai koneksi baru agar penolakan koneksi terlihat
+	probe := func(path string) (int, error) {
+		client := &http.Client{Timeout: time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+		resp, err := client.Get(base + path)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+	if code, err := probe("/readyz"); err != nil || code != http.StatusOK {
+		t.Fatalf("readyz before shutdown = %d, %v", code, err)
+	}
+
+	type result struct {
+		code int
+		body string
+		err  error
+	}
+	checkout := make(chan result, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodPost, base+"/api/checkout",
+			strings.NewReader(`{"items":[{"product_id":`+strconv.Itoa(tea.ID)+`,"quantity":3}]}`))
+		req.Header.Set("X-API-Key", key.Key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			checkout <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		checkout <- result{code: resp.StatusCode, body: string(body)}
+	}()
+	<-started
+	cancel() // SIGTERM
+
+	if code, err := probe("/readyz"); err != nil || code != http.StatusServiceUnavailable {
+		t.Errorf("readyz during shutdown delay = %d, %v; want 503", code, err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, err := probe("/healthz"); err != nil {
+			break // listener sudah ditutup
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server still accepts new connections after shutdown started")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	select {
+	case err := <-served:
+		t.Fatalf("serve returned %v while a checkout was still in flight", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	res := <-checkout
+	if res.err != nil || res.code != http.StatusOK {
+		t.Fatalf("in-flight checkout = %d %s, %v; want 200", res.code, res.body, res.err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after the in-flight request finished")
+	}
+	p, err := stores.Products.GetByID(context.Background(), tea.ID)
+	if err != nil || p.Stock != 7 {
+		t.Errorf("stock = %+v, %v; want 7 (checkout committed)", p, err)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"slices"
+	"strings"
+)
+
+// apiKeyPrefix menandai kunci API agar mudah dikenali (mis. saat tidak sengaja ter-commit).
+const (
+	apiKeyPrefix       = "kasir_"
+	apiKeyPrefixLength = len(apiKeyPrefix) + 6
+	defaultAPIKeyLimit = 60 // request per menit
+)
+
+type APIKeyService struct {
+	repo repositories.APIKeyStore
+}
+
+func NewAPIKeyService(repo repositories.APIKeyStore) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+func (s *APIKeyService) GetAll(ctx context.Context) ([]models.APIKey, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *APIKeyService) GetByID(ctx context.Context, id int) (*models.APIKey, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create membuat kunci baru dan mengisi k.Key; kunci utuh hanya terlihat di respons ini.
+func (s *APIKeyService) Create(ctx context.Context, k *models.APIKey) error {
+	if err := validateAPIKey(k); err != nil {
+		return err
+	}
+	if err := newAPIKey(k); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, k)
+}
+
+func (s *APIKeyService) Update(ctx context.Context, k *models.APIKey) error {
+	if k.ID == 0 {
+		return models.Validationf("invalid api key ID")
+	}
+	if err := validateAPIKey(k); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, k)
+}
+
+// Rotate menerbitkan kunci baru untuk klien yang sama (scope dan limit tetap).
+func (s *APIKeyService) Rotate(ctx context.Context, id int) (*models.APIKey, error) {
+	k, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := newAPIKey(k); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Rotate(ctx, k.ID, k.Prefix, k.KeyHash); err != nil {
+		return nil, err
+	}
+	k.LastUsedAt = nil
+	return k, nil
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id int) error {
+	return s.repo.Revoke(ctx, id)
+}
+
+func newAPIKey(k *models.APIKey) error {
+	token, _, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+	k.Key = apiKeyPrefix + token
+	k.Prefix = k.Key[:apiKeyPrefixLength]
+	k.KeyHash = auth.HashToken(k.Key)
+	return nil
+}
+
+func validateAPIKey(k *models.APIKey) error {
+	k.Name = strings.TrimSpace(k.Name)
+	if k.Name == "" {
+		return models.Validationf("api key name is required")
+	}
+	if len(k.Scopes) == 0 {
+		return models.Validationf("at least one scope is required")
+	}
+	for i, scope := range k.Scopes {
+		k.Scopes[i] = strings.ToLower(strings.TrimSpace(scope))
+		if !auth.ValidPermission(auth.Permission(k.Scopes[i])) {
+			return models.Validationf("unknown scope %q", scope)
+		}
+	}
+	slices.Sort(k.Scopes)
+	k.Scopes = slices.Compact(k.Scopes)
+	if k.RateLimit < 0 {
+		return models.Validationf("rate_limit must not be negative")
+	}
+	if k.RateLimit == 0 {
+		k.RateLimit = defaultAPIKeyLimit
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"reflect"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditService struct {
+	repo repositories.AuditStore
+}
+
+func NewAuditService(repo repositories.AuditStore) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Change adalah perubahan yang diaudit. Ia mengembalikan ID entitas yang diubah dan
+// snapshot before/after-nya (nil untuk create/delete).
+type Change func(ctx context.Context) (entityID int, before, after any, err error)
+
+// Record menjalankan change dan menyimpan entry beserta snapshot dan diff per field di
+// antara keduanya dalam satu transaksi database: jika audit log gagal ditulis, perubahan
+// ikut dibatalkan. change harus memakai ctx yang diterimanya untuk semua akses data.
+func (s *AuditService) Record(ctx context.Context, entry *models.AuditLog, change Change) error {
+	return s.repo.Record(ctx, func(ctx context.Context) (*models.AuditLog, error) {
+		id, before, after, err := change(ctx)
+		if err != nil {
+			return nil, err
+		}
+		entry.EntityID = &id
+		if entry.Before, err = marshalSnapshot(before); err != nil {
+			return nil, err
+		}
+		if entry.After, err = marshalSnapshot(after); err != nil {
+			return nil, err
+		}
+		if entry.Diff, err = jsonDiff(entry.Before, entry.After); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	})
+}
+
+func (s *AuditService) GetAll(ctx context.Context, f models.AuditFilter) ([]models.AuditLog, error) {
+	switch {
+	case f.Limit < 0:
+		return nil, models.Validationf("limit must not be negative")
+	case f.Limit == 0:
+		f.Limit = defaultAuditLimit
+	case f.Limit > maxAuditLimit:
+		f.Limit = maxAuditLimit
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return nil, models.Validationf("from must be before to")
+	}
+	return s.repo.GetAll(ctx, f)
+}
+
+func marshalSnapshot(v any) (json.RawMessage, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// jsonDiff membandingkan dua objek JSON per field tingkat atas dan mengembalikan
+// field yang berbeda. Snapshot kosong dianggap objek tanpa field.
+func jsonDiff(before, after json.RawMessage) (json.RawMessage, error) {
+	var b, a map[string]any
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &b); err != nil {
+			return nil, err
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &a); err != nil {
+			return nil, err
+		}
+	}
+
+	type change struct {
+		Before any `json:"before"`
+		After  any `json:"after"`
+	}
+	diff := map[string]change{}
+	for k, v := range b {
+		if !reflect.DeepEqual(v, a[k]) {
+			diff[k] = change{Before: v, After: a[k]}
+		}
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok {
+			diff[k] = change{After: v}
+		}
+	}
+	return json.Marshal(diff)
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidOverride    = models.Forbidden("invalid supervisor username or PIN")
+	ErrInvalidTerminal    = errors.New("unknown or inactive terminal")
+	ErrPINLocked          = models.TooManyAttempts("too many wrong PIN attempts, try again later")
+)
+
+// Aturan login PIN: setelah maxPINAttempts kali salah berturut-turut, PIN dikunci selama
+// pinLockout. Sesi terminal berumur satu shift.
+const (
+	maxPINAttempts     = 5
+	pinLockout         = 15 * time.Minute
+	terminalSessionTTL = 12 * time.Hour
+)
+
+type AuthService struct {
+	userRepo     repositories.UserStore
+	sessionRepo  repositories.SessionStore
+	terminalRepo repositories.TerminalStore
+	apiKeyRepo   repositories.APIKeyStore
+	issuer       *auth.Issuer
+	refreshTTL   time.Duration
+	// tenantID adalah toko pemilik repository di atas; token toko lain ditolak.
+	tenantID int
+}
+
+func NewAuthService(userRepo repositories.UserStore, sessionRepo repositories.SessionStore, terminalRepo repositories.TerminalStore, apiKeyRepo repositories.APIKeyStore, issuer *auth.Issuer, refreshTTL time.Duration, tenantID int) *AuthService {
+	return &AuthService{userRepo: userRepo, sessionRepo: sessionRepo, terminalRepo: terminalRepo, apiKeyRepo: apiKeyRepo, issuer: issuer, refreshTTL: refreshTTL, tenantID: tenantID}
+}
+
+// Login memeriksa username/password lalu membuka sesi baru. Username tidak dikenal,
+// password salah, dan user nonaktif sengaja menghasilkan error yang sama.
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.TokenResponse, error) {
+	user, err := s.userRepo.GetByUsername(ctx, strings.ToLower(strings.TrimSpace(req.Username)))
+	if err != nil || !user.Active || !auth.CheckPassword(user.PasswordHash, req.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	refreshToken, refreshHash, err := auth.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	session := models.Session{UserID: user.ID, RefreshHash: refreshHash, ExpiresAt: time.Now().Add(s.refreshTTL)}
+	if err := s.sessionRepo.Create(ctx, &session); err != nil {
+		return nil, err
+	}
+	return s.tokens(user, session.ID, refreshToken)
+}
+
+// PINLogin adalah login cepat kasir di terminal terdaftar. Kasir harus terdaftar di
+// terminal tersebut; sesi kasir sebelumnya di terminal yang sama dicabut (ganti shift).
+func (s *AuthService) PINLogin(ctx context.Context, terminalKey string, req *models.PINLoginRequest) (*models.TokenResponse, error) {
+	terminal, err := s.terminalRepo.GetByKeyHash(ctx, auth.HashToken(terminalKey))
+	if err != nil {
+		return nil, ErrInvalidTerminal
+	}
+	user, err := s.userRepo.GetByUsername(ctx, strings.ToLower(strings.TrimSpace(req.Username)))
+	if err != nil || !user.Active || !slices.Contains(terminal.UserIDs, user.ID) {
+		return nil, ErrInvalidCredentials
+	}
+	if err := s.checkPIN(ctx, user, req.PIN); err != nil {
+		if errors.Is(err, ErrPINLocked) {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.sessionRepo.RevokeTerminalSessions(ctx, terminal.ID); err != nil {
+		return nil, err
+	}
+	refreshToken, refreshHash, err := auth.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	session := models.Session{
+		UserID:      user.ID,
+		TerminalID:  &terminal.ID,
+		RefreshHash: refreshHash,
+		ExpiresAt:   time.Now().Add(terminalSessionTTL),
+	}
+	if err := s.sessionRepo.Create(ctx, &session); err != nil {
+		return nil, err
+	}
+	return s.tokens(user, session.ID, refreshToken)
+}
+
+// checkPIN memeriksa PIN dengan penguncian setelah terlalu banyak percobaan salah.
+func (s *AuthService) checkPIN(ctx context.Context, user *models.User, pin string) error {
+	if user.PINLockedUntil != nil && time.Now().Before(*user.PINLockedUntil) {
+		return ErrPINLocked
+	}
+	if user.PINHash == "" || !auth.CheckPassword(user.PINHash, pin) {
+		if err := s.userRepo.RecordPINFailure(ctx, user.ID, maxPINAttempts, pinLockout); err != nil {
+			return err
+		}
+		return ErrInvalidCredentials
+	}
+	return s.userRepo.ResetPINFailures(ctx, user.ID)
+}
+
+// Refresh menukar refresh token dengan pasangan token baru (refresh token lama tidak berlaku lagi).
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*models.TokenResponse, error) {
+	newToken, newHash, err := auth.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.sessionRepo.Rotate(ctx, auth.HashToken(refreshToken), newHash)
+	if err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil || !user.Active {
+		return nil, auth.ErrInvalidToken
+	}
+	return s.tokens(user, session.ID, newToken)
+}
+
+// Logout mencabut sesi; access token yang terikat ke sesi itu langsung ditolak.
+func (s *AuthService) Logout(ctx context.Context, sessionID int) error {
+	return s.sessionRepo.Revoke(ctx, sessionID)
+}
+
+// Authenticate memeriksa access token dan memastikan sesinya masih aktif di toko ini.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*models.Principal, error) {
+	claims, err := s.issuer.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TenantID != s.tenantID {
+		return nil, auth.ErrInvalidToken
+	}
+	user, terminalID, err := s.sessionRepo.GetActiveUser(ctx, claims.SessionID)
+	if err != nil || user.ID != claims.UserID() {
+		return nil, auth.ErrInvalidToken
+	}
+	return &models.Principal{
+		TenantID:   s.tenantID,
+		UserID:     user.ID,
+		Username:   user.Username,
+		Role:       user.Role,
+		SessionID:  claims.SessionID,
+		TerminalID: terminalID,
+	}, nil
+}
+
+// AuthenticateAPIKey memeriksa kunci API; izinnya dibatasi pada scope kunci tersebut.
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, key string) (*models.Principal, error) {
+	k, err := s.apiKeyRepo.GetActiveByHash(ctx, auth.HashToken(key))
+	if err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+	scopes := k.Scopes
+	if scopes == nil {
+		scopes = []string{} // nil berarti "pakai peran"; kunci tanpa scope tidak boleh apa pun
+	}
+	return &models.Principal{
+		TenantID:  s.tenantID,
+		Username:  "api-key:" + k.Name,
+		APIKeyID:  &k.ID,
+		Scopes:    scopes,
+		RateLimit: k.RateLimit,
+	}, nil
+}
+
+// VerifyOverride memeriksa username dan PIN supervisor yang menyetujui aksi kasir.
+func (s *AuthService) VerifyOverride(ctx context.Context, username, pin string) (*models.Principal, error) {
+	user, err := s.userRepo.GetByUsername(ctx, strings.ToLower(strings.TrimSpace(username)))
+	if err != nil || !user.Active {
+		return nil, ErrInvalidOverride
+	}
+	if err := s.checkPIN(ctx, user, pin); err != nil {
+		if errors.Is(err, ErrPINLocked) {
+			return nil, err
+		}
+		return nil, ErrInvalidOverride
+	}
+	return &models.Principal{TenantID: s.tenantID, UserID: user.ID, Username: user.Username, Role: user.Role}, nil
+}
+
+func (s *AuthService) tokens(user *models.User, sessionID int, refreshToken string) (*models.TokenResponse, error) {
+	accessToken, err := s.issuer.Issue(s.tenantID, user.ID, user.Username, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.issuer.AccessTTL().Seconds()),
+	}, nil
+}
@@ -1,41 +1,56 @@
 package services
 
 import (
-	"fmt"
+	"context"
 	"kasir-api/models"
 	"kasir-api/repositories"
+	"kasir-api/validate"
 )
 
 type CategoryService struct {
-	repo *repositories.CategoryRepository
+	repo repositories.CategoryStore
 }
 
-func NewCategoryService(repo *repositories.CategoryRepository) *CategoryService {
+func NewCategoryService(repo repositories.CategoryStore) *CategoryService {
 	return &CategoryService{repo: repo}
 }
 
-func (s *CategoryService) GetAllCategories() ([]models.Category, error) {
-	return s.repo.GetAllCategories()
+func (s *CategoryService) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	return s.repo.GetAllCategories(ctx)
 }
 
-func (s *CategoryService) CreateCategory(c *models.Category) error {
-	if c.Name == "" {
-		return fmt.Errorf("category name is required")
+func (s *CategoryService) CreateCategory(ctx context.Context, c *models.Category) error {
+	if err := validateCategory(c); err != nil {
+		return err
 	}
-	return s.repo.CreateCategory(c)
+	return s.repo.CreateCategory(ctx, c)
 }
 
-func (s *CategoryService) GetCategoryByID(id int) (*models.Category, error) {
-	return s.repo.GetCategoryByID(id)
+func (s *CategoryService) GetCategoryByID(ctx context.Context, id int) (*models.Category, error) {
+	return s.repo.GetCategoryByID(ctx, id)
 }
 
-func (s *CategoryService) UpdateCategory(c *models.Category) error {
+func (s *CategoryService) UpdateCategory(ctx context.Context, c *models.Category) error {
 	if c.ID == 0 {
-		return fmt.Errorf("invalid category ID")
+		return models.Validationf("invalid category ID")
 	}
-	return s.repo.UpdateCategory(c)
+	if err := validateCategory(c); err != nil {
+		return err
+	}
+	return s.repo.UpdateCategory(ctx, c)
+}
+
+// Validate memeriksa kategori tanpa menyimpannya.
+func (s *CategoryService) Validate(c *models.Category) error {
+	return validateCategory(c)
+}
+
+func validateCategory(c *models.Category) error {
+	var v validate.Validator
+	v.Required("name", c.Name)
+	return v.Err()
 }
 
-func (s *CategoryService) DeleteCategory(id int) error {
-	return s.repo.DeleteCategory(id)
+func (s *CategoryService) DeleteCategory(ctx context.Context, id int) error {
+	return s.repo.DeleteCategory(ctx, id)
 }
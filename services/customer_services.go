@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories"
+	"strings"
+)
+
+// defaultHistoryLimit membatasi riwayat belanja yang dikembalikan jika limit tidak diisi.
+const defaultHistoryLimit = 20
+
+type CustomerService struct {
+	repo            repositories.CustomerStore
+	transactionRepo repositories.TransactionStore
+	loyaltyRepo     repositories.LoyaltyStore
+	receivableRepo  repositories.ReceivableStore
+}
+
+func NewCustomerService(repo repositories.CustomerStore, transactionRepo repositories.TransactionStore, loyaltyRepo repositories.LoyaltyStore, receivableRepo repositories.ReceivableStore) *CustomerService {
+	return &CustomerService{repo: repo, transactionRepo: transactionRepo, loyaltyRepo: loyaltyRepo, receivableRepo: receivableRepo}
+}
+
+func (s *CustomerService) GetAll(ctx context.Context, search string) ([]models.Customer, error) {
+	return s.repo.GetAll(ctx, strings.TrimSpace(search))
+}
+
+func (s *CustomerService) GetByID(ctx context.Context, id int) (*models.Customer, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetByPhone mencari pelanggan dari nomor HP dalam format apa pun (0812..., +62812..., 62-812-...).
+func (s *CustomerService) GetByPhone(ctx context.Context, phone string) (*models.Customer, error) {
+	return s.repo.GetByPhone(ctx, NormalizePhone(phone))
+}
+
+func (s *CustomerService) Create(ctx context.Context, c *models.Customer) error {
+	if err := validateCustomer(c); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, c)
+}
+
+func (s *CustomerService) Update(ctx context.Context, c *models.Customer) error {
+	if c.ID == 0 {
+		return models.Validationf("invalid customer ID")
+	}
+	if err := validateCustomer(c); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, c)
+}
+
+func (s *CustomerService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *CustomerService) GetTransactions(ctx context.Context, id, limit int) ([]models.Transaction, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	return s.transactionRepo.GetByCustomerID(ctx, id, limit)
+}
+
+func (s *CustomerService) GetSummary(ctx context.Context, id int) (*models.CustomerSummary, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	summary, err := s.repo.GetSummary(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	lifetime := summary.LifetimeValue.Amount
+	summary.LifetimeValue, summary.AverageBasket = money.New(lifetime), money.New(0)
+	if summary.TotalTransactions > 0 {
+		summary.AverageBasket = money.New(lifetime / money.Amount(summary.TotalTransactions))
+	}
+	return summary, nil
+}
+
+// GetPoints mengembalikan saldo poin loyalti pelanggan beserta riwayatnya.
+func (s *CustomerService) GetPoints(ctx context.Context, id int) (*models.LoyaltyBalance, error) {
+	return s.loyaltyRepo.GetBalance(ctx, id)
+}
+
+// GetReceivables mengembalikan posisi kasbon pelanggan: batas, sisa, dan piutang yang belum lunas.
+func (s *CustomerService) GetReceivables(ctx context.Context, id int) (*models.CustomerCredit, error) {
+	return s.receivableRepo.GetCredit(ctx, id)
+}
+
+// Repay mencatat cicilan/pelunasan kasbon pelanggan.
+func (s *CustomerService) Repay(ctx context.Context, p *models.ReceivablePayment) error {
+	if p.Amount <= 0 {
+		return models.Validationf("payment amount must be greater than zero")
+	}
+	p.Note = strings.TrimSpace(p.Note)
+	return s.receivableRepo.Repay(ctx, p)
+}
+
+func validateCustomer(c *models.Customer) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return models.Validationf("customer name is required")
+	}
+	c.Phone = NormalizePhone(c.Phone)
+	c.Email = strings.ToLower(strings.TrimSpace(c.Email))
+	if c.CreditLimit < 0 {
+		return models.Validationf("credit_limit must not be negative")
+	}
+	if c.Email != "" && !strings.Contains(c.Email, "@") {
+		return models.Validationf("invalid email")
+	}
+	return nil
+}
+
+// NormalizePhone menyeragamkan nomor HP ke format lokal berawalan 0 tanpa spasi/tanda hubung,
+// sehingga "+62 812-3456" dan "0812 3456" dianggap nomor yang sama.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := b.String()
+	if strings.HasPrefix(digits, "62") {
+		digits = "0" + digits[2:]
+	}
+	return digits
+}
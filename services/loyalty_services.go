@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type LoyaltyService struct {
+	repo repositories.LoyaltyStore
+}
+
+func NewLoyaltyService(repo repositories.LoyaltyStore) *LoyaltyService {
+	return &LoyaltyService{repo: repo}
+}
+
+func (s *LoyaltyService) GetRules(ctx context.Context) (*models.LoyaltyRules, error) {
+	return s.repo.GetRules(ctx)
+}
+
+func (s *LoyaltyService) UpdateRules(ctx context.Context, rules *models.LoyaltyRules) error {
+	if rules.SpendPerPoint < 0 || rules.PointValue < 0 {
+		return models.Validationf("spend_per_point and point_value must not be negative")
+	}
+	if rules.ExpiryDays < 0 {
+		return models.Validationf("expiry_days must not be negative")
+	}
+	seen := map[int]bool{}
+	for _, m := range rules.CategoryMultipliers {
+		if m.Multiplier < 0 {
+			return models.Validationf("multiplier for category %d must not be negative", m.CategoryID)
+		}
+		if seen[m.CategoryID] {
+			return models.Validationf("category %d has more than one multiplier", m.CategoryID)
+		}
+		seen[m.CategoryID] = true
+	}
+	if rules.CategoryMultipliers == nil {
+		rules.CategoryMultipliers = []models.CategoryMultiplier{}
+	}
+	return s.repo.UpdateRules(ctx, rules)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type ModifierService struct {
+	repo repositories.ModifierStore
+}
+
+func NewModifierService(repo repositories.ModifierStore) *ModifierService {
+	return &ModifierService{repo: repo}
+}
+
+func (s *ModifierService) GetAll(ctx context.Context, productID int) ([]models.ModifierGroup, error) {
+	return s.repo.GetAll(ctx, productID)
+}
+
+func (s *ModifierService) GetByID(ctx context.Context, id int) (*models.ModifierGroup, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ModifierService) Create(ctx context.Context, g *models.ModifierGroup) error {
+	if err := validateModifierGroup(g); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, g)
+}
+
+func (s *ModifierService) Update(ctx context.Context, g *models.ModifierGroup) error {
+	if g.ID == 0 {
+		return models.Validationf("invalid modifier group ID")
+	}
+	if err := validateModifierGroup(g); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, g)
+}
+
+func (s *ModifierService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func validateModifierGroup(g *models.ModifierGroup) error {
+	if g.Name == "" {
+		return models.Validationf("modifier group name is required")
+	}
+	if (g.ProductID == nil) == (g.CategoryID == nil) {
+		return models.Validationf("exactly one of product_id or category_id is required")
+	}
+	if g.MinSelect < 0 || g.MaxSelect < 0 {
+		return models.Validationf("min_select and max_select must not be negative")
+	}
+	if g.Required && g.MinSelect == 0 {
+		g.MinSelect = 1
+	}
+	if g.MaxSelect > 0 && g.MaxSelect < g.MinSelect {
+		return models.Validationf("max_select must be greater than or equal to min_select")
+	}
+	if len(g.Options) == 0 {
+		return models.Validationf("modifier group needs at least one option")
+	}
+	for _, m := range g.Options {
+		if m.Name == "" {
+			return models.Validationf("modifier name is required")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"log/slog"
+	"time"
+)
+
+type PriceHistoryService struct {
+	repo        repositories.PriceHistoryStore
+	productRepo repositories.ProductStore
+}
+
+func NewPriceHistoryService(repo repositories.PriceHistoryStore, productRepo repositories.ProductStore) *PriceHistoryService {
+	return &PriceHistoryService{repo: repo, productRepo: productRepo}
+}
+
+func (s *PriceHistoryService) GetHistory(ctx context.Context, productID int) ([]models.PriceChange, error) {
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetHistory(ctx, productID)
+}
+
+func (s *PriceHistoryService) GetScheduled(ctx context.Context, productID int) ([]models.ScheduledPrice, error) {
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetScheduled(ctx, productID)
+}
+
+func (s *PriceHistoryService) Schedule(ctx context.Context, sp *models.ScheduledPrice) error {
+	if sp.Price < 0 {
+		return models.Validationf("price must not be negative")
+	}
+	if sp.EffectiveAt.IsZero() {
+		return models.Validationf("effective_at is required")
+	}
+	if !sp.EffectiveAt.After(time.Now()) {
+		return models.Validationf("effective_at must be in the future")
+	}
+	if _, err := s.productRepo.GetByID(ctx, sp.ProductID); err != nil {
+		return err
+	}
+	return s.repo.Schedule(ctx, sp)
+}
+
+// Cancel membatalkan jadwal harga milik produk productID dan mengembalikan kondisi sebelumnya.
+func (s *PriceHistoryService) Cancel(ctx context.Context, productID, id int) (*models.ScheduledPrice, error) {
+	sp, err := s.repo.GetScheduledByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sp.ProductID != productID {
+		return nil, models.NotFound("scheduled price")
+	}
+	if err := s.repo.Cancel(ctx, id); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// RunScheduler menerapkan harga terjadwal yang jatuh tempo setiap interval sampai ctx
+// selesai. Dijalankan sekali saat start agar jadwal yang terlewat saat server mati ikut diterapkan.
+func (s *PriceHistoryService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		applied, err := s.repo.ApplyDue(ctx, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "price scheduler: failed to apply scheduled prices", "err", err)
+		}
+		for _, sp := range applied {
+			slog.InfoContext(ctx, "price scheduler: applied scheduled price",
+				"scheduled_price_id", sp.ID, "product_id", sp.ProductID, "price", sp.Price)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+type PriceListService struct {
+	repo repositories.PriceListStore
+}
+
+func NewPriceListService(repo repositories.PriceListStore) *PriceListService {
+	return &PriceListService{repo: repo}
+}
+
+func (s *PriceListService) GetAll(ctx context.Context) ([]models.PriceList, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *PriceListService) GetByID(ctx context.Context, id int) (*models.PriceList, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *PriceListService) Create(ctx context.Context, l *models.PriceList) error {
+	if err := validatePriceList(l); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, l); err != nil {
+		return err
+	}
+	if len(l.Items) > 0 {
+		return s.ReplaceItems(ctx, l.ID, l.Items)
+	}
+	return nil
+}
+
+func (s *PriceListService) Update(ctx context.Context, l *models.PriceList) error {
+	if l.ID == 0 {
+		return models.Validationf("invalid price list ID")
+	}
+	if err := validatePriceList(l); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, l)
+}
+
+func (s *PriceListService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *PriceListService) ReplaceItems(ctx context.Context, priceListID int, items []models.PriceListItem) error {
+	type tier struct {
+		productID, variantID int
+		minQuantity          float64
+	}
+	seen := map[tier]bool{}
+	for _, item := range items {
+		if item.ProductID == 0 {
+			return models.Validationf("product_id is required for every price list item")
+		}
+		if item.Price < 0 {
+			return models.Validationf("price for product %d must not be negative", item.ProductID)
+		}
+		if item.MinQuantity < 0 {
+			return models.Validationf("min_quantity for product %d must not be negative", item.ProductID)
+		}
+		key := tier{productID: item.ProductID, minQuantity: item.MinQuantity}
+		if item.VariantID != nil {
+			key.variantID = *item.VariantID
+		}
+		if seen[key] {
+			return models.Validationf("duplicate price tier for product %d at quantity %g", item.ProductID, item.MinQuantity)
+		}
+		seen[key] = true
+	}
+	return s.repo.ReplaceItems(ctx, priceListID, items)
+}
+
+func validatePriceList(l *models.PriceList) error {
+	l.Code = strings.ToLower(strings.TrimSpace(l.Code))
+	if l.Code == "" {
+		return models.Validationf("price list code is required")
+	}
+	if l.Name == "" {
+		l.Name = l.Code
+	}
+	return nil
+}
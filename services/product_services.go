@@ -1,41 +1,100 @@
 package services
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"kasir-api/models"
 	"kasir-api/repositories"
+	"kasir-api/validate"
 )
 
+// DefaultUnit dipakai jika produk dibuat tanpa satuan dasar.
+const DefaultUnit = "pcs"
+
 type ProductService struct {
-	repo *repositories.ProductRepository
+	repo         repositories.ProductStore
+	categoryRepo repositories.CategoryStore
+	variantRepo  repositories.VariantLookup
+	unitRepo     repositories.UnitLookup
 }
 
-func NewProductService(repo *repositories.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+// variantRepo dan unitRepo boleh nil untuk backend tanpa varian dan satuan alternatif.
+func NewProductService(repo repositories.ProductStore, categoryRepo repositories.CategoryStore, variantRepo repositories.VariantLookup, unitRepo repositories.UnitLookup) *ProductService {
+	return &ProductService{repo: repo, categoryRepo: categoryRepo, variantRepo: variantRepo, unitRepo: unitRepo}
 }
 
-func (s *ProductService) GetAll(name string) ([]models.Product, error) {
-	return s.repo.GetAll(name)
+func (s *ProductService) GetAll(ctx context.Context, name string) ([]models.Product, error) {
+	return s.repo.GetAll(ctx, name)
 }
 
-func (s *ProductService) Create(data *models.Product) error {
-	if data.Name == "" {
-		return fmt.Errorf("name is required")
+func (s *ProductService) Create(ctx context.Context, data *models.Product) error {
+	if data.Unit == "" {
+		data.Unit = DefaultUnit
+	}
+	if err := s.Validate(ctx, data); err != nil {
+		return err
 	}
-	return s.repo.Create(data)
+	return s.repo.Create(ctx, data)
 }
 
-func (s *ProductService) GetByID(id int) (*models.Product, error) {
-	return s.repo.GetByID(id)
+func (s *ProductService) GetByID(ctx context.Context, id int) (*models.Product, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// variantRepo/unitRepo nil pada backend tanpa varian dan satuan (mis. SQLite)
+	if len(product.OptionAxes) > 0 && s.variantRepo != nil {
+		variants, err := s.variantRepo.GetByProductID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		product.Variants = variants
+	}
+	if s.unitRepo == nil {
+		return product, nil
+	}
+	units, err := s.unitRepo.GetByProductID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(units) > 0 {
+		product.Units = units
+	}
+	return product, nil
 }
 
-func (s *ProductService) Update(product *models.Product) error {
+func (s *ProductService) Update(ctx context.Context, product *models.Product) error {
 	if product.ID == 0 {
-		return fmt.Errorf("invalid product ID")
+		return models.Validationf("invalid product ID")
+	}
+	if product.Unit == "" {
+		product.Unit = DefaultUnit
+	}
+	if err := s.Validate(ctx, product); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, product)
+}
+
+// Validate memeriksa semua field produk sekaligus tanpa menyimpan apa pun. Kategori dicek ke database agar
+// category_id yang tidak ada dilaporkan sebagai field yang salah, bukan error constraint.
+func (s *ProductService) Validate(ctx context.Context, p *models.Product) error {
+	var v validate.Validator
+	v.Required("name", p.Name)
+	v.NonNegative("price", float64(p.Price))
+	v.NonNegative("stock", p.Stock)
+	if p.CategoryID != nil {
+		_, err := s.categoryRepo.GetCategoryByID(ctx, *p.CategoryID)
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			v.Add("category_id", "category %d does not exist", *p.CategoryID)
+		case err != nil:
+			return err
+		}
 	}
-	return s.repo.Update(product)
+	return v.Err()
 }
 
-func (s *ProductService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *ProductService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
 }
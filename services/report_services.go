@@ -1,18 +1,36 @@
 package services
 
 import (
+	"context"
+	"fmt"
 	"kasir-api/models"
+	"kasir-api/money"
 	"kasir-api/repositories"
 )
 
 type ReportService struct {
-	repo *repositories.ReportRepository
+	repo           repositories.ReportStore
+	receivableRepo repositories.ReceivableAging
 }
 
-func NewReportService(repo *repositories.ReportRepository) *ReportService {
-	return &ReportService{repo: repo}
+// receivableRepo nil untuk backend tanpa kasbon.
+func NewReportService(repo repositories.ReportStore, receivableRepo repositories.ReceivableAging) *ReportService {
+	return &ReportService{repo: repo, receivableRepo: receivableRepo}
 }
 
-func (s *ReportService) GetTodayReport() (*models.TodayReport, error) {
-	return s.repo.GetTodayReport()
+func (s *ReportService) GetTodayReport(ctx context.Context, byVariant bool) (*models.TodayReport, error) {
+	return s.repo.GetTodayReport(ctx, byVariant)
+}
+
+// GetReceivablesAging mengembalikan umur piutang kasbon per pelanggan.
+func (s *ReportService) GetReceivablesAging(ctx context.Context) (*models.AgingReport, error) {
+	if s.receivableRepo == nil {
+		return nil, fmt.Errorf("receivables: %w", models.ErrUnsupported)
+	}
+	report, err := s.receivableRepo.GetAging(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Currency = money.Current().Currency
+	return report, nil
 }
@@ -0,0 +1,62 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories/memory"
+	"kasir-api/services"
+	"testing"
+)
+
+func TestTodayReport(t *testing.T) {
+	store, txs := newCheckoutService(t)
+	reports := services.NewReportService(store.Reports(), nil)
+	tea := addProduct(t, store, "Teh", 5000, 100)
+	coffee := addProduct(t, store, "Kopi", 8000, 100)
+	ctx := context.Background()
+
+	empty, err := reports.GetTodayReport(ctx, false)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if empty.TotalTransactions != 0 || empty.TotalRevenue.Amount != 0 {
+		t.Errorf("empty report = %+v", empty)
+	}
+
+	for _, line := range []models.CheckoutItem{item(tea, 2), item(coffee, 1), item(tea, 3)} {
+		if _, err := txs.Checkout(ctx, checkout(line), true); err != nil {
+			t.Fatalf("checkout: %v", err)
+		}
+	}
+	// transaksi yang direfund tidak dihitung
+	refunded, err := txs.Checkout(ctx, checkout(item(coffee, 10)), true)
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if _, err := txs.Refund(ctx, refunded.ID, nil); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+
+	report, err := reports.GetTodayReport(ctx, false)
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if report.TotalTransactions != 3 {
+		t.Errorf("transactions = %d, want 3", report.TotalTransactions)
+	}
+	if want := (money.Money{Amount: 33000, Currency: "IDR"}); report.TotalRevenue != want {
+		t.Errorf("revenue = %+v, want %+v", report.TotalRevenue, want)
+	}
+	if len(report.BestsellingProducts) != 1 || report.BestsellingProducts[0].Name != "Teh" || report.BestsellingProducts[0].QtySold != 5 {
+		t.Errorf("bestselling = %+v, want Teh x5", report.BestsellingProducts)
+	}
+}
+
+func TestReceivablesAgingUnsupportedWithoutRepository(t *testing.T) {
+	reports := services.NewReportService(memory.New().Reports(), nil)
+	if _, err := reports.GetReceivablesAging(context.Background()); !errors.Is(err, models.ErrUnsupported) {
+		t.Errorf("err = %v, want ErrUnsupported", err)
+	}
+}
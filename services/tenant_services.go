@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"regexp"
+	"strings"
+)
+
+// slugPattern: slug dipakai sebagai subdomain, jadi hanya huruf kecil, angka, dan tanda hubung.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type TenantService struct {
+	repo repositories.TenantStore
+}
+
+func NewTenantService(repo repositories.TenantStore) *TenantService {
+	return &TenantService{repo: repo}
+}
+
+func (s *TenantService) GetAll(ctx context.Context) ([]models.Tenant, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// Create membuat toko baru beserta user admin pertamanya.
+func (s *TenantService) Create(ctx context.Context, req *models.CreateTenantRequest) (*models.Tenant, error) {
+	t := models.Tenant{
+		Slug:   strings.ToLower(strings.TrimSpace(req.Slug)),
+		Name:   strings.TrimSpace(req.Name),
+		Active: true,
+	}
+	if !slugPattern.MatchString(t.Slug) {
+		return nil, models.Validationf("slug must be 1-63 lowercase letters, digits or hyphens")
+	}
+	if t.Name == "" {
+		t.Name = t.Slug
+	}
+
+	admin := models.User{Username: req.AdminUsername, Role: auth.RoleAdmin, Password: req.AdminPassword, Active: true}
+	if err := validateUser(&admin); err != nil {
+		return nil, err
+	}
+	if err := setPassword(&admin); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, &t, &admin); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
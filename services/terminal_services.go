@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"slices"
+	"strings"
+)
+
+type TerminalService struct {
+	repo     repositories.TerminalStore
+	userRepo repositories.UserStore
+}
+
+func NewTerminalService(repo repositories.TerminalStore, userRepo repositories.UserStore) *TerminalService {
+	return &TerminalService{repo: repo, userRepo: userRepo}
+}
+
+func (s *TerminalService) GetAll(ctx context.Context) ([]models.Terminal, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *TerminalService) GetByID(ctx context.Context, id int) (*models.Terminal, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create mendaftarkan terminal baru dan mengisi t.Key dengan kunci terminal. Kunci ini
+// hanya ditampilkan sekali dan dipasang di perangkat kasir.
+func (s *TerminalService) Create(ctx context.Context, t *models.Terminal) error {
+	if err := s.validate(ctx, t); err != nil {
+		return err
+	}
+	key, hash, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+	t.Key, t.KeyHash = key, hash
+	return s.repo.Create(ctx, t)
+}
+
+func (s *TerminalService) Update(ctx context.Context, t *models.Terminal) error {
+	if t.ID == 0 {
+		return models.Validationf("invalid terminal ID")
+	}
+	if err := s.validate(ctx, t); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, t)
+}
+
+func (s *TerminalService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *TerminalService) validate(ctx context.Context, t *models.Terminal) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return models.Validationf("terminal name is required")
+	}
+	if t.UserIDs == nil {
+		t.UserIDs = []int{}
+	}
+	slices.Sort(t.UserIDs)
+	t.UserIDs = slices.Compact(t.UserIDs)
+	for _, id := range t.UserIDs {
+		if _, err := s.userRepo.GetByID(ctx, id); err != nil {
+			return fmt.Errorf("user %d: %w", id, err)
+		}
+	}
+	return nil
+}
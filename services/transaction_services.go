@@ -1,18 +1,268 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"kasir-api/auth"
+	"kasir-api/barcode"
 	"kasir-api/models"
+	"kasir-api/money"
 	"kasir-api/repositories"
+	"kasir-api/validate"
+	"slices"
+	"strings"
 )
 
 type TransactionService struct {
-	repo *repositories.TransactionRepository
+	repo          repositories.TransactionStore
+	productRepo   repositories.ProductStore
+	unitRepo      repositories.UnitLookup
+	customerRepo  repositories.CustomerLookup
+	priceListRepo repositories.PriceListLookup
+	// maxDiscountPercent adalah diskon manual terbesar yang boleh diberikan kasir tanpa persetujuan supervisor.
+	maxDiscountPercent float64
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+// unitRepo, customerRepo, dan priceListRepo boleh nil; checkout yang membutuhkannya
+// ditolak dengan models.ErrUnsupported.
+func NewTransactionService(repo repositories.TransactionStore, productRepo repositories.ProductStore, unitRepo repositories.UnitLookup, customerRepo repositories.CustomerLookup, priceListRepo repositories.PriceListLookup, maxDiscountPercent float64) *TransactionService {
+	return &TransactionService{repo: repo, productRepo: productRepo, unitRepo: unitRepo, customerRepo: customerRepo, priceListRepo: priceListRepo, maxDiscountPercent: maxDiscountPercent}
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem, useLock bool) (*models.Transaction, error) {
-	return s.repo.CreateTransaction(items)
+// RequiredPermissions mengembalikan izin tambahan yang dibutuhkan checkout ini di luar
+// transactions:create: ubah harga manual dan diskon di atas batas kasir.
+func (s *TransactionService) RequiredPermissions(req *models.CheckoutRequest) []auth.Permission {
+	var perms []auth.Permission
+	for _, item := range req.Items {
+		if item.PriceOverride != nil {
+			perms = append(perms, auth.PricesOverride)
+			break
+		}
+	}
+	if req.DiscountPercent > s.maxDiscountPercent {
+		perms = append(perms, auth.DiscountsLarge)
+	}
+	return perms
+}
+
+func (s *TransactionService) Checkout(ctx context.Context, req *models.CheckoutRequest, useLock bool) (*models.Transaction, error) {
+	if err := validateCheckout(req); err != nil {
+		return nil, err
+	}
+	for i := range req.Items {
+		if req.Items[i].Barcode == "" {
+			continue
+		}
+		if err := s.resolveBarcode(ctx, &req.Items[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.resolveCustomer(ctx, req); err != nil {
+		return nil, err
+	}
+	if err := validatePaymentMethod(req); err != nil {
+		return nil, err
+	}
+	for _, item := range req.Items {
+		if item.PriceOverride != nil && item.EmbeddedPrice != nil {
+			return nil, models.Validationf("price_override cannot be used with a price-embedded scale barcode")
+		}
+	}
+	return s.repo.CreateTransaction(ctx, req)
+}
+
+// ValidateCheckout memeriksa isi keranjang seperti langkah pertama Checkout, tanpa
+// menyentuh stok; handler memakainya untuk melengkapi error input yang tidak bisa dibaca.
+func (s *TransactionService) ValidateCheckout(req *models.CheckoutRequest) error {
+	return validateCheckout(req)
+}
+
+// validateCheckout memeriksa isi keranjang sebelum barcode dan pelanggan di-resolve.
+// Item dengan barcode boleh tanpa product_id dan kuantitas (diisi dari barcode). Baris
+// kembar (produk, varian, satuan, dan modifier sama) ditolak: kuantitasnya harus digabung.
+func validateCheckout(req *models.CheckoutRequest) error {
+	var v validate.Validator
+	v.Check(len(req.Items) > 0, "items", "must not be empty")
+	seen := make(map[string]int)
+	for i, item := range req.Items {
+		field := func(name string) string { return fmt.Sprintf("items[%d].%s", i, name) }
+		if item.Barcode != "" {
+			v.NonNegative(field("quantity"), item.Quantity)
+		} else {
+			v.Check(item.ProductID > 0, field("product_id"), "is required unless barcode is given")
+			v.Positive(field("quantity"), item.Quantity)
+			key := checkoutLineKey(item)
+			if j, dup := seen[key]; dup && item.ProductID > 0 {
+				v.Add(field("product_id"), "duplicates items[%d]; combine the quantities", j)
+			} else {
+				seen[key] = i
+			}
+		}
+		if item.PriceOverride != nil {
+			v.NonNegative(field("price_override"), float64(*item.PriceOverride))
+		}
+	}
+	v.NonNegative("redeem_points", float64(req.RedeemPoints))
+	v.Between("discount_percent", req.DiscountPercent, 0, 100)
+	return v.Err()
+}
+
+func checkoutLineKey(item models.CheckoutItem) string {
+	modifiers := slices.Clone(item.ModifierIDs)
+	slices.Sort(modifiers)
+	variant := 0
+	if item.VariantID != nil {
+		variant = *item.VariantID
+	}
+	return fmt.Sprintf("%d/%d/%s/%v", item.ProductID, variant, item.Unit, modifiers)
+}
+
+func validatePaymentMethod(req *models.CheckoutRequest) error {
+	req.PaymentMethod = strings.ToLower(strings.TrimSpace(req.PaymentMethod))
+	switch req.PaymentMethod {
+	case "":
+		req.PaymentMethod = models.PaymentCash
+	case models.PaymentCash, models.PaymentCard, models.PaymentTransfer, models.PaymentQRIS:
+	case models.PaymentPayLater:
+		if req.CustomerID == nil {
+			return models.Validationf("pay_later requires a customer")
+		}
+	default:
+		return models.Validationf("unknown payment method %q", req.PaymentMethod)
+	}
+	return nil
+}
+
+// resolveCustomer memastikan pelanggan pada checkout ada, mengisi customer_id dari
+// nomor HP jika kasir hanya memasukkan nomor HP, lalu menentukan daftar harga:
+// daftar harga eksplisit di request didahulukan, jika tidak ada dipakai milik pelanggan.
+func (s *TransactionService) resolveCustomer(ctx context.Context, req *models.CheckoutRequest) error {
+	// backend tanpa pelanggan dan daftar harga (mis. SQLite) tidak memasang repo-nya
+	if s.customerRepo == nil || s.priceListRepo == nil {
+		if req.CustomerPhone != "" || req.PriceListCode != "" {
+			return fmt.Errorf("customers and price lists: %w", models.ErrUnsupported)
+		}
+		return nil
+	}
+
+	var customer *models.Customer
+	var err error
+	switch {
+	case req.CustomerID != nil:
+		customer, err = s.customerRepo.GetByID(ctx, *req.CustomerID)
+	case req.CustomerPhone != "":
+		customer, err = s.customerRepo.GetByPhone(ctx, NormalizePhone(req.CustomerPhone))
+	}
+	if err != nil {
+		return err
+	}
+	if customer != nil {
+		req.CustomerID = &customer.ID
+	}
+
+	switch {
+	case req.PriceListCode != "":
+		list, err := s.priceListRepo.GetByCode(ctx, strings.ToLower(req.PriceListCode))
+		if err != nil {
+			return err
+		}
+		req.PriceListID = &list.ID
+	case req.PriceListID != nil:
+		if _, err := s.priceListRepo.GetByID(ctx, *req.PriceListID); err != nil {
+			return err
+		}
+	case customer != nil:
+		req.PriceListID = customer.PriceListID
+	}
+	return nil
+}
+
+// resolveBarcode mengisi product_id, satuan, dan kuantitas item dari barcode yang di-scan.
+// Barcode timbangan (berawalan 2) membawa berat atau harga; barcode lain dicocokkan ke
+// barcode satuan produk (mis. barcode karton). Prefix 2 juga dipakai untuk kode internal
+// toko, jadi barcode berawalan 2 yang PLU-nya tidak dikenal tetap dicari sebagai barcode
+// satuan.
+func (s *TransactionService) resolveBarcode(ctx context.Context, item *models.CheckoutItem) error {
+	scale, scaleErr := barcode.ParseScale(item.Barcode)
+	if scaleErr == nil {
+		product, err := s.productRepo.GetByPLU(ctx, scale.PLU)
+		if err == nil {
+			return resolveScale(item, product, scale)
+		}
+		if !errors.Is(err, models.ErrNotFound) {
+			return err
+		}
+		scaleErr = fmt.Errorf("scale barcode %s: %w", item.Barcode, err)
+	} else if !errors.Is(scaleErr, barcode.ErrNotScaleBarcode) {
+		scaleErr = models.Validationf("barcode %s: %v", item.Barcode, scaleErr)
+	}
+
+	if s.unitRepo == nil {
+		if errors.Is(scaleErr, barcode.ErrNotScaleBarcode) {
+			return fmt.Errorf("unit barcode %s: %w", item.Barcode, models.ErrUnsupported)
+		}
+		return scaleErr
+	}
+	unit, err := s.unitRepo.GetByBarcode(ctx, item.Barcode)
+	if err != nil {
+		// barcode berawalan 2 yang juga bukan barcode satuan: laporkan kenapa ia bukan
+		// barcode timbangan yang sah
+		if errors.Is(err, models.ErrNotFound) && !errors.Is(scaleErr, barcode.ErrNotScaleBarcode) {
+			return scaleErr
+		}
+		return err
+	}
+	if !unit.Sellable {
+		return models.Validationf("barcode %s belongs to a unit that is not sold", item.Barcode)
+	}
+	item.ProductID = unit.ProductID
+	item.Unit = unit.Name
+	if item.Quantity == 0 {
+		item.Quantity = 1
+	}
+	return nil
+}
+
+// resolveScale mengisi item dari barcode timbangan yang PLU-nya cocok dengan product.
+func resolveScale(item *models.CheckoutItem, product *models.Product, scale barcode.ScaleBarcode) error {
+	if !product.DecimalQty {
+		return models.Validationf("product %d is not sold by weight", product.ID)
+	}
+	item.ProductID = product.ID
+	item.Unit = product.Unit
+
+	if scale.IsWeight() {
+		switch product.Unit {
+		case "kg":
+			item.Quantity = models.RoundQuantity(float64(scale.Grams) / 1000)
+		case "g":
+			item.Quantity = float64(scale.Grams)
+		default:
+			return models.Validationf("product %d unit %q cannot take a weight barcode", product.ID, product.Unit)
+		}
+		return nil
+	}
+
+	// barcode berisi harga: kuantitas diturunkan dari harga per satuan dasar
+	if product.Price <= 0 {
+		return models.Validationf("product %d has no price to derive quantity from", product.ID)
+	}
+	price := money.Amount(scale.Price)
+	item.EmbeddedPrice = &price
+	item.Quantity = models.RoundQuantity(float64(scale.Price) / float64(product.Price))
+	return nil
+}
+
+func (s *TransactionService) GetByID(ctx context.Context, id int) (*models.Transaction, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Refund membatalkan transaksi dan mengembalikan transaksi dengan status terbarunya.
+// refundedBy adalah user yang menyetujui (kasir dengan izin void atau supervisor).
+func (s *TransactionService) Refund(ctx context.Context, id int, refundedBy *int) (*models.Transaction, error) {
+	if err := s.repo.Refund(ctx, id, refundedBy); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
 }
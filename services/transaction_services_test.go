@@ -0,0 +1,297 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/money"
+	"kasir-api/repositories/memory"
+	"kasir-api/services"
+	"sync"
+	"testing"
+)
+
+// newCheckoutService memasang service transaksi di atas memory.Store tanpa satuan,
+// pelanggan, maupun daftar harga, seperti backend yang tidak menyimpannya.
+func newCheckoutService(t *testing.T) (*memory.Store, *services.TransactionService) {
+	t.Helper()
+	store := memory.New()
+	return store, services.NewTransactionService(store.Transactions(), store.Products(), nil, nil, nil, 10)
+}
+
+func addProduct(t *testing.T, store *memory.Store, name string, price int64, stock float64) int {
+	t.Helper()
+	p := models.Product{Name: name, Price: money.Amount(price), Stock: stock, Unit: "pcs"}
+	if err := store.Products().Create(context.Background(), &p); err != nil {
+		t.Fatalf("create product %s: %v", name, err)
+	}
+	return p.ID
+}
+
+func stockOf(t *testing.T, store *memory.Store, id int) float64 {
+	t.Helper()
+	p, err := store.Products().GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("get product %d: %v", id, err)
+	}
+	return p.Stock
+}
+
+func checkout(items ...models.CheckoutItem) *models.CheckoutRequest {
+	return &models.CheckoutRequest{Items: items}
+}
+
+func item(productID int, qty float64) models.CheckoutItem {
+	return models.CheckoutItem{ProductID: productID, Quantity: qty}
+}
+
+func TestCheckoutDecrementsStock(t *testing.T) {
+	store, svc := newCheckoutService(t)
+	tea := addProduct(t, store, "Teh", 5000, 10)
+	coffee := addProduct(t, store, "Kopi", 8000, 4)
+
+	tx, err := svc.Checkout(context.Background(), checkout(item(tea, 3), item(coffee, 1)), true)
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if tx.TotalAmount != money.New(23000) {
+		t.Errorf("total = %+v, want 23000", tx.TotalAmount)
+	}
+	if tx.Status != models.TransactionCompleted || tx.PaymentMethod != models.PaymentCash {
+		t.Errorf("status/payment = %s/%s, want completed/cash", tx.Status, tx.PaymentMethod)
+	}
+	if got := stockOf(t, store, tea); got != 7 {
+		t.Errorf("tea stock = %g, want 7", got)
+	}
+	if got := stockOf(t, store, coffee); got != 3 {
+		t.Errorf("coffee stock = %g, want 3", got)
+	}
+}
+
+// Pajak dihitung atas total setelah diskon dengan mode pembulatan konfigurasi, lalu
+// total tunai dibulatkan ke Rp100 dan selisihnya dicatat.
+func TestCheckoutTaxAndCashRounding(t *testing.T) {
+	prev := money.Current()
+	t.Cleanup(func() { money.Configure(prev) })
+	money.Configure(money.Config{Currency: "IDR", CashRounding: 100, Mode: money.HalfUp, TaxRate: 1100})
+
+	store, svc := newCheckoutService(t)
+	snack := addProduct(t, store, "Keripik", 4550, 10)
+
+	tx, err := svc.Checkout(context.Background(), checkout(item(snack, 1)), true)
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	// 4550 x 11% = 500.5 -> 501; 5051 dibulatkan ke 5100
+	if tx.Tax != 501 || tx.TotalAmount != (money.Money{Amount: 5100, Currency: "IDR"}) || tx.RoundingAdjustment != 49 {
+		t.Errorf("tax = %d, total = %+v, rounding = %d; want 501, 5100 IDR, 49", tx.Tax, tx.TotalAmount, tx.RoundingAdjustment)
+	}
+	stored, err := store.Transactions().GetByID(context.Background(), tx.ID)
+	if err != nil || stored.Tax != tx.Tax || stored.TotalAmount != tx.TotalAmount {
+		t.Errorf("stored = %+v, %v; want tax and total as returned", stored, err)
+	}
+}
+
+func TestCheckoutIsAtomic(t *testing.T) {
+	store, svc := newCheckoutService(t)
+	tea := addProduct(t, store, "Teh", 5000, 10)
+	coffee := addProduct(t, store, "Kopi", 8000, 1)
+
+	_, err := svc.Checkout(context.Background(), checkout(item(tea, 2), item(coffee, 5)), true)
+	var stock *models.InsufficientStockError
+	if !errors.As(err, &stock) {
+		t.Fatalf("err = %v, want InsufficientStockError", err)
+	}
+	if stock.ProductID != coffee || stock.Available != 1 || stock.Requested != 5 {
+		t.Errorf("stock error = %+v", stock)
+	}
+	if got := stockOf(t, store, tea); got != 10 {
+		t.Errorf("tea stock = %g after failed checkout, want 10 (unchanged)", got)
+	}
+	if _, err := svc.GetByID(context.Background(), 1); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("transaction stored after failed checkout: err = %v", err)
+	}
+
+	// produk yang tidak ada juga membatalkan seluruh keranjang
+	_, err = svc.Checkout(context.Background(), checkout(item(tea, 1), item(999, 1)), true)
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if got := stockOf(t, store, tea); got != 10 {
+		t.Errorf("tea stock = %g after failed checkout, want 10 (unchanged)", got)
+	}
+}
+
+func TestCheckoutConcurrentDoesNotOversell(t *testing.T) {
+	store, svc := newCheckoutService(t)
+	tea := addProduct(t, store, "Teh", 5000, 10)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+	)
+	for range 25 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.Checkout(context.Background(), checkout(item(tea, 1)), true)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				success++
+			} else if !errors.Is(err, models.ErrConflict) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if success != 10 {
+		t.Errorf("%d checkouts succeeded, want 10", success)
+	}
+	if got := stockOf(t, store, tea); got != 0 {
+		t.Errorf("stock = %g, want 0", got)
+	}
+}
+
+func TestCheckoutValidation(t *testing.T) {
+	_, svc := newCheckoutService(t)
+
+	req := checkout(item(0, 1), item(2, -2), item(1, 1), item(1, 1))
+	req.DiscountPercent = 120
+	_, err := svc.Checkout(context.Background(), req, true)
+
+	var fields models.ValidationErrors
+	if !errors.As(err, &fields) {
+		t.Fatalf("err = %v, want ValidationErrors", err)
+	}
+	want := map[string]bool{
+		"items[0].product_id": true,
+		"items[1].quantity":   true,
+		"items[3].product_id": true, // duplikat items[2]
+		"discount_percent":    true,
+	}
+	for _, f := range fields {
+		if !want[f.Field] {
+			t.Errorf("unexpected field error %s: %s", f.Field, f.Message)
+		}
+		delete(want, f.Field)
+	}
+	for f := range want {
+		t.Errorf("missing field error for %s", f)
+	}
+}
+
+func TestCheckoutRejectsUnsupportedFeatures(t *testing.T) {
+	store, svc := newCheckoutService(t)
+	tea := addProduct(t, store, "Teh", 5000, 10)
+
+	tests := []struct {
+		name string
+		req  *models.CheckoutRequest
+	}{
+		{"customer phone", &models.CheckoutRequest{Items: []models.CheckoutItem{item(tea, 1)}, CustomerPhone: "0812"}},
+		{"price list", &models.CheckoutRequest{Items: []models.CheckoutItem{item(tea, 1)}, PriceListCode: "grosir"}},
+		{"unit barcode", checkout(models.CheckoutItem{Barcode: "8991234567890"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Checkout(context.Background(), tt.req, true); !errors.Is(err, models.ErrUnsupported) {
+				t.Errorf("err = %v, want ErrUnsupported", err)
+			}
+		})
+	}
+	if got := stockOf(t, store, tea); got != 10 {
+		t.Errorf("stock = %g, want 10", got)
+	}
+}
+
+// unitBarcodes adalah UnitLookup yang hanya mengenal barcode satuan.
+type unitBarcodes map[string]models.ProductUnit
+
+func (u unitBarcodes) GetByProductID(ctx context.Context, productID int) ([]models.ProductUnit, error) {
+	return nil, nil
+}
+
+func (u unitBarcodes) GetByBarcode(ctx context.Context, barcode string) (*models.ProductUnit, error) {
+	unit, ok := u[barcode]
+	if !ok {
+		return nil, models.NotFound("unit")
+	}
+	return &unit, nil
+}
+
+// Barcode internal toko juga berawalan 2; yang PLU-nya tidak dikenal harus dicari sebagai
+// barcode satuan, bukan ditolak sebagai barcode timbangan.
+func TestCheckoutScaleBarcodeFallsBackToUnitBarcode(t *testing.T) {
+	store := memory.New()
+	apple := models.Product{Name: "Apel", Price: 20000, Stock: 10, Unit: "kg", DecimalQty: true, PLU: "12345"}
+	if err := store.Products().Create(context.Background(), &apple); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	bread := addProduct(t, store, "Roti", 8000, 10)
+	units := unitBarcodes{"2000001000076": {ProductID: bread, Name: "pcs", Factor: 1, Sellable: true}}
+	svc := services.NewTransactionService(store.Transactions(), store.Products(), units, nil, nil, 10)
+
+	tests := []struct {
+		name    string
+		barcode string
+		product int
+		qty     float64
+		wantErr error
+	}{
+		{"scale barcode", "2012345012509", apple.ID, 1.25, nil},
+		{"in-store unit barcode", "2000001000076", bread, 1, nil},
+		{"unknown plu", "2054321001008", 0, 0, models.ErrNotFound},
+		{"invalid scale barcode", "2000002000006", 0, 0, models.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := svc.Checkout(context.Background(), checkout(models.CheckoutItem{Barcode: tt.barcode}), true)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkout: %v", err)
+			}
+			if d := tx.Details[0]; d.ProductID != tt.product || d.Quantity != tt.qty {
+				t.Errorf("detail = product %d x%g, want product %d x%g", d.ProductID, d.Quantity, tt.product, tt.qty)
+			}
+		})
+	}
+}
+
+func TestRefundRestoresStock(t *testing.T) {
+	store, svc := newCheckoutService(t)
+	tea := addProduct(t, store, "Teh", 5000, 10)
+
+	tx, err := svc.Checkout(context.Background(), checkout(item(tea, 4)), true)
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	refundedBy := 7
+	refunded, err := svc.Refund(context.Background(), tx.ID, &refundedBy)
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if refunded.Status != models.TransactionRefunded || refunded.RefundedBy == nil || *refunded.RefundedBy != 7 {
+		t.Errorf("refunded transaction = %+v", refunded)
+	}
+	if got := stockOf(t, store, tea); got != 10 {
+		t.Errorf("stock = %g after refund, want 10", got)
+	}
+
+	if _, err := svc.Refund(context.Background(), tx.ID, nil); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("second refund err = %v, want ErrConflict", err)
+	}
+	if got := stockOf(t, store, tea); got != 10 {
+		t.Errorf("stock = %g after second refund, want 10", got)
+	}
+	if _, err := svc.Refund(context.Background(), 999, nil); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("refund of unknown transaction err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+type UnitService struct {
+	repo        repositories.UnitStore
+	productRepo repositories.ProductStore
+}
+
+func NewUnitService(repo repositories.UnitStore, productRepo repositories.ProductStore) *UnitService {
+	return &UnitService{repo: repo, productRepo: productRepo}
+}
+
+func (s *UnitService) GetByProductID(ctx context.Context, productID int) ([]models.ProductUnit, error) {
+	return s.repo.GetByProductID(ctx, productID)
+}
+
+func (s *UnitService) GetByID(ctx context.Context, id int) (*models.ProductUnit, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *UnitService) Create(ctx context.Context, u *models.ProductUnit) error {
+	if err := s.validate(ctx, u); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, u)
+}
+
+func (s *UnitService) Update(ctx context.Context, u *models.ProductUnit) error {
+	if u.ID == 0 {
+		return models.Validationf("invalid unit ID")
+	}
+	if err := s.validate(ctx, u); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, u)
+}
+
+func (s *UnitService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// ReceiveStock menambah stok dari penerimaan barang dalam satuan beli produk.
+func (s *UnitService) ReceiveStock(ctx context.Context, receipt *models.StockReceipt) error {
+	if receipt.Quantity <= 0 {
+		return models.Validationf("quantity must be greater than zero")
+	}
+	product, err := s.productRepo.GetByID(ctx, receipt.ProductID)
+	if err != nil {
+		return err
+	}
+
+	factor := 1.0
+	if receipt.Unit == "" {
+		receipt.Unit = product.Unit
+	}
+	if receipt.Unit != product.Unit {
+		units, err := s.repo.GetByProductID(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, u := range units {
+			if u.Name == receipt.Unit && u.Purchasable {
+				factor, found = u.Factor, true
+				break
+			}
+		}
+		if !found {
+			return models.Validationf("unit %q is not a purchase unit of product %d", receipt.Unit, product.ID)
+		}
+	}
+
+	receipt.BaseQuantity = models.RoundQuantity(receipt.Quantity * factor)
+	if !product.DecimalQty && receipt.BaseQuantity != float64(int(receipt.BaseQuantity)) {
+		return models.Validationf("product %d only holds whole %s", product.ID, product.Unit)
+	}
+	return s.repo.ReceiveStock(ctx, receipt)
+}
+
+func (s *UnitService) validate(ctx context.Context, u *models.ProductUnit) error {
+	if u.Name == "" {
+		return models.Validationf("unit name is required")
+	}
+	if u.Factor <= 0 {
+		return models.Validationf("factor must be greater than zero")
+	}
+	product, err := s.productRepo.GetByID(ctx, u.ProductID)
+	if err != nil {
+		return err
+	}
+	if u.Name == product.Unit {
+		return models.Validationf("unit %q is already the base unit of the product", u.Name)
+	}
+	if !u.Sellable && !u.Purchasable {
+		return models.Validationf("unit must be sellable, purchasable, or both")
+	}
+	return nil
+}
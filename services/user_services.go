@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+// minPasswordLength adalah panjang minimal password user; PIN berupa 4–6 digit angka.
+const (
+	minPasswordLength = 8
+	minPINLength      = 4
+	maxPINLength      = 6
+)
+
+type UserService struct {
+	repo repositories.UserStore
+}
+
+func NewUserService(repo repositories.UserStore) *UserService {
+	return &UserService{repo: repo}
+}
+
+func (s *UserService) GetAll(ctx context.Context) ([]models.User, error) {
+	return s.repo.GetAll(ctx)
+}
+
+func (s *UserService) GetByID(ctx context.Context, id int) (*models.User, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *UserService) Create(ctx context.Context, u *models.User) error {
+	if err := validateUser(u); err != nil {
+		return err
+	}
+	if err := setPassword(u); err != nil {
+		return err
+	}
+	if err := setPIN(u); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, u)
+}
+
+// Update menyimpan perubahan user; password dan PIN hanya diganti jika diisi.
+func (s *UserService) Update(ctx context.Context, u *models.User) error {
+	if u.ID == 0 {
+		return models.Validationf("invalid user ID")
+	}
+	if err := validateUser(u); err != nil {
+		return err
+	}
+	if u.Password != "" {
+		if err := setPassword(u); err != nil {
+			return err
+		}
+	}
+	if err := setPIN(u); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, u)
+}
+
+func (s *UserService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// EnsureAdmin membuat user pertama dari konfigurasi jika tabel users masih kosong,
+// supaya server yang baru dipasang tetap bisa dipakai login.
+func (s *UserService) EnsureAdmin(ctx context.Context, username, password string) (bool, error) {
+	n, err := s.repo.Count(ctx)
+	if err != nil || n > 0 {
+		return false, err
+	}
+	if username == "" || password == "" {
+		return false, fmt.Errorf("no users exist; set ADMIN_USERNAME and ADMIN_PASSWORD to create the first one")
+	}
+	u := models.User{Username: username, Name: username, Role: auth.RoleAdmin, Password: password, Active: true}
+	return true, s.Create(ctx, &u)
+}
+
+func validateUser(u *models.User) error {
+	u.Username = strings.ToLower(strings.TrimSpace(u.Username))
+	if u.Username == "" {
+		return models.Validationf("username is required")
+	}
+	if strings.ContainsAny(u.Username, " \t") {
+		return models.Validationf("username must not contain spaces")
+	}
+	u.Name = strings.TrimSpace(u.Name)
+	if u.Name == "" {
+		u.Name = u.Username
+	}
+	u.Role = strings.ToLower(strings.TrimSpace(u.Role))
+	if u.Role == "" {
+		u.Role = auth.RoleCashier
+	}
+	if !auth.ValidRole(u.Role) {
+		return models.Validationf("unknown role %q", u.Role)
+	}
+	return nil
+}
+
+func setPassword(u *models.User) error {
+	if len(u.Password) < minPasswordLength {
+		return models.Validationf("password must be at least %d characters", minPasswordLength)
+	}
+	hash, err := auth.HashPassword(u.Password)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash, u.Password = hash, ""
+	return nil
+}
+
+// setPIN meng-hash PIN jika diisi; PIN kosong berarti PIN lama dipertahankan.
+func setPIN(u *models.User) error {
+	if u.PIN == "" {
+		return nil
+	}
+	if len(u.PIN) < minPINLength || len(u.PIN) > maxPINLength || strings.Trim(u.PIN, "0123456789") != "" {
+		return models.Validationf("pin must be %d-%d digits", minPINLength, maxPINLength)
+	}
+	hash, err := auth.HashPassword(u.PIN)
+	if err != nil {
+		return err
+	}
+	u.PINHash, u.PIN = hash, ""
+	return nil
+}
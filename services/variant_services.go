@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+type VariantService struct {
+	repo        repositories.VariantStore
+	productRepo repositories.ProductStore
+}
+
+func NewVariantService(repo repositories.VariantStore, productRepo repositories.ProductStore) *VariantService {
+	return &VariantService{repo: repo, productRepo: productRepo}
+}
+
+func (s *VariantService) GetByProductID(ctx context.Context, productID int) ([]models.ProductVariant, error) {
+	return s.repo.GetByProductID(ctx, productID)
+}
+
+func (s *VariantService) GetByID(ctx context.Context, id int) (*models.ProductVariant, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *VariantService) Create(ctx context.Context, v *models.ProductVariant) error {
+	product, err := s.productRepo.GetByID(ctx, v.ProductID)
+	if err != nil {
+		return err
+	}
+	if err := validateVariant(product, v); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, v)
+}
+
+func (s *VariantService) Update(ctx context.Context, v *models.ProductVariant) error {
+	if v.ID == 0 {
+		return models.Validationf("invalid variant ID")
+	}
+	product, err := s.productRepo.GetByID(ctx, v.ProductID)
+	if err != nil {
+		return err
+	}
+	if err := validateVariant(product, v); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, v)
+}
+
+func (s *VariantService) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// validateVariant memastikan opsi varian sesuai dengan option_axes milik produk induk
+// dan mengisi nama varian dari nilai opsi jika kosong (mis. "L / Merah").
+func validateVariant(product *models.Product, v *models.ProductVariant) error {
+	if v.SKU == "" {
+		return models.Validationf("sku is required")
+	}
+	if v.Stock < 0 {
+		return models.Validationf("stock must not be negative")
+	}
+	if len(product.OptionAxes) == 0 {
+		return models.Validationf("product %d has no option axes", product.ID)
+	}
+	if len(v.Options) != len(product.OptionAxes) {
+		return models.Validationf("options must have exactly these keys: %s", strings.Join(product.OptionAxes, ", "))
+	}
+
+	values := make([]string, 0, len(product.OptionAxes))
+	for _, axis := range product.OptionAxes {
+		value, ok := v.Options[axis]
+		if !ok || value == "" {
+			return models.Validationf("option %q is required", axis)
+		}
+		values = append(values, value)
+	}
+	if v.Name == "" {
+		v.Name = strings.Join(values, " / ")
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package tenant menentukan toko (tenant) pemilik setiap request dan meneruskannya
+// ke handler yang dibangun khusus untuk toko tersebut.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Header yang dipakai untuk menentukan tenant.
+const (
+	Header            = "X-Tenant" // slug toko, untuk klien yang tidak memakai subdomain
+	apiKeyHeader      = "X-API-Key"
+	terminalKeyHeader = "X-Terminal-Key"
+)
+
+var (
+	ErrTenantRequired = errors.New("tenant required: use the store subdomain or the X-Tenant header")
+	ErrUnknownTenant  = errors.New("unknown tenant")
+	ErrInactiveTenant = errors.New("tenant is inactive")
+)
+
+// Lookup mencari tenant; diimplementasikan oleh repositories.TenantRepository.
+type Lookup interface {
+	GetByID(ctx context.Context, id int) (*models.Tenant, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	GetByAPIKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error)
+	GetByTerminalKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error)
+}
+
+// Resolver menentukan tenant request, berurutan: klaim tid di access token, pemilik
+// X-API-Key, pemilik X-Terminal-Key, header X-Tenant, lalu subdomain dari baseDomain.
+// Kredensial didahulukan supaya token satu toko tidak bisa dipakai di subdomain toko lain.
+type Resolver struct {
+	lookup     Lookup
+	issuer     *auth.Issuer
+	baseDomain string
+}
+
+func NewResolver(lookup Lookup, issuer *auth.Issuer, baseDomain string) *Resolver {
+	return &Resolver{lookup: lookup, issuer: issuer, baseDomain: strings.ToLower(strings.TrimPrefix(baseDomain, "."))}
+}
+
+func (res *Resolver) Resolve(r *http.Request) (*models.Tenant, error) {
+	t, err := res.find(r)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Active {
+		return nil, ErrInactiveTenant
+	}
+	return t, nil
+}
+
+func (res *Resolver) find(r *http.Request) (*models.Tenant, error) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		// token yang tidak valid diabaikan di sini; middleware auth milik tenant yang menolaknya
+		if claims, err := res.issuer.Parse(token); err == nil {
+			return res.found(res.lookup.GetByID(r.Context(), claims.TenantID))
+		}
+	}
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return res.found(res.lookup.GetByAPIKeyHash(r.Context(), auth.HashToken(key)))
+	}
+	if key := r.Header.Get(terminalKeyHeader); key != "" {
+		return res.found(res.lookup.GetByTerminalKeyHash(r.Context(), auth.HashToken(key)))
+	}
+	if slug := strings.ToLower(strings.TrimSpace(r.Header.Get(Header))); slug != "" {
+		return res.found(res.lookup.GetBySlug(r.Context(), slug))
+	}
+	if slug := res.subdomain(r.Host); slug != "" {
+		return res.found(res.lookup.GetBySlug(r.Context(), slug))
+	}
+	return nil, ErrTenantRequired
+}
+
+// found menyamakan semua kegagalan lookup menjadi ErrUnknownTenant agar klien tidak
+// bisa membedakan kunci yang salah dari toko yang tidak ada.
+func (res *Resolver) found(t *models.Tenant, err error) (*models.Tenant, error) {
+	if err != nil {
+		return nil, ErrUnknownTenant
+	}
+	return t, nil
+}
+
+// subdomain mengembalikan label pertama host jika host adalah <slug>.<baseDomain>.
+func (res *Resolver) subdomain(host string) string {
+	if res.baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	slug, ok := strings.CutSuffix(strings.ToLower(host), "."+res.baseDomain)
+	if !ok || slug == "" || strings.Contains(slug, ".") {
+		return ""
+	}
+	return slug
+}
@@ -0,0 +1,55 @@
+package tenant
+
+import (
+	"errors"
+	"kasir-api/problem"
+	"net/http"
+	"sync"
+)
+
+// Router meneruskan request ke handler milik tenant-nya. Handler tiap tenant dibangun
+// sekali saat pertama dipakai lalu disimpan, sehingga semua repository di dalamnya
+// terikat ke tenant tersebut.
+type Router struct {
+	resolver *Resolver
+	build    func(tenantID int) http.Handler
+
+	mu     sync.Mutex
+	stacks map[int]http.Handler
+}
+
+func NewRouter(resolver *Resolver, build func(tenantID int) http.Handler) *Router {
+	return &Router{resolver: resolver, build: build, stacks: map[int]http.Handler{}}
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	t, err := rt.resolver.Resolve(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrTenantRequired):
+			problem.Write(w, r, http.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrInactiveTenant):
+			problem.Write(w, r, http.StatusForbidden, err.Error())
+		default:
+			problem.Write(w, r, http.StatusNotFound, err.Error())
+		}
+		return
+	}
+	rt.handler(t.ID).ServeHTTP(w, r)
+}
+
+func (rt *Router) handler(tenantID int) http.Handler {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	h, ok := rt.stacks[tenantID]
+	if !ok {
+		h = rt.build(tenantID)
+		rt.stacks[tenantID] = h
+	}
+	return h
+}
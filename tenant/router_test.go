@@ -0,0 +1,140 @@
+package tenant_test
+
+import (
+	"context"
+	"fmt"
+	"kasir-api/auth"
+	"kasir-api/models"
+	"kasir-api/tenant"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const baseDomain = "kasir.example"
+
+// fakeLookup menyimpan tenant beserta hash API key dan kunci terminal miliknya.
+type fakeLookup struct {
+	tenants      map[int]*models.Tenant
+	apiKeys      map[string]int
+	terminalKeys map[string]int
+}
+
+func (f *fakeLookup) GetByID(ctx context.Context, id int) (*models.Tenant, error) {
+	if t, ok := f.tenants[id]; ok {
+		return t, nil
+	}
+	return nil, models.NotFound("tenant")
+}
+
+func (f *fakeLookup) GetBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	for _, t := range f.tenants {
+		if t.Slug == slug {
+			return t, nil
+		}
+	}
+	return nil, models.NotFound("tenant")
+}
+
+func (f *fakeLookup) GetByAPIKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error) {
+	return f.GetByID(ctx, f.apiKeys[keyHash])
+}
+
+func (f *fakeLookup) GetByTerminalKeyHash(ctx context.Context, keyHash string) (*models.Tenant, error) {
+	return f.GetByID(ctx, f.terminalKeys[keyHash])
+}
+
+// newTestRouter memasang dua toko aktif (1 "abc", 2 "xyz") dan satu toko nonaktif (3 "off").
+// Handler tiap tenant menulis ID tenant-nya, jadi respons menunjukkan stack mana yang dipakai.
+func newTestRouter(t *testing.T) (*tenant.Router, *auth.Issuer, map[int]int) {
+	t.Helper()
+	lookup := &fakeLookup{
+		tenants: map[int]*models.Tenant{
+			1: {ID: 1, Slug: "abc", Active: true},
+			2: {ID: 2, Slug: "xyz", Active: true},
+			3: {ID: 3, Slug: "off", Active: false},
+		},
+		apiKeys:      map[string]int{auth.HashToken("key-abc"): 1, auth.HashToken("key-xyz"): 2},
+		terminalKeys: map[string]int{auth.HashToken("term-abc"): 1, auth.HashToken("term-xyz"): 2},
+	}
+	issuer := auth.NewIssuer("test-secret", time.Minute)
+	builds := map[int]int{}
+	rt := tenant.NewRouter(tenant.NewResolver(lookup, issuer, baseDomain), func(tenantID int) http.Handler {
+		builds[tenantID]++
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, tenantID)
+		})
+	})
+	return rt, issuer, builds
+}
+
+func token(t *testing.T, issuer *auth.Issuer, tenantID int) string {
+	t.Helper()
+	tok, err := issuer.Issue(tenantID, 1, "kasir", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + tok
+}
+
+func TestRouterResolvesTenant(t *testing.T) {
+	rt, issuer, builds := newTestRouter(t)
+	otherIssuer := auth.NewIssuer("other-secret", time.Minute)
+
+	tests := []struct {
+		name   string
+		host   string
+		header map[string]string
+		status int
+		tenant int
+	}{
+		{"token tid", "api.example", map[string]string{"Authorization": token(t, issuer, 2)}, http.StatusOK, 2},
+		{"api key", "api.example", map[string]string{"X-API-Key": "key-xyz"}, http.StatusOK, 2},
+		{"terminal key", "api.example", map[string]string{"X-Terminal-Key": "term-abc"}, http.StatusOK, 1},
+		{"X-Tenant header", "api.example", map[string]string{tenant.Header: " XYZ "}, http.StatusOK, 2},
+		{"subdomain", "abc.kasir.example", nil, http.StatusOK, 1},
+		{"subdomain with port", "XYZ.kasir.example:8080", nil, http.StatusOK, 2},
+
+		// kredensial milik satu toko tidak bisa dipakai di toko lain lewat subdomain atau header
+		{"token beats subdomain", "abc.kasir.example", map[string]string{"Authorization": token(t, issuer, 2)}, http.StatusOK, 2},
+		{"token beats api key", "api.example", map[string]string{"Authorization": token(t, issuer, 1), "X-API-Key": "key-xyz"}, http.StatusOK, 1},
+		{"api key beats X-Tenant", "api.example", map[string]string{"X-API-Key": "key-abc", tenant.Header: "xyz"}, http.StatusOK, 1},
+		{"terminal key beats subdomain", "xyz.kasir.example", map[string]string{"X-Terminal-Key": "term-abc"}, http.StatusOK, 1},
+		{"unknown api key is not a fallback to subdomain", "abc.kasir.example", map[string]string{"X-API-Key": "stolen"}, http.StatusNotFound, 0},
+		{"unknown terminal key", "api.example", map[string]string{"X-Terminal-Key": "stolen", tenant.Header: "abc"}, http.StatusNotFound, 0},
+
+		// token dengan tanda tangan lain diabaikan di sini; auth milik tenant yang menolaknya
+		{"foreign token falls through", "xyz.kasir.example", map[string]string{"Authorization": token(t, otherIssuer, 1)}, http.StatusOK, 2},
+		{"token for unknown tenant", "abc.kasir.example", map[string]string{"Authorization": token(t, issuer, 99)}, http.StatusNotFound, 0},
+		{"unknown slug", "api.example", map[string]string{tenant.Header: "nope"}, http.StatusNotFound, 0},
+		{"inactive tenant", "off.kasir.example", nil, http.StatusForbidden, 0},
+		{"nested subdomain", "a.abc.kasir.example", nil, http.StatusBadRequest, 0},
+		{"other domain", "abc.kasir.example.evil", nil, http.StatusBadRequest, 0},
+		{"no tenant", "kasir.example", nil, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/products", nil)
+			req.Host = tt.host
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			rt.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status == http.StatusOK && rec.Body.String() != strconv.Itoa(tt.tenant) {
+				t.Errorf("served by tenant %s, want %d", rec.Body.String(), tt.tenant)
+			}
+		})
+	}
+
+	// stack tiap tenant dibangun sekali dan tidak pernah untuk tenant yang ditolak
+	if len(builds) != 2 || builds[1] != 1 || builds[2] != 1 {
+		t.Errorf("builds = %v, want tenants 1 and 2 built once each", builds)
+	}
+}
@@ -0,0 +1,56 @@
+// Package validate mengumpulkan aturan validasi input per field. Aturan dicek semua
+// tanpa berhenti di kegagalan pertama, lalu dikembalikan sebagai models.ValidationErrors
+// (422 dengan daftar field di respons).
+package validate
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"strings"
+)
+
+// Validator dipakai sebagai nilai kosong: var v validate.Validator.
+type Validator struct {
+	errs models.ValidationErrors
+}
+
+// Add mencatat field yang gagal dengan pesan, mis. Add("price", "must not be negative").
+func (v *Validator) Add(field, format string, args ...any) {
+	v.errs = append(v.errs, models.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Check mencatat field jika ok bernilai false.
+func (v *Validator) Check(ok bool, field, format string, args ...any) {
+	if !ok {
+		v.Add(field, format, args...)
+	}
+}
+
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+func (v *Validator) NonNegative(field string, value float64) {
+	v.Check(value >= 0, field, "must not be negative")
+}
+
+func (v *Validator) Positive(field string, value float64) {
+	v.Check(value > 0, field, "must be greater than 0")
+}
+
+func (v *Validator) Between(field string, value, min, max float64) {
+	v.Check(value >= min && value <= max, field, "must be between %g and %g", min, max)
+}
+
+// Valid bernilai true jika belum ada field yang gagal.
+func (v *Validator) Valid() bool {
+	return len(v.errs) == 0
+}
+
+// Err mengembalikan nil jika semua aturan lolos.
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return v.errs
+}